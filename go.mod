@@ -5,8 +5,11 @@ go 1.23.6
 require (
 	github.com/alecthomas/assert/v2 v2.3.0
 	github.com/alecthomas/kong v0.8.0
+	github.com/alicebob/miniredis/v2 v2.37.0
+	github.com/fsnotify/fsnotify v1.8.0
 	github.com/gin-gonic/gin v1.10.0
 	github.com/go-dataspace/run-dsrpc v0.0.3-alpha1
+	github.com/go-jose/go-jose/v4 v4.0.4
 	github.com/google/uuid v1.6.0
 	github.com/oapi-codegen/runtime v1.0.0
 	github.com/penglongli/gin-metrics v0.1.10
@@ -19,6 +22,7 @@ require (
 	go.opentelemetry.io/otel/sdk v1.23.1
 	go.opentelemetry.io/otel/trace v1.23.1
 	google.golang.org/grpc v1.64.1
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -36,6 +40,7 @@ require (
 	github.com/rs/xid v1.5.0 // indirect
 	github.com/sirupsen/logrus v1.9.3 // indirect
 	github.com/stretchr/objx v0.5.2 // indirect
+	github.com/yuin/gopher-lua v1.1.1 // indirect
 	gopkg.in/ini.v1 v1.67.0 // indirect
 )
 
@@ -89,5 +94,4 @@ require (
 	google.golang.org/genproto/googleapis/api v0.0.0-20240318140521-94a12d6c2237 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20240318140521-94a12d6c2237 // indirect
 	google.golang.org/protobuf v1.34.2 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -19,6 +19,7 @@ package types
 
 import (
 	context "context"
+	io "io"
 
 	types "github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/types"
 	mock "github.com/stretchr/testify/mock"
@@ -95,34 +96,41 @@ func (_c *MockDataspaceConnector_GetDownloadCredentials_Call) RunAndReturn(run f
 	return _c
 }
 
-// GetProviderFile provides a mock function with given fields: ctx, providerID, fileID
-func (_m *MockDataspaceConnector) GetProviderFile(ctx context.Context, providerID string, fileID string) ([]byte, error) {
-	ret := _m.Called(ctx, providerID, fileID)
+// GetProviderFile provides a mock function with given fields: ctx, providerID, fileID, fileRange
+func (_m *MockDataspaceConnector) GetProviderFile(ctx context.Context, providerID string, fileID string, fileRange types.FileRange) (io.ReadCloser, types.FileMetadata, error) {
+	ret := _m.Called(ctx, providerID, fileID, fileRange)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GetProviderFile")
 	}
 
-	var r0 []byte
-	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) ([]byte, error)); ok {
-		return rf(ctx, providerID, fileID)
+	var r0 io.ReadCloser
+	var r1 types.FileMetadata
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, types.FileRange) (io.ReadCloser, types.FileMetadata, error)); ok {
+		return rf(ctx, providerID, fileID, fileRange)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, string, string) []byte); ok {
-		r0 = rf(ctx, providerID, fileID)
+	if rf, ok := ret.Get(0).(func(context.Context, string, string, types.FileRange) io.ReadCloser); ok {
+		r0 = rf(ctx, providerID, fileID, fileRange)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]byte)
+			r0 = ret.Get(0).(io.ReadCloser)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, string, string) error); ok {
-		r1 = rf(ctx, providerID, fileID)
+	if rf, ok := ret.Get(1).(func(context.Context, string, string, types.FileRange) types.FileMetadata); ok {
+		r1 = rf(ctx, providerID, fileID, fileRange)
 	} else {
-		r1 = ret.Error(1)
+		r1 = ret.Get(1).(types.FileMetadata)
 	}
 
-	return r0, r1
+	if rf, ok := ret.Get(2).(func(context.Context, string, string, types.FileRange) error); ok {
+		r2 = rf(ctx, providerID, fileID, fileRange)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
 }
 
 // MockDataspaceConnector_GetProviderFile_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetProviderFile'
@@ -134,23 +142,24 @@ type MockDataspaceConnector_GetProviderFile_Call struct {
 //   - ctx context.Context
 //   - providerID string
 //   - fileID string
-func (_e *MockDataspaceConnector_Expecter) GetProviderFile(ctx interface{}, providerID interface{}, fileID interface{}) *MockDataspaceConnector_GetProviderFile_Call {
-	return &MockDataspaceConnector_GetProviderFile_Call{Call: _e.mock.On("GetProviderFile", ctx, providerID, fileID)}
+//   - fileRange types.FileRange
+func (_e *MockDataspaceConnector_Expecter) GetProviderFile(ctx interface{}, providerID interface{}, fileID interface{}, fileRange interface{}) *MockDataspaceConnector_GetProviderFile_Call {
+	return &MockDataspaceConnector_GetProviderFile_Call{Call: _e.mock.On("GetProviderFile", ctx, providerID, fileID, fileRange)}
 }
 
-func (_c *MockDataspaceConnector_GetProviderFile_Call) Run(run func(ctx context.Context, providerID string, fileID string)) *MockDataspaceConnector_GetProviderFile_Call {
+func (_c *MockDataspaceConnector_GetProviderFile_Call) Run(run func(ctx context.Context, providerID string, fileID string, fileRange types.FileRange)) *MockDataspaceConnector_GetProviderFile_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(context.Context), args[1].(string), args[2].(string))
+		run(args[0].(context.Context), args[1].(string), args[2].(string), args[3].(types.FileRange))
 	})
 	return _c
 }
 
-func (_c *MockDataspaceConnector_GetProviderFile_Call) Return(_a0 []byte, _a1 error) *MockDataspaceConnector_GetProviderFile_Call {
-	_c.Call.Return(_a0, _a1)
+func (_c *MockDataspaceConnector_GetProviderFile_Call) Return(_a0 io.ReadCloser, _a1 types.FileMetadata, _a2 error) *MockDataspaceConnector_GetProviderFile_Call {
+	_c.Call.Return(_a0, _a1, _a2)
 	return _c
 }
 
-func (_c *MockDataspaceConnector_GetProviderFile_Call) RunAndReturn(run func(context.Context, string, string) ([]byte, error)) *MockDataspaceConnector_GetProviderFile_Call {
+func (_c *MockDataspaceConnector_GetProviderFile_Call) RunAndReturn(run func(context.Context, string, string, types.FileRange) (io.ReadCloser, types.FileMetadata, error)) *MockDataspaceConnector_GetProviderFile_Call {
 	_c.Call.Return(run)
 	return _c
 }
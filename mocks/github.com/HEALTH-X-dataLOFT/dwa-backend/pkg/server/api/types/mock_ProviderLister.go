@@ -22,6 +22,8 @@ import (
 
 	types "github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/types"
 	mock "github.com/stretchr/testify/mock"
+
+	jose "github.com/go-jose/go-jose/v4"
 )
 
 // MockProviderLister is an autogenerated mock type for the ProviderLister type
@@ -94,6 +96,65 @@ func (_c *MockProviderLister_GetProvider_Call) RunAndReturn(run func(context.Con
 	return _c
 }
 
+// GetProviderKeys provides a mock function with given fields: ctx, providerID
+func (_m *MockProviderLister) GetProviderKeys(ctx context.Context, providerID string) ([]jose.JSONWebKey, error) {
+	ret := _m.Called(ctx, providerID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetProviderKeys")
+	}
+
+	var r0 []jose.JSONWebKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) ([]jose.JSONWebKey, error)); ok {
+		return rf(ctx, providerID)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) []jose.JSONWebKey); ok {
+		r0 = rf(ctx, providerID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]jose.JSONWebKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, providerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockProviderLister_GetProviderKeys_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetProviderKeys'
+type MockProviderLister_GetProviderKeys_Call struct {
+	*mock.Call
+}
+
+// GetProviderKeys is a helper method to define mock.On call
+//   - ctx context.Context
+//   - providerID string
+func (_e *MockProviderLister_Expecter) GetProviderKeys(ctx interface{}, providerID interface{}) *MockProviderLister_GetProviderKeys_Call {
+	return &MockProviderLister_GetProviderKeys_Call{Call: _e.mock.On("GetProviderKeys", ctx, providerID)}
+}
+
+func (_c *MockProviderLister_GetProviderKeys_Call) Run(run func(ctx context.Context, providerID string)) *MockProviderLister_GetProviderKeys_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *MockProviderLister_GetProviderKeys_Call) Return(_a0 []jose.JSONWebKey, _a1 error) *MockProviderLister_GetProviderKeys_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockProviderLister_GetProviderKeys_Call) RunAndReturn(run func(context.Context, string) ([]jose.JSONWebKey, error)) *MockProviderLister_GetProviderKeys_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetProviderURL provides a mock function with given fields: ctx, providerID
 func (_m *MockProviderLister) GetProviderURL(ctx context.Context, providerID string) (string, error) {
 	ret := _m.Called(ctx, providerID)
@@ -22,6 +22,8 @@ import (
 
 	types "github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/types"
 	mock "github.com/stretchr/testify/mock"
+
+	uuid "github.com/google/uuid"
 )
 
 // MockShareManager is an autogenerated mock type for the ShareManager type
@@ -37,6 +39,64 @@ func (_m *MockShareManager) EXPECT() *MockShareManager_Expecter {
 	return &MockShareManager_Expecter{mock: &_m.Mock}
 }
 
+// GetSharedFile provides a mock function with given fields: ctx, shareID, bearerToken
+func (_m *MockShareManager) GetSharedFile(ctx context.Context, shareID uuid.UUID, bearerToken string) (types.SharedFile, error) {
+	ret := _m.Called(ctx, shareID, bearerToken)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSharedFile")
+	}
+
+	var r0 types.SharedFile
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string) (types.SharedFile, error)); ok {
+		return rf(ctx, shareID, bearerToken)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uuid.UUID, string) types.SharedFile); ok {
+		r0 = rf(ctx, shareID, bearerToken)
+	} else {
+		r0 = ret.Get(0).(types.SharedFile)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uuid.UUID, string) error); ok {
+		r1 = rf(ctx, shareID, bearerToken)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// MockShareManager_GetSharedFile_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetSharedFile'
+type MockShareManager_GetSharedFile_Call struct {
+	*mock.Call
+}
+
+// GetSharedFile is a helper method to define mock.On call
+//   - ctx context.Context
+//   - shareID uuid.UUID
+//   - bearerToken string
+func (_e *MockShareManager_Expecter) GetSharedFile(ctx interface{}, shareID interface{}, bearerToken interface{}) *MockShareManager_GetSharedFile_Call {
+	return &MockShareManager_GetSharedFile_Call{Call: _e.mock.On("GetSharedFile", ctx, shareID, bearerToken)}
+}
+
+func (_c *MockShareManager_GetSharedFile_Call) Run(run func(ctx context.Context, shareID uuid.UUID, bearerToken string)) *MockShareManager_GetSharedFile_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(uuid.UUID), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *MockShareManager_GetSharedFile_Call) Return(_a0 types.SharedFile, _a1 error) *MockShareManager_GetSharedFile_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *MockShareManager_GetSharedFile_Call) RunAndReturn(run func(context.Context, uuid.UUID, string) (types.SharedFile, error)) *MockShareManager_GetSharedFile_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // SubmitShare provides a mock function with given fields: ctx, share
 func (_m *MockShareManager) SubmitShare(ctx context.Context, share types.ShareRequest) (types.ShareResponse, error) {
 	ret := _m.Called(ctx, share)
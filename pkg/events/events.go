@@ -0,0 +1,61 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package events contains the bus that changes in the backend are published on as events, so they
+// can be pushed to clients.
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Type is the type of an event.
+type Type string
+
+const (
+	TypeProviderAdded    Type = "provider.added"
+	TypeProviderRemoved  Type = "provider.removed"
+	TypeStudyAdded       Type = "study.added"
+	TypeStudyUpdated     Type = "study.updated"
+	TypeTransferProgress Type = "transfer.progress"
+)
+
+// Event is a change in the backend.
+type Event struct {
+	Type Type `json:"type"`
+	// Subject limits the event to the user with that subject, events without subject are for
+	// everyone.
+	Subject string          `json:"subject,omitempty"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// New creates an event with the data marshalled as JSON.
+func New(typ Type, subject string, data any) (Event, error) {
+	d, err := json.Marshal(data)
+	if err != nil {
+		return Event{}, fmt.Errorf("couldn't marshal %s event: %w", typ, err)
+	}
+	return Event{Type: typ, Subject: subject, Data: d}, nil
+}
+
+// Bus delivers published events to all subscribers.
+type Bus interface {
+	Publish(ctx context.Context, event Event) error
+	// Subscribe returns the events for everyone, and the events for the given subject. The channel
+	// is closed once the context is done.
+	Subscribe(ctx context.Context, subject string) <-chan Event
+}
@@ -0,0 +1,81 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"sync"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/logging"
+)
+
+// subscriberBuffer is the amount of events buffered for a subscriber, events for subscribers
+// that fall behind further are dropped.
+const subscriberBuffer = 64
+
+type subscriber struct {
+	subject string
+	ch      chan Event
+}
+
+// LocalBus delivers events to the subscribers in this process.
+type LocalBus struct {
+	sync.Mutex
+	subscribers map[*subscriber]struct{}
+}
+
+func NewLocal() *LocalBus {
+	return &LocalBus{
+		subscribers: make(map[*subscriber]struct{}),
+	}
+}
+
+// Publish delivers the event to all interested subscribers, without waiting for slow ones.
+func (b *LocalBus) Publish(ctx context.Context, event Event) error {
+	logger := logging.Extract(ctx)
+	b.Lock()
+	defer b.Unlock()
+	for s := range b.subscribers {
+		if event.Subject != "" && event.Subject != s.subject {
+			continue
+		}
+		select {
+		case s.ch <- event:
+		default:
+			logger.Warn("Subscriber fell behind, dropping event", "type", event.Type)
+		}
+	}
+	return nil
+}
+
+// Subscribe registers a subscriber until the context is done.
+func (b *LocalBus) Subscribe(ctx context.Context, subject string) <-chan Event {
+	s := &subscriber{
+		subject: subject,
+		ch:      make(chan Event, subscriberBuffer),
+	}
+	b.Lock()
+	b.subscribers[s] = struct{}{}
+	b.Unlock()
+	go func() {
+		<-ctx.Done()
+		b.Lock()
+		delete(b.subscribers, s)
+		close(s.ch)
+		b.Unlock()
+	}()
+	return s.ch
+}
@@ -0,0 +1,47 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/events"
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestLocalBus(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	bus := events.NewLocal()
+	alice := bus.Subscribe(ctx, "alice")
+	bob := bus.Subscribe(ctx, "bob")
+
+	global, err := events.New(events.TypeProviderAdded, "", map[string]string{"id": "p1"})
+	assert.NoError(t, err)
+	personal, err := events.New(events.TypeTransferProgress, "alice", map[string]string{"state": "ready"})
+	assert.NoError(t, err)
+	assert.NoError(t, bus.Publish(ctx, global))
+	assert.NoError(t, bus.Publish(ctx, personal))
+
+	assert.Equal(t, global, <-alice)
+	assert.Equal(t, personal, <-alice)
+	assert.Equal(t, global, <-bob)
+	assert.Equal(t, 0, len(bob))
+
+	cancel()
+	_, open := <-alice
+	assert.False(t, open)
+}
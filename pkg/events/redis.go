@@ -0,0 +1,93 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/logging"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/waitgroup"
+	"github.com/redis/go-redis/v9"
+)
+
+const channel = "events"
+
+// RedisBus publishes events on a redis channel, so that they reach the subscribers of all
+// replicas. Received events are delivered to the subscribers of this replica by a local bus.
+type RedisBus struct {
+	local *LocalBus
+	r     *redis.Client
+}
+
+// NewRedis creates a redis bus, that receives events until the context is done.
+func NewRedis(ctx context.Context, redisClient *redis.Client) *RedisBus {
+	b := &RedisBus{
+		local: NewLocal(),
+		r:     redisClient,
+	}
+	wg := waitgroup.Extract(ctx)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		b.receive(ctx)
+	}()
+	return b
+}
+
+// Publish publishes the event to all replicas.
+func (b *RedisBus) Publish(ctx context.Context, event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("couldn't marshal %s event: %w", event.Type, err)
+	}
+	if err := b.r.Publish(ctx, channel, data).Err(); err != nil {
+		return fmt.Errorf("couldn't publish %s event: %w", event.Type, err)
+	}
+	return nil
+}
+
+// Subscribe registers a subscriber on this replica until the context is done.
+func (b *RedisBus) Subscribe(ctx context.Context, subject string) <-chan Event {
+	return b.local.Subscribe(ctx, subject)
+}
+
+func (b *RedisBus) receive(ctx context.Context) {
+	logger := logging.Extract(ctx).With("channel", channel)
+	pubsub := b.r.Subscribe(ctx, channel)
+	defer pubsub.Close()
+	// The channel of the subscription reconnects by itself when the connection is lost.
+	messages := pubsub.Channel()
+	logger.Info("Receiving events")
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("Context done, stopping to receive events")
+			return
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			var event Event
+			if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+				logger.Error("Couldn't unmarshal event", "error", err)
+				continue
+			}
+			_ = b.local.Publish(ctx, event)
+		}
+	}
+}
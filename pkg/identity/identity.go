@@ -0,0 +1,47 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package identity
+
+import (
+	"context"
+	"time"
+)
+
+type contextKeyType string
+
+const contextKey contextKeyType = "identity"
+
+// Identity is the verified identity of the user a request is made on behalf of.
+type Identity struct {
+	Subject           string
+	Issuer            string
+	Audience          []string
+	ExpiresAt         time.Time
+	PreferredUsername string
+	Name              string
+	Email             string
+}
+
+// Inject puts the identity into the context.
+func Inject(ctx context.Context, id Identity) context.Context {
+	return context.WithValue(ctx, contextKey, id)
+}
+
+// Extract returns the identity from the context, if one was verified.
+func Extract(ctx context.Context) (Identity, bool) {
+	id, ok := ctx.Value(contextKey).(Identity)
+	return id, ok
+}
@@ -0,0 +1,38 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package identity provides helpers to find out which user a request is made on behalf of.
+package identity
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/types"
+)
+
+// Subject returns the subject of the user the request is made on behalf of. Only the subject of
+// an identity the token verification put into the context is trusted, as it keys the data of
+// the user. Without a verified identity the request is rejected.
+func Subject(ctx context.Context) (string, error) {
+	id, ok := Extract(ctx)
+	if !ok {
+		return "", fmt.Errorf("%w: no verified identity", types.ErrInvalidCredentials)
+	}
+	if id.Subject == "" {
+		return "", fmt.Errorf("%w: identity has no subject", types.ErrInvalidCredentials)
+	}
+	return id.Subject, nil
+}
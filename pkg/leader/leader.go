@@ -0,0 +1,174 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package leader elects one replica of the backend as the leader with a lock in redis, so the
+// background monitors that poll the catalogues only run on one replica.
+package leader
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/logging"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/waitgroup"
+	"github.com/penglongli/gin-metrics/ginmetrics"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	lockKey     = "leader:lock"
+	metricName  = "cma_backend_leader"
+	releaseWait = 5 * time.Second
+)
+
+// renewScript extends the lock if this replica still holds it.
+var renewScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// releaseScript deletes the lock if this replica still holds it.
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// Elector campaigns for the leader lock until its context is done. The lock expires after its
+// TTL and is renewed every third of it, so when the leader is gone another replica takes over
+// within the TTL. A nil elector is always the leader, for a single replica without election.
+type Elector struct {
+	r      *redis.Client
+	id     string
+	ttl    time.Duration
+	leader atomic.Bool
+}
+
+// New creates an elector that campaigns as id. The first campaign is done before it returns, so
+// IsLeader is up to date when the monitors start.
+func New(ctx context.Context, redisClient *redis.Client, id string, ttl time.Duration) *Elector {
+	e := &Elector{
+		r:   redisClient,
+		id:  id,
+		ttl: ttl,
+	}
+	m := ginmetrics.GetMonitor()
+	if m.GetMetric(metricName).Name == "" {
+		_ = m.AddMetric(&ginmetrics.Metric{
+			Type:        ginmetrics.Gauge,
+			Name:        metricName,
+			Description: "Whether the replica is the leader that runs the background monitors.",
+			Labels:      []string{"pod"},
+		})
+	}
+	e.campaign(ctx)
+	e.setMetric(e.leader.Load())
+
+	wg := waitgroup.Extract(ctx)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		e.run(ctx)
+	}()
+	return e
+}
+
+// IsLeader returns whether this replica is the leader.
+func (e *Elector) IsLeader() bool {
+	if e == nil {
+		return true
+	}
+	return e.leader.Load()
+}
+
+func (e *Elector) run(ctx context.Context) {
+	logger := logging.Extract(ctx).With("leader_id", e.id)
+	t := time.NewTicker(e.ttl / 3)
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("Context done, stopping leader election")
+			t.Stop()
+			e.release(ctx)
+			return
+		case <-t.C:
+			e.campaign(ctx)
+		}
+	}
+}
+
+// campaign renews the lock if this replica holds it, or acquires it if nobody does. When the
+// lock can't be renewed this replica steps down, as another one may take over once it expires.
+func (e *Elector) campaign(ctx context.Context) {
+	logger := logging.Extract(ctx).With("leader_id", e.id)
+	leader, err := e.acquire(ctx)
+	if err != nil {
+		logger.Error("Couldn't campaign for leader", "error", err)
+		leader = false
+	}
+	if e.leader.Swap(leader) == leader {
+		return
+	}
+	if leader {
+		logger.Info("Became the leader")
+	} else {
+		logger.Warn("No longer the leader")
+	}
+	e.setMetric(leader)
+}
+
+func (e *Elector) acquire(ctx context.Context) (bool, error) {
+	renewed, err := renewScript.Run(ctx, e.r, []string{lockKey}, e.id, e.ttl.Milliseconds()).Bool()
+	if err != nil {
+		return false, fmt.Errorf("couldn't renew leader lock: %w", err)
+	}
+	if renewed {
+		return true, nil
+	}
+	acquired, err := e.r.SetNX(ctx, lockKey, e.id, e.ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("couldn't acquire leader lock: %w", err)
+	}
+	return acquired, nil
+}
+
+// release gives up the lock on shutdown, so another replica doesn't have to wait for it to expire.
+func (e *Elector) release(ctx context.Context) {
+	if !e.leader.Swap(false) {
+		return
+	}
+	e.setMetric(false)
+	logger := logging.Extract(ctx).With("leader_id", e.id)
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), releaseWait)
+	defer cancel()
+	if err := releaseScript.Run(ctx, e.r, []string{lockKey}, e.id).Err(); err != nil {
+		logger.Error("Couldn't release leader lock", "error", err)
+		return
+	}
+	logger.Info("Released the leader lock")
+}
+
+func (e *Elector) setMetric(leader bool) {
+	value := 0.0
+	if leader {
+		value = 1
+	}
+	_ = ginmetrics.GetMonitor().GetMetric(metricName).SetGaugeValue([]string{e.id}, value)
+}
@@ -0,0 +1,150 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package leader_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/leader"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/waitgroup"
+	"github.com/alecthomas/assert/v2"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	lockKey = "leader:lock"
+	ttl     = 300 * time.Millisecond
+)
+
+// replica runs an elector until the returned stop function is called.
+func replica(t *testing.T, mr *miniredis.Miniredis, id string) (*leader.Elector, func()) {
+	t.Helper()
+	r := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	wg := &sync.WaitGroup{}
+	ctx, cancel := context.WithCancel(waitgroup.Inject(context.Background(), wg))
+	e := leader.New(ctx, r, id, ttl)
+	var once sync.Once
+	stop := func() {
+		once.Do(func() {
+			cancel()
+			wg.Wait()
+			r.Close()
+		})
+	}
+	t.Cleanup(stop)
+	return e, stop
+}
+
+func eventually(t *testing.T, cond func() bool, msg string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal(msg)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestAcquire(t *testing.T) {
+	mr := miniredis.RunT(t)
+	a, _ := replica(t, mr, "replica-a")
+	b, _ := replica(t, mr, "replica-b")
+
+	assert.True(t, a.IsLeader())
+	assert.False(t, b.IsLeader())
+	holder, err := mr.Get(lockKey)
+	assert.NoError(t, err)
+	assert.Equal(t, "replica-a", holder)
+	assert.Equal(t, ttl, mr.TTL(lockKey))
+}
+
+func TestRenew(t *testing.T) {
+	mr := miniredis.RunT(t)
+	a, _ := replica(t, mr, "replica-a")
+	b, _ := replica(t, mr, "replica-b")
+
+	mr.FastForward(ttl * 5 / 6)
+	eventually(t, func() bool { return mr.TTL(lockKey) > ttl/2 }, "lock wasn't renewed")
+	mr.FastForward(ttl * 5 / 6)
+	eventually(t, func() bool { return mr.TTL(lockKey) > ttl/2 }, "lock wasn't renewed")
+
+	assert.True(t, a.IsLeader())
+	assert.False(t, b.IsLeader())
+}
+
+func TestLossOnFailedRenew(t *testing.T) {
+	tests := []struct {
+		name      string
+		interfere func(mr *miniredis.Miniredis)
+	}{
+		{
+			name:      "LockTaken",
+			interfere: func(mr *miniredis.Miniredis) { mr.Set(lockKey, "replica-b") },
+		},
+		{
+			name:      "RedisUnavailable",
+			interfere: func(mr *miniredis.Miniredis) { mr.Close() },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mr := miniredis.RunT(t)
+			a, _ := replica(t, mr, "replica-a")
+			assert.True(t, a.IsLeader())
+
+			tt.interfere(mr)
+			eventually(t, func() bool { return !a.IsLeader() }, "replica is still the leader")
+		})
+	}
+}
+
+func TestTakeOverExpiredLock(t *testing.T) {
+	mr := miniredis.RunT(t)
+	a, stop := replica(t, mr, "replica-a")
+	assert.True(t, a.IsLeader())
+
+	// The leader is gone without releasing its lock.
+	mr.Set(lockKey, "replica-gone")
+	mr.SetTTL(lockKey, ttl)
+	stop()
+
+	b, _ := replica(t, mr, "replica-b")
+	assert.False(t, b.IsLeader())
+	mr.FastForward(ttl)
+	eventually(t, b.IsLeader, "replica didn't take over the expired lock")
+}
+
+func TestRelease(t *testing.T) {
+	mr := miniredis.RunT(t)
+	a, stop := replica(t, mr, "replica-a")
+	b, _ := replica(t, mr, "replica-b")
+	assert.True(t, a.IsLeader())
+
+	stop()
+	assert.False(t, a.IsLeader())
+	assert.False(t, mr.Exists(lockKey))
+	eventually(t, b.IsLeader, "replica didn't take over the released lock")
+}
+
+func TestNilElector(t *testing.T) {
+	var e *leader.Elector
+	assert.True(t, e.IsLeader())
+}
@@ -94,6 +94,12 @@ func StreamInterceptor(
 	return handler(srv, &serverStream{ss, ctx})
 }
 
+// InjectAuthorization injects the authorization header value into the context, so that work done
+// on behalf of the user outside of the request can still be authenticated.
+func InjectAuthorization(ctx context.Context, authorization string) context.Context {
+	return context.WithValue(ctx, contextKey, authorization)
+}
+
 // ExtractAuthorization shouldn't be public, but it temporarily is to keep things working.
 func ExtractAuthorization(ctx context.Context) string {
 	ctxVal := ctx.Value(contextKey)
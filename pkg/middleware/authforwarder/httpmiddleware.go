@@ -16,8 +16,8 @@
 package authforwarder
 
 import (
-	"context"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
@@ -32,7 +32,7 @@ const (
 func HTTPMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authContents := c.Request.Header.Get("Authorization")
-		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), contextKey, authContents))
+		c.Request = c.Request.WithContext(InjectAuthorization(c.Request.Context(), authContents))
 		c.Next()
 	}
 }
@@ -49,3 +49,13 @@ func (art AuthRoundTripper) RoundTrip(req *http.Request) (*http.Response, error)
 	req.Header.Add("Authorization", authVal)
 	return art.Proxied.RoundTrip(req)
 }
+
+// BearerToken strips the scheme from an authorization header value, it returns an empty string
+// if the header doesn't contain a bearer token.
+func BearerToken(header string) string {
+	scheme, token, found := strings.Cut(strings.TrimSpace(header), " ")
+	if !found || !strings.EqualFold(scheme, "bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
@@ -0,0 +1,203 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authforwarder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/logging"
+	"github.com/go-jose/go-jose/v4"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// defaultKeyAlgorithm is used for provider keys that don't state their algorithm.
+const defaultKeyAlgorithm = jose.RSA_OAEP_256
+
+// ProviderKeysFunc returns the public keys of the provider with the given ID.
+type ProviderKeysFunc func(ctx context.Context, providerID string) ([]jose.JSONWebKey, error)
+
+type providerContextKeyType string
+
+const providerContextKey providerContextKeyType = "provider"
+
+// keyAlgorithms are the algorithms a provider key can be used with to wrap a token.
+var keyAlgorithms = []jose.KeyAlgorithm{
+	jose.RSA_OAEP, jose.RSA_OAEP_256,
+	jose.ECDH_ES, jose.ECDH_ES_A128KW, jose.ECDH_ES_A192KW, jose.ECDH_ES_A256KW,
+}
+
+// InjectProvider puts the ID of the provider the calls to run-dsp are made for into the context,
+// so the token can be wrapped for it.
+func InjectProvider(ctx context.Context, providerID string) context.Context {
+	return context.WithValue(ctx, providerContextKey, providerID)
+}
+
+// ExtractProvider returns the ID of the provider the calls to run-dsp are made for, if any.
+func ExtractProvider(ctx context.Context) string {
+	providerID, _ := ctx.Value(providerContextKey).(string)
+	return providerID
+}
+
+// providerRequest is implemented by the run-dsp requests that address a provider.
+type providerRequest interface {
+	GetProviderUrl() string
+}
+
+// catalogueRequest is implemented by the run-dsp requests that address the catalogue of a
+// provider.
+type catalogueRequest interface {
+	GetProviderUri() string
+}
+
+// JWEUnaryClientInterceptor does the same as UnaryClientInterceptor, but wraps a plain bearer
+// token as JWE for the provider addressed by the request, so only that provider can read it. The
+// ID of the provider has to be injected with InjectProvider. Tokens that already are a JWE, and
+// requests that don't address a provider, are passed on as they are.
+func JWEUnaryClientInterceptor(providerKeys ProviderKeysFunc) grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string, req interface{}, reply interface{},
+		cc *grpc.ClientConn, invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		val := ExtractAuthorization(ctx)
+		if addressedProvider(req) != "" {
+			var err error
+			if val, err = wrapAuthorization(ctx, providerKeys, method, val); err != nil {
+				return err
+			}
+		}
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", val)
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// JWEStreamClientInterceptor does the same as StreamClientInterceptor, but wraps a plain bearer
+// token as JWE for the provider injected with InjectProvider. The requests of a stream are only
+// sent after it has been opened, so streams without an injected provider are passed on as they
+// are.
+func JWEStreamClientInterceptor(providerKeys ProviderKeysFunc) grpc.StreamClientInterceptor {
+	return func(
+		ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn,
+		method string, streamer grpc.Streamer, opts ...grpc.CallOption,
+	) (grpc.ClientStream, error) {
+		val := ExtractAuthorization(ctx)
+		if ExtractProvider(ctx) != "" {
+			var err error
+			if val, err = wrapAuthorization(ctx, providerKeys, method, val); err != nil {
+				return nil, err
+			}
+		}
+		ctx = metadata.AppendToOutgoingContext(ctx, "authorization", val)
+		return streamer(ctx, desc, cc, method, opts...)
+	}
+}
+
+// wrapAuthorization wraps a plain bearer token in the authorization for the provider in the
+// context.
+func wrapAuthorization(ctx context.Context, providerKeys ProviderKeysFunc, method string, val string) (string, error) {
+	token := BearerToken(val)
+	if token == "" || strings.Count(token, ".") != 2 {
+		return val, nil
+	}
+	providerID := ExtractProvider(ctx)
+	if providerID == "" {
+		return "", fmt.Errorf("couldn't wrap token for %s: no provider in context", method)
+	}
+	logging.Extract(ctx).Debug("Wrapping token for provider", "provider_id", providerID, "method", method)
+	keys, err := providerKeys(ctx, providerID)
+	if err != nil {
+		return "", fmt.Errorf("couldn't get keys of provider %s: %w", providerID, err)
+	}
+	key, err := SelectKey(keys)
+	if err != nil {
+		return "", fmt.Errorf("couldn't wrap token for provider %s: %w", providerID, err)
+	}
+	wrapped, err := WrapToken(token, key)
+	if err != nil {
+		return "", fmt.Errorf("couldn't wrap token for provider %s: %w", providerID, err)
+	}
+	return "Bearer " + wrapped, nil
+}
+
+// SelectKey returns the key a token is wrapped with. Keys meant for signatures are skipped, keys
+// marked for encryption are preferred over keys without a use, and keys with a key ID, which the
+// provider needs to find its private key, over keys without one.
+func SelectKey(keys []jose.JSONWebKey) (jose.JSONWebKey, error) {
+	var selected *jose.JSONWebKey
+	rank := func(k jose.JSONWebKey) int {
+		r := 0
+		if k.Use == "enc" {
+			r += 2
+		}
+		if k.KeyID != "" {
+			r++
+		}
+		return r
+	}
+	for i, k := range keys {
+		if k.Use != "" && k.Use != "enc" {
+			continue
+		}
+		if k.Algorithm != "" && !slices.Contains(keyAlgorithms, jose.KeyAlgorithm(k.Algorithm)) {
+			continue
+		}
+		if selected == nil || rank(k) > rank(*selected) {
+			selected = &keys[i]
+		}
+	}
+	if selected == nil {
+		return jose.JSONWebKey{}, errors.New("no key for encryption")
+	}
+	return *selected, nil
+}
+
+func addressedProvider(req interface{}) string {
+	switch r := req.(type) {
+	case providerRequest:
+		return r.GetProviderUrl()
+	case catalogueRequest:
+		return r.GetProviderUri()
+	default:
+		return ""
+	}
+}
+
+// WrapToken encrypts the token as nested JWT for the given key.
+func WrapToken(token string, key jose.JSONWebKey) (string, error) {
+	alg := jose.KeyAlgorithm(key.Algorithm)
+	if alg == "" {
+		alg = defaultKeyAlgorithm
+	}
+	encrypter, err := jose.NewEncrypter(
+		jose.A256GCM,
+		jose.Recipient{Algorithm: alg, Key: key.Public().Key, KeyID: key.KeyID},
+		(&jose.EncrypterOptions{}).WithContentType("JWT"),
+	)
+	if err != nil {
+		return "", fmt.Errorf("couldn't create encrypter: %w", err)
+	}
+	jwe, err := encrypter.Encrypt([]byte(token))
+	if err != nil {
+		return "", fmt.Errorf("couldn't encrypt token: %w", err)
+	}
+	return jwe.CompactSerialize()
+}
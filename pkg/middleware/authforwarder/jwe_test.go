@@ -0,0 +1,183 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authforwarder_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"strings"
+	"testing"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/middleware/authforwarder"
+	"github.com/alecthomas/assert/v2"
+	"github.com/go-jose/go-jose/v4"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	providerURL = "https://provider.example.org"
+	// {"alg":"none"}.{"sub":"user-1"}.
+	plainToken = "eyJhbGciOiJub25lIn0.eyJzdWIiOiJ1c2VyLTEifQ."
+	jweToken   = "eyJhbGciOiJSU0EtT0FFUCJ9.a.b.c.d"
+)
+
+type providerRequest struct{ url string }
+
+func (r providerRequest) GetProviderUrl() string { return r.url }
+
+func TestJWEUnaryClientInterceptor(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	public := jose.JSONWebKey{
+		Key: &key.PublicKey, KeyID: "provider-key", Algorithm: string(jose.RSA_OAEP_256), Use: "enc",
+	}
+	keys := func(_ context.Context, providerID string) ([]jose.JSONWebKey, error) {
+		assert.Equal(t, providerID, "provider-a")
+		return []jose.JSONWebKey{public}, nil
+	}
+	interceptor := authforwarder.JWEUnaryClientInterceptor(keys)
+
+	tests := []struct {
+		name       string
+		token      string
+		providerID string
+		req        any
+		wrapped    bool
+		wantErr    bool
+	}{
+		{
+			name: "PlainToken", token: plainToken, providerID: "provider-a", req: providerRequest{url: providerURL},
+			wrapped: true,
+		},
+		{name: "AlreadyEncrypted", token: jweToken, providerID: "provider-a", req: providerRequest{url: providerURL}},
+		{name: "NoProvider", token: plainToken, providerID: "provider-a", req: struct{}{}},
+		{name: "NoProviderInContext", token: plainToken, req: providerRequest{url: providerURL}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := authforwarder.InjectAuthorization(context.Background(), "Bearer "+tt.token)
+			if tt.providerID != "" {
+				ctx = authforwarder.InjectProvider(ctx, tt.providerID)
+			}
+			var forwarded string
+			invoker := func(ctx context.Context, _ string, _, _ any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+				md, _ := metadata.FromOutgoingContext(ctx)
+				forwarded = authforwarder.BearerToken(md.Get("authorization")[0])
+				return nil
+			}
+			err := interceptor(ctx, "/method", tt.req, nil, nil, invoker)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assertForwarded(t, key, tt.token, forwarded, tt.wrapped)
+		})
+	}
+}
+
+func TestJWEStreamClientInterceptor(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	keys := func(_ context.Context, providerID string) ([]jose.JSONWebKey, error) {
+		assert.Equal(t, providerID, "provider-a")
+		return []jose.JSONWebKey{{Key: &key.PublicKey, KeyID: "provider-key", Use: "enc"}}, nil
+	}
+	interceptor := authforwarder.JWEStreamClientInterceptor(keys)
+
+	tests := []struct {
+		name       string
+		token      string
+		providerID string
+		wrapped    bool
+	}{
+		{name: "PlainToken", token: plainToken, providerID: "provider-a", wrapped: true},
+		{name: "AlreadyEncrypted", token: jweToken, providerID: "provider-a"},
+		{name: "NoProvider", token: plainToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := authforwarder.InjectAuthorization(context.Background(), "Bearer "+tt.token)
+			if tt.providerID != "" {
+				ctx = authforwarder.InjectProvider(ctx, tt.providerID)
+			}
+			var forwarded string
+			streamer := func(
+				ctx context.Context, _ *grpc.StreamDesc, _ *grpc.ClientConn, _ string, _ ...grpc.CallOption,
+			) (grpc.ClientStream, error) {
+				md, _ := metadata.FromOutgoingContext(ctx)
+				forwarded = authforwarder.BearerToken(md.Get("authorization")[0])
+				return nil, nil
+			}
+			_, err := interceptor(ctx, &grpc.StreamDesc{ServerStreams: true}, nil, "/method", streamer)
+			assert.NoError(t, err)
+			assertForwarded(t, key, tt.token, forwarded, tt.wrapped)
+		})
+	}
+}
+
+func assertForwarded(t *testing.T, key *rsa.PrivateKey, token string, forwarded string, wrapped bool) {
+	t.Helper()
+	if !wrapped {
+		assert.Equal(t, token, forwarded)
+		return
+	}
+	assert.Equal(t, 4, strings.Count(forwarded, "."))
+	jwe, err := jose.ParseEncrypted(
+		forwarded, []jose.KeyAlgorithm{jose.RSA_OAEP_256}, []jose.ContentEncryption{jose.A256GCM},
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, "provider-key", jwe.Header.KeyID)
+	decrypted, err := jwe.Decrypt(key)
+	assert.NoError(t, err)
+	assert.Equal(t, token, string(decrypted))
+}
+
+func TestSelectKey(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	sig := jose.JSONWebKey{Key: &key.PublicKey, KeyID: "sig", Use: "sig"}
+	sigAlg := jose.JSONWebKey{Key: &key.PublicKey, KeyID: "sig-alg", Algorithm: string(jose.RS256)}
+	enc := jose.JSONWebKey{Key: &key.PublicKey, KeyID: "enc", Use: "enc"}
+	anyUse := jose.JSONWebKey{Key: &key.PublicKey, KeyID: "any"}
+	noKeyID := jose.JSONWebKey{Key: &key.PublicKey, Use: "enc"}
+
+	tests := []struct {
+		name    string
+		keys    []jose.JSONWebKey
+		want    string
+		wantErr bool
+	}{
+		{name: "EncryptionKey", keys: []jose.JSONWebKey{sig, anyUse, enc}, want: "enc"},
+		{name: "KeyWithoutUse", keys: []jose.JSONWebKey{sig, sigAlg, anyUse}, want: "any"},
+		{name: "KeyWithKeyID", keys: []jose.JSONWebKey{noKeyID, enc}, want: "enc"},
+		{name: "OnlySignatureKeys", keys: []jose.JSONWebKey{sig, sigAlg}, wantErr: true},
+		{name: "NoKeys", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, err := authforwarder.SelectKey(tt.keys)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, key.KeyID)
+		})
+	}
+}
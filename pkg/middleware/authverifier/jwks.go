@@ -0,0 +1,143 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authverifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/logging"
+	"github.com/go-jose/go-jose/v4"
+)
+
+const (
+	// refreshInterval is the minimum time between fetching a JWKS again because a token was
+	// signed with an unknown key.
+	refreshInterval = time.Minute
+	fetchTimeout    = 10 * time.Second
+)
+
+// keySet is a JWKS loaded from a URL or a local file. A JWKS from a URL is fetched again when a
+// token is signed with a key it doesn't contain, so keys rotated by the issuer are picked up.
+type keySet struct {
+	sync.Mutex
+	source    string
+	client    *http.Client
+	keys      jose.JSONWebKeySet
+	fetchedAt time.Time
+}
+
+func newKeySet(ctx context.Context, source string) (*keySet, error) {
+	ks := &keySet{
+		source: source,
+		client: &http.Client{Timeout: fetchTimeout},
+	}
+	keys, err := ks.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ks.keys = keys
+	ks.fetchedAt = time.Now()
+	return ks, nil
+}
+
+// key returns the verification key with the given ID.
+func (ks *keySet) key(ctx context.Context, keyID string) (jose.JSONWebKey, error) {
+	ks.Lock()
+	defer ks.Unlock()
+	if k, ok := findKey(ks.keys, keyID); ok {
+		return k, nil
+	}
+	if !ks.remote() || time.Since(ks.fetchedAt) < refreshInterval {
+		return jose.JSONWebKey{}, fmt.Errorf("unknown key %q", keyID)
+	}
+
+	logging.Extract(ctx).Info("Unknown key, fetching JWKS again", "kid", keyID, "source", ks.source)
+	ks.fetchedAt = time.Now()
+	keys, err := ks.load(ctx)
+	if err != nil {
+		return jose.JSONWebKey{}, err
+	}
+	ks.keys = keys
+	if k, ok := findKey(ks.keys, keyID); ok {
+		return k, nil
+	}
+	return jose.JSONWebKey{}, fmt.Errorf("unknown key %q", keyID)
+}
+
+func (ks *keySet) remote() bool {
+	return strings.HasPrefix(ks.source, "https://") || strings.HasPrefix(ks.source, "http://")
+}
+
+func (ks *keySet) load(ctx context.Context) (jose.JSONWebKeySet, error) {
+	var (
+		data []byte
+		err  error
+	)
+	if ks.remote() {
+		data, err = ks.fetch(ctx)
+	} else {
+		data, err = os.ReadFile(ks.source)
+	}
+	if err != nil {
+		return jose.JSONWebKeySet{}, fmt.Errorf("couldn't load JWKS from %s: %w", ks.source, err)
+	}
+	var keys jose.JSONWebKeySet
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return jose.JSONWebKeySet{}, fmt.Errorf("couldn't parse JWKS from %s: %w", ks.source, err)
+	}
+	if len(keys.Keys) == 0 {
+		return jose.JSONWebKeySet{}, fmt.Errorf("JWKS from %s contains no keys", ks.source)
+	}
+	return keys, nil
+}
+
+func (ks *keySet) fetch(ctx context.Context) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ks.source, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Accept", "application/json")
+	resp, err := ks.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP error when getting JWKS: %d", resp.StatusCode)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// findKey returns the signing key with the given ID, a key without ID matches any ID.
+func findKey(keys jose.JSONWebKeySet, keyID string) (jose.JSONWebKey, bool) {
+	for _, k := range keys.Keys {
+		if k.Use == "enc" || !k.Valid() {
+			continue
+		}
+		if k.KeyID == keyID || k.KeyID == "" {
+			return k.Public(), true
+		}
+	}
+	return jose.JSONWebKey{}, false
+}
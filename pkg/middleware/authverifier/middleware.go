@@ -0,0 +1,69 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authverifier
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/identity"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/logging"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/middleware/authforwarder"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/types"
+	"github.com/gin-gonic/gin"
+)
+
+// HTTPMiddleware verifies the bearer token of the request, and injects the identity of the user
+// into the context. Requests with an invalid token are rejected, requests without a token are
+// passed on, so endpoints that don't need a user keep working. Requests with an encrypted token
+// are passed on without identity too: the token is forwarded to the provider, which can decrypt
+// and verify it, while endpoints keyed by the user refuse the request. The routes in exempt use
+// bearer tokens that aren't user tokens, like share tokens, so they aren't verified.
+func HTTPMiddleware(v *Verifier, exempt ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.Request.Header.Get("Authorization")
+		if header == "" || slices.Contains(exempt, c.FullPath()) {
+			c.Next()
+			return
+		}
+		logger := logging.Extract(c)
+		token := authforwarder.BearerToken(header)
+		if token == "" {
+			logger.Info("Rejecting request without bearer token")
+			c.AbortWithStatusJSON(http.StatusUnauthorized, types.ErrorResponse{
+				Status: "Invalid credentials",
+				Error:  "The authorization header doesn't contain a bearer token",
+			})
+			return
+		}
+		id, err := v.Verify(c.Request.Context(), token)
+		if errors.Is(err, ErrEncrypted) {
+			c.Next()
+			return
+		}
+		if err != nil {
+			logger.Info("Rejecting request with invalid token", "error", err)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, types.ErrorResponse{
+				Status: "Invalid credentials",
+				Error:  err.Error(),
+			})
+			return
+		}
+		c.Request = c.Request.WithContext(identity.Inject(c.Request.Context(), id))
+		c.Next()
+	}
+}
@@ -0,0 +1,122 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authverifier_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/identity"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/middleware/authverifier"
+	"github.com/alecthomas/assert/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+)
+
+func TestHTTPMiddlewareExempt(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	data, err := json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{Key: &key.PublicKey, KeyID: "k1"}}})
+	assert.NoError(t, err)
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	assert.NoError(t, os.WriteFile(jwksFile, data, 0o600))
+	v, err := authverifier.New(context.Background(), jwksFile, issuer, audience)
+	assert.NoError(t, err)
+
+	router := gin.New()
+	router.Use(authverifier.HTTPMiddleware(v, "/api/shares/:share_id"))
+	router.GET("/api/shares/:share_id", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+	router.GET("/api/policies", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	tests := []struct {
+		name   string
+		path   string
+		status int
+	}{
+		{name: "Exempt", path: "/api/shares/8c5b6f3e-52a4-4a43-9d59-6b0f1f3a6d10", status: http.StatusNoContent},
+		{name: "Verified", path: "/api/policies", status: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set("Authorization", "Bearer opaque-share-token")
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			assert.Equal(t, tt.status, rec.Code)
+		})
+	}
+}
+
+func TestHTTPMiddlewareEncrypted(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	data, err := json.Marshal(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{Key: &key.PublicKey, KeyID: "k1"}}})
+	assert.NoError(t, err)
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	assert.NoError(t, os.WriteFile(jwksFile, data, 0o600))
+	v, err := authverifier.New(context.Background(), jwksFile, issuer, audience)
+	assert.NoError(t, err)
+	token := encrypt(t, key, jwt.Claims{
+		Subject:  "user-a",
+		Issuer:   issuer,
+		Audience: jwt.Audience{audience},
+		Expiry:   jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	})
+
+	router := gin.New()
+	router.Use(authverifier.HTTPMiddleware(v))
+	// Provider routes forward the token as is.
+	router.GET("/api/providers", func(c *gin.Context) {
+		_, verified := identity.Extract(c.Request.Context())
+		assert.False(t, verified)
+		c.Status(http.StatusNoContent)
+	})
+	// Routes keyed by the user need a verified subject.
+	router.GET("/api/policies", func(c *gin.Context) {
+		if _, err := identity.Subject(c.Request.Context()); err != nil {
+			c.Status(http.StatusUnauthorized)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		name   string
+		path   string
+		status int
+	}{
+		{name: "Forwarded", path: "/api/providers", status: http.StatusNoContent},
+		{name: "NoSubject", path: "/api/policies", status: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			assert.Equal(t, tt.status, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package authverifier contains a middleware that verifies the bearer tokens of incoming requests.
+package authverifier
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/identity"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/types"
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+)
+
+// leeway is the clock skew allowed when checking the time based claims.
+const leeway = time.Minute
+
+var (
+	signatureAlgorithms = []jose.SignatureAlgorithm{
+		jose.RS256, jose.RS384, jose.RS512,
+		jose.PS256, jose.PS384, jose.PS512,
+		jose.ES256, jose.ES384, jose.ES512,
+		jose.EdDSA,
+	}
+)
+
+// tokenClaims are the claims of a Keycloak token the identity is made of.
+type tokenClaims struct {
+	jwt.Claims
+	PreferredUsername string `json:"preferred_username,omitempty"`
+	Name              string `json:"name,omitempty"`
+	Email             string `json:"email,omitempty"`
+}
+
+// Verifier verifies bearer tokens against a JWKS, and checks their issuer, audience and expiry.
+type Verifier struct {
+	keys     *keySet
+	issuer   string
+	audience string
+}
+
+// New creates a verifier with the JWKS loaded from the given URL or file. An empty issuer or
+// audience is not checked.
+func New(ctx context.Context, jwksSource, issuer, audience string) (*Verifier, error) {
+	keys, err := newKeySet(ctx, jwksSource)
+	if err != nil {
+		return nil, err
+	}
+	return &Verifier{
+		keys:     keys,
+		issuer:   issuer,
+		audience: audience,
+	}, nil
+}
+
+// ErrEncrypted is returned for encrypted tokens (JWE). Their payload is meant for the provider and
+// can't be verified by the backend, and the claims in their header aren't protected by any
+// signature, so no identity can be taken from them.
+var ErrEncrypted = fmt.Errorf("%w: encrypted bearer tokens can't be verified", types.ErrInvalidCredentials)
+
+// Verify verifies the token and returns the identity of its user. Only signed tokens (JWS) yield
+// an identity, for encrypted tokens ErrEncrypted is returned.
+func (v *Verifier) Verify(ctx context.Context, token string) (identity.Identity, error) {
+	switch strings.Count(token, ".") {
+	case 2:
+		return v.verifySigned(ctx, token)
+	case 4:
+		return identity.Identity{}, ErrEncrypted
+	default:
+		return identity.Identity{}, fmt.Errorf("%w: bearer token is neither a JWS nor a JWE", types.ErrInvalidCredentials)
+	}
+}
+
+func (v *Verifier) verifySigned(ctx context.Context, token string) (identity.Identity, error) {
+	tok, err := jwt.ParseSigned(token, signatureAlgorithms)
+	if err != nil {
+		return identity.Identity{}, fmt.Errorf("%w: couldn't parse token: %w", types.ErrInvalidCredentials, err)
+	}
+	key, err := v.keys.key(ctx, tok.Headers[0].KeyID)
+	if err != nil {
+		return identity.Identity{}, fmt.Errorf("%w: %w", types.ErrInvalidCredentials, err)
+	}
+	var c tokenClaims
+	if err := tok.Claims(key.Key, &c); err != nil {
+		return identity.Identity{}, fmt.Errorf("%w: invalid token signature: %w", types.ErrInvalidCredentials, err)
+	}
+	if err := v.validate(c.Claims); err != nil {
+		return identity.Identity{}, err
+	}
+	return identity.Identity{
+		Subject:           c.Subject,
+		Issuer:            c.Issuer,
+		Audience:          c.Audience,
+		ExpiresAt:         c.Expiry.Time(),
+		PreferredUsername: c.PreferredUsername,
+		Name:              c.Name,
+		Email:             c.Email,
+	}, nil
+}
+
+func (v *Verifier) validate(c jwt.Claims) error {
+	if c.Expiry == nil {
+		return fmt.Errorf("%w: token has no expiry", types.ErrInvalidCredentials)
+	}
+	if c.Subject == "" {
+		return fmt.Errorf("%w: token has no subject", types.ErrInvalidCredentials)
+	}
+	expected := jwt.Expected{
+		Issuer: v.issuer,
+		Time:   time.Now(),
+	}
+	if v.audience != "" {
+		expected.AnyAudience = jwt.Audience{v.audience}
+	}
+	if err := c.ValidateWithLeeway(expected, leeway); err != nil {
+		return fmt.Errorf("%w: %w", types.ErrInvalidCredentials, err)
+	}
+	return nil
+}
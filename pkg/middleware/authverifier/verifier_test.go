@@ -0,0 +1,141 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authverifier_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/middleware/authverifier"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/types"
+	"github.com/alecthomas/assert/v2"
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+)
+
+const (
+	issuer   = "https://keycloak.example.org/realms/dataloft"
+	audience = "cma-app"
+)
+
+func TestVerify(t *testing.T) {
+	ctx := context.Background()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	jwks := jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{
+		Key: &key.PublicKey, KeyID: "k1", Algorithm: string(jose.RS256), Use: "sig",
+	}}}
+	data, err := json.Marshal(jwks)
+	assert.NoError(t, err)
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	assert.NoError(t, os.WriteFile(jwksFile, data, 0o600))
+	v, err := authverifier.New(ctx, jwksFile, issuer, audience)
+	assert.NoError(t, err)
+
+	now := time.Now()
+	valid := jwt.Claims{
+		Subject:  "user-1",
+		Issuer:   issuer,
+		Audience: jwt.Audience{audience, "account"},
+		Expiry:   jwt.NewNumericDate(now.Add(time.Hour)),
+		IssuedAt: jwt.NewNumericDate(now),
+	}
+	with := func(change func(c *jwt.Claims)) jwt.Claims {
+		c := valid
+		change(&c)
+		return c
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		subject string
+		err     error
+	}{
+		{name: "Valid", token: sign(t, key, "k1", valid), subject: "user-1"},
+		{
+			name:  "Expired",
+			token: sign(t, key, "k1", with(func(c *jwt.Claims) { c.Expiry = jwt.NewNumericDate(now.Add(-time.Hour)) })),
+			err:   types.ErrInvalidCredentials,
+		},
+		{
+			name:  "NoExpiry",
+			token: sign(t, key, "k1", with(func(c *jwt.Claims) { c.Expiry = nil })),
+			err:   types.ErrInvalidCredentials,
+		},
+		{
+			name:  "WrongIssuer",
+			token: sign(t, key, "k1", with(func(c *jwt.Claims) { c.Issuer = "https://evil.example.org" })),
+			err:   types.ErrInvalidCredentials,
+		},
+		{
+			name:  "WrongAudience",
+			token: sign(t, key, "k1", with(func(c *jwt.Claims) { c.Audience = jwt.Audience{"account"} })),
+			err:   types.ErrInvalidCredentials,
+		},
+		{name: "UnknownKey", token: sign(t, key, "k2", valid), err: types.ErrInvalidCredentials},
+		{name: "WrongSignature", token: sign(t, otherKey, "k1", valid), err: types.ErrInvalidCredentials},
+		{name: "Encrypted", token: encrypt(t, otherKey, valid), err: authverifier.ErrEncrypted},
+		{name: "Garbage", token: "not-a-token", err: types.ErrInvalidCredentials},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id, err := v.Verify(ctx, tt.token)
+			assert.IsError(t, err, tt.err)
+			assert.Equal(t, tt.subject, id.Subject)
+		})
+	}
+}
+
+func sign(t *testing.T, key *rsa.PrivateKey, keyID string, c jwt.Claims) string {
+	t.Helper()
+	signer, err := jose.NewSigner(
+		jose.SigningKey{Algorithm: jose.RS256, Key: key},
+		(&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", keyID),
+	)
+	assert.NoError(t, err)
+	token, err := jwt.Signed(signer).Claims(c).Serialize()
+	assert.NoError(t, err)
+	return token
+}
+
+// encrypt creates a JWE for the provider key, with the claims replicated in its header.
+func encrypt(t *testing.T, providerKey *rsa.PrivateKey, c jwt.Claims) string {
+	t.Helper()
+	opts := (&jose.EncrypterOptions{}).WithType("JWT").
+		WithHeader("sub", c.Subject).
+		WithHeader("iss", c.Issuer).
+		WithHeader("aud", c.Audience)
+	if c.Expiry != nil {
+		opts = opts.WithHeader("exp", c.Expiry)
+	}
+	encrypter, err := jose.NewEncrypter(
+		jose.A256GCM, jose.Recipient{Algorithm: jose.RSA_OAEP_256, Key: &providerKey.PublicKey}, opts,
+	)
+	assert.NoError(t, err)
+	token, err := jwt.Encrypted(encrypter).Claims(c).Serialize()
+	assert.NoError(t, err)
+	return token
+}
@@ -0,0 +1,80 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package middleware
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/logging"
+	"github.com/gin-gonic/gin"
+)
+
+// forwardedHeaders are the headers a reverse proxy tells the original URL of a request with.
+var forwardedHeaders = []string{"X-Forwarded-Proto", "X-Forwarded-Host"}
+
+// ParseTrustedProxies parses the IP addresses or CIDR ranges of trusted reverse proxies.
+func ParseTrustedProxies(proxies []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(proxies))
+	for _, proxy := range proxies {
+		if !strings.Contains(proxy, "/") {
+			addr, err := netip.ParseAddr(proxy)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", proxy, err)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+// ForwardedHeaders removes the X-Forwarded-Proto and X-Forwarded-Host headers from requests that
+// don't come directly from one of the trusted proxies, so clients can't choose the URLs the
+// backend hands out.
+func ForwardedHeaders(trustedProxies []netip.Prefix) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !trusted(trustedProxies, c.RemoteIP()) {
+			for _, header := range forwardedHeaders {
+				if c.Request.Header.Get(header) != "" {
+					logging.Extract(c).Debug("Ignoring header from untrusted client", "header", header)
+					c.Request.Header.Del(header)
+				}
+			}
+		}
+		c.Next()
+	}
+}
+
+func trusted(trustedProxies []netip.Prefix, remoteIP string) bool {
+	addr, err := netip.ParseAddr(remoteIP)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/middleware"
+	"github.com/alecthomas/assert/v2"
+	"github.com/gin-gonic/gin"
+)
+
+func TestForwardedHeaders(t *testing.T) {
+	proxies, err := middleware.ParseTrustedProxies([]string{"10.0.0.0/8", "192.0.2.1", "2001:db8::/32"})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		want       string
+	}{
+		{name: "TrustedRange", remoteAddr: "10.1.2.3:1234", want: "https://cma.example.org"},
+		{name: "TrustedAddress", remoteAddr: "192.0.2.1:1234", want: "https://cma.example.org"},
+		{name: "TrustedIPv6", remoteAddr: "[2001:db8::1]:1234", want: "https://cma.example.org"},
+		{name: "Untrusted", remoteAddr: "192.0.2.2:1234", want: "://"},
+		{name: "UntrustedIPv6", remoteAddr: "[2001:db9::1]:1234", want: "://"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gin.New()
+			r.Use(middleware.ForwardedHeaders(proxies))
+			r.GET("/", func(c *gin.Context) {
+				c.String(http.StatusOK, c.GetHeader("X-Forwarded-Proto")+"://"+c.GetHeader("X-Forwarded-Host"))
+			})
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set("X-Forwarded-Proto", "https")
+			req.Header.Set("X-Forwarded-Host", "cma.example.org")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			assert.Equal(t, tt.want, w.Body.String())
+		})
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	_, err := middleware.ParseTrustedProxies([]string{"not-an-ip"})
+	assert.Error(t, err)
+	_, err = middleware.ParseTrustedProxies([]string{"10.0.0.0/33"})
+	assert.Error(t, err)
+	proxies, err := middleware.ParseTrustedProxies(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(proxies))
+}
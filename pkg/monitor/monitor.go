@@ -0,0 +1,160 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package monitor runs the background monitors that poll the catalogues. Every monitor runs at
+// its own interval with some jitter, backs off when it fails, and runs right away on SIGUSR1.
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/logging"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/waitgroup"
+)
+
+// Func runs the monitor once. On error the next run is delayed further.
+type Func func(ctx context.Context) error
+
+// Config is when a monitor runs.
+type Config struct {
+	// Interval is the time between two runs.
+	Interval time.Duration
+	// Jitter is the fraction of the delay before the next run that it is randomly changed by, so
+	// that monitors and replicas don't poll at the same time.
+	Jitter float64
+	// MaxBackoff is the longest delay after failed runs, the delay doubles with every failed run
+	// in a row.
+	MaxBackoff time.Duration
+}
+
+// Delay returns the time to wait before the next run after the given number of failed runs in a
+// row.
+func (c Config) Delay(failures int) time.Duration {
+	d := c.Interval
+	for i := 0; i < failures && d < c.MaxBackoff; i++ {
+		d = min(2*d, c.MaxBackoff)
+	}
+	if c.Jitter > 0 {
+		d += time.Duration((2*rand.Float64() - 1) * c.Jitter * float64(d)) //nolint:gosec
+	}
+	return d
+}
+
+type monitor struct {
+	name    string
+	cfg     Config
+	fn      Func
+	refresh chan struct{}
+}
+
+// Runner runs the started monitors.
+type Runner struct {
+	sync.Mutex
+	monitors []*monitor
+}
+
+// New creates a runner without monitors.
+func New() *Runner {
+	return &Runner{}
+}
+
+// Start runs the monitor right away, and then until the context is done.
+func (r *Runner) Start(ctx context.Context, name string, cfg Config, fn Func) error {
+	if cfg.Interval <= 0 {
+		return fmt.Errorf("invalid interval %s of the %s monitor", cfg.Interval, name)
+	}
+	m := &monitor{
+		name:    name,
+		cfg:     cfg,
+		fn:      fn,
+		refresh: make(chan struct{}, 1),
+	}
+	r.Lock()
+	r.monitors = append(r.monitors, m)
+	r.Unlock()
+
+	wg := waitgroup.Extract(ctx)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		m.run(ctx)
+	}()
+	return nil
+}
+
+// Refresh runs all monitors right away, a monitor that is running already runs again after it.
+func (r *Runner) Refresh() {
+	r.Lock()
+	defer r.Unlock()
+	for _, m := range r.monitors {
+		select {
+		case m.refresh <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Run refreshes the monitors on SIGUSR1 until the context is done.
+func (r *Runner) Run(ctx context.Context) {
+	logger := logging.Extract(ctx)
+	usr1 := make(chan os.Signal, 1)
+	signal.Notify(usr1, syscall.SIGUSR1)
+	defer signal.Stop(usr1)
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("Context done, stopping monitor refreshes")
+			return
+		case <-usr1:
+			logger.Info("Got SIGUSR1, refreshing monitors")
+			r.Refresh()
+		}
+	}
+}
+
+func (m *monitor) run(ctx context.Context) {
+	logger := logging.Extract(ctx).With("monitor_type", m.name)
+	logger.Info("Starting monitor", "interval", m.cfg.Interval)
+	ctx = logging.Inject(ctx, logger)
+	failures := 0
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("Context done, stopping monitor")
+			return
+		case <-timer.C:
+		case <-m.refresh:
+			logger.Info("Refreshing monitor")
+		}
+		if err := m.fn(ctx); err != nil {
+			failures++
+			logger.Error("Monitor run failed", "error", err, "failures", failures)
+		} else {
+			failures = 0
+		}
+		delay := m.cfg.Delay(failures)
+		logger.Debug("Waiting for next run", "delay", delay)
+		timer.Reset(delay)
+	}
+}
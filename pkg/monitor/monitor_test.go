@@ -0,0 +1,79 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package monitor_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/monitor"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/waitgroup"
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestDelay(t *testing.T) {
+	cfg := monitor.Config{Interval: time.Minute, MaxBackoff: 10 * time.Minute}
+	tests := []struct {
+		name     string
+		failures int
+		want     time.Duration
+	}{
+		{name: "Success", failures: 0, want: time.Minute},
+		{name: "OneFailure", failures: 1, want: 2 * time.Minute},
+		{name: "ThreeFailures", failures: 3, want: 8 * time.Minute},
+		{name: "Capped", failures: 20, want: 10 * time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, cfg.Delay(tt.failures))
+		})
+	}
+}
+
+func TestDelayJitter(t *testing.T) {
+	cfg := monitor.Config{Interval: time.Minute, Jitter: 0.1}
+	for range 100 {
+		d := cfg.Delay(0)
+		assert.True(t, d >= 54*time.Second && d <= 66*time.Second, "delay %s", d)
+	}
+}
+
+func TestRefresh(t *testing.T) {
+	var wg sync.WaitGroup
+	ctx, cancel := context.WithCancel(waitgroup.Inject(context.Background(), &wg))
+	runs := make(chan struct{}, 10)
+	r := monitor.New()
+	err := r.Start(ctx, "test", monitor.Config{Interval: time.Hour}, func(context.Context) error {
+		runs <- struct{}{}
+		return errors.New("failed")
+	})
+	assert.NoError(t, err)
+
+	<-runs
+	r.Refresh()
+	select {
+	case <-runs:
+	case <-time.After(5 * time.Second):
+		t.Fatal("monitor wasn't refreshed")
+	}
+	cancel()
+	wg.Wait()
+
+	assert.Error(t, r.Start(ctx, "invalid", monitor.Config{}, nil))
+}
@@ -0,0 +1,221 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package providerkeys loads and validates the public keys clients encrypt tokens for providers
+// with.
+package providerkeys
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/logging"
+	"github.com/go-jose/go-jose/v4"
+)
+
+// minRSABits is the minimum size of an RSA key.
+const minRSABits = 2048
+
+// ErrInvalidKey is returned for keys that can't be used to encrypt tokens for a provider.
+var ErrInvalidKey = errors.New("invalid provider key")
+
+// Keys are the public keys of the providers, by provider URL. The first key of a provider is the
+// current one, further keys are kept while clients rotate.
+type Keys map[string][]jose.JSONWebKey
+
+// Load reads the keys from a JSON file that maps provider URLs to a base64 encoded JWK, or a list
+// of them. Invalid keys are logged and skipped, or fail loading if strict is set.
+func Load(ctx context.Context, file string, strict bool) (Keys, error) {
+	logger := logging.Extract(ctx)
+	keys := Keys{}
+	if file == "" {
+		return keys, nil
+	}
+
+	logger.Info("Loading provider public keys", "file", file)
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read provider public keys: %w", err)
+	}
+	var entries map[string]json.RawMessage
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("couldn't parse provider public keys: %w", err)
+	}
+	for providerURL, entry := range entries {
+		encoded, err := decodeEntry(entry)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't parse keys of provider %s: %w", providerURL, err)
+		}
+		seen := map[string]bool{}
+		for i, e := range encoded {
+			key, err := Decode(e)
+			if err == nil && seen[key.KeyID] {
+				err = fmt.Errorf("%w: duplicate kid %q", ErrInvalidKey, key.KeyID)
+			}
+			if err != nil {
+				if strict {
+					return nil, fmt.Errorf("key %d of provider %s: %w", i, providerURL, err)
+				}
+				logger.Error("Skipping invalid provider key", "provider_url", providerURL, "index", i, "error", err)
+				continue
+			}
+			seen[key.KeyID] = true
+			keys[providerURL] = append(keys[providerURL], key)
+		}
+		if len(keys[providerURL]) == 0 {
+			logger.Warn("Provider has no valid public key", "provider_url", providerURL)
+		}
+	}
+	return keys, nil
+}
+
+// Diff describes the keys that were added, removed or changed, by provider URL and kid.
+func Diff(previous, current Keys) []string {
+	var changes []string
+	for providerURL, keys := range current {
+		before := byKeyID(previous[providerURL])
+		for _, k := range keys {
+			prev, ok := before[k.KeyID]
+			switch {
+			case !ok:
+				changes = append(changes, fmt.Sprintf("%s: added key %q", providerURL, k.KeyID))
+			case !sameKey(prev, k):
+				changes = append(changes, fmt.Sprintf("%s: changed key %q", providerURL, k.KeyID))
+			}
+		}
+	}
+	for providerURL, keys := range previous {
+		after := byKeyID(current[providerURL])
+		for _, k := range keys {
+			if _, ok := after[k.KeyID]; !ok {
+				changes = append(changes, fmt.Sprintf("%s: removed key %q", providerURL, k.KeyID))
+			}
+		}
+	}
+	slices.Sort(changes)
+	return changes
+}
+
+func byKeyID(keys []jose.JSONWebKey) map[string]jose.JSONWebKey {
+	m := make(map[string]jose.JSONWebKey, len(keys))
+	for _, k := range keys {
+		m[k.KeyID] = k
+	}
+	return m
+}
+
+func sameKey(a, b jose.JSONWebKey) bool {
+	ta, errA := a.Thumbprint(crypto.SHA256)
+	tb, errB := b.Thumbprint(crypto.SHA256)
+	return errA == nil && errB == nil && bytes.Equal(ta, tb) && a.Algorithm == b.Algorithm
+}
+
+// decodeEntry accepts a single base64 encoded JWK, or a list of them.
+func decodeEntry(entry json.RawMessage) ([]string, error) {
+	var single string
+	if err := json.Unmarshal(entry, &single); err == nil {
+		return []string{single}, nil
+	}
+	var list []string
+	if err := json.Unmarshal(entry, &list); err != nil {
+		return nil, errors.New("expected a base64 encoded JWK or a list of them")
+	}
+	return list, nil
+}
+
+// Decode parses and validates a base64 encoded JWK.
+func Decode(encoded string) (jose.JSONWebKey, error) {
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return jose.JSONWebKey{}, fmt.Errorf("%w: couldn't decode base64: %w", ErrInvalidKey, err)
+	}
+	var key jose.JSONWebKey
+	if err := json.Unmarshal(data, &key); err != nil {
+		return jose.JSONWebKey{}, fmt.Errorf("%w: couldn't parse JWK: %w", ErrInvalidKey, err)
+	}
+	if err := Validate(key); err != nil {
+		return jose.JSONWebKey{}, err
+	}
+	return key, nil
+}
+
+// Parse parses and validates a JWK given either as JSON, or base64 encoded.
+func Parse(s string) (jose.JSONWebKey, error) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "{") {
+		return Decode(s)
+	}
+	var key jose.JSONWebKey
+	if err := json.Unmarshal([]byte(s), &key); err != nil {
+		return jose.JSONWebKey{}, fmt.Errorf("%w: couldn't parse JWK: %w", ErrInvalidKey, err)
+	}
+	if err := Validate(key); err != nil {
+		return jose.JSONWebKey{}, err
+	}
+	return key, nil
+}
+
+// Encode returns the public part of the key as base64 encoded JWK, the format clients expect in
+// the public key of a provider.
+func Encode(key jose.JSONWebKey) (string, error) {
+	data, err := json.Marshal(key.Public())
+	if err != nil {
+		return "", fmt.Errorf("couldn't marshal key %q: %w", key.KeyID, err)
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// Validate checks that the key is a public encryption key with a kid, and an algorithm that fits
+// its type.
+func Validate(key jose.JSONWebKey) error {
+	if key.KeyID == "" {
+		return fmt.Errorf("%w: missing kid", ErrInvalidKey)
+	}
+	if key.Use != "enc" {
+		return fmt.Errorf("%w: key %q has use %q, expected \"enc\"", ErrInvalidKey, key.KeyID, key.Use)
+	}
+	if !key.Valid() || !key.IsPublic() {
+		return fmt.Errorf("%w: key %q is not a valid public key", ErrInvalidKey, key.KeyID)
+	}
+	alg := jose.KeyAlgorithm(key.Algorithm)
+	switch k := key.Key.(type) {
+	case *rsa.PublicKey:
+		if alg != jose.RSA_OAEP && alg != jose.RSA_OAEP_256 {
+			return fmt.Errorf("%w: alg %q doesn't fit RSA key %q", ErrInvalidKey, alg, key.KeyID)
+		}
+		if k.N.BitLen() < minRSABits {
+			return fmt.Errorf("%w: RSA key %q has less than %d bits", ErrInvalidKey, key.KeyID, minRSABits)
+		}
+	case *ecdsa.PublicKey:
+		switch alg {
+		case jose.ECDH_ES, jose.ECDH_ES_A128KW, jose.ECDH_ES_A192KW, jose.ECDH_ES_A256KW:
+		default:
+			return fmt.Errorf("%w: alg %q doesn't fit EC key %q", ErrInvalidKey, alg, key.KeyID)
+		}
+	default:
+		return fmt.Errorf("%w: unsupported kty of key %q", ErrInvalidKey, key.KeyID)
+	}
+	return nil
+}
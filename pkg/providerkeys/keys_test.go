@@ -0,0 +1,97 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package providerkeys_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/providerkeys"
+	"github.com/alecthomas/assert/v2"
+	"github.com/go-jose/go-jose/v4"
+)
+
+//nolint:lll
+func TestLoad(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	smallKey, err := rsa.GenerateKey(rand.Reader, 1024)
+	assert.NoError(t, err)
+
+	encode := func(k jose.JSONWebKey) string {
+		data, err := json.Marshal(k)
+		assert.NoError(t, err)
+		return base64.StdEncoding.EncodeToString(data)
+	}
+	valid := func(kid string) jose.JSONWebKey {
+		return jose.JSONWebKey{Key: &rsaKey.PublicKey, KeyID: kid, Algorithm: string(jose.RSA_OAEP_256), Use: "enc"}
+	}
+	with := func(k jose.JSONWebKey, change func(k *jose.JSONWebKey)) jose.JSONWebKey {
+		change(&k)
+		return k
+	}
+
+	tests := []struct {
+		name    string
+		keys    any
+		valid   []string
+		invalid bool
+	}{
+		{name: "Single", keys: encode(valid("k1")), valid: []string{"k1"}},
+		{name: "Rotation", keys: []string{encode(valid("k2")), encode(valid("k1"))}, valid: []string{"k2", "k1"}},
+		{name: "DuplicateKid", keys: []string{encode(valid("k1")), encode(valid("k1"))}, valid: []string{"k1"}, invalid: true},
+		{name: "MissingKid", keys: encode(valid("")), invalid: true},
+		{name: "SigningKey", keys: encode(with(valid("k1"), func(k *jose.JSONWebKey) { k.Use = "sig" })), invalid: true},
+		{name: "WrongAlg", keys: encode(with(valid("k1"), func(k *jose.JSONWebKey) { k.Algorithm = "RS256" })), invalid: true},
+		{name: "PrivateKey", keys: encode(with(valid("k1"), func(k *jose.JSONWebKey) { k.Key = rsaKey })), invalid: true},
+		{name: "SmallKey", keys: encode(with(valid("k1"), func(k *jose.JSONWebKey) { k.Key = &smallKey.PublicKey })), invalid: true},
+		{name: "NotBase64", keys: "No key found", invalid: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(map[string]any{"https://provider.example.org": tt.keys})
+			assert.NoError(t, err)
+			file := filepath.Join(t.TempDir(), "keys.json")
+			assert.NoError(t, os.WriteFile(file, data, 0o600))
+
+			keys, err := providerkeys.Load(context.Background(), file, false)
+			assert.NoError(t, err)
+			kids := []string{}
+			for _, k := range keys["https://provider.example.org"] {
+				kids = append(kids, k.KeyID)
+				assert.True(t, k.IsPublic())
+			}
+			want := tt.valid
+			if want == nil {
+				want = []string{}
+			}
+			assert.Equal(t, want, kids)
+
+			_, err = providerkeys.Load(context.Background(), file, true)
+			if tt.invalid {
+				assert.IsError(t, err, providerkeys.ErrInvalidKey)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package reload reloads file based configuration on SIGHUP, or when the files change.
+package reload
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/logging"
+	"github.com/fsnotify/fsnotify"
+)
+
+// debounce is how long to wait for more changes after a file changed, editors and kubernetes
+// change a file in several steps.
+const debounce = 500 * time.Millisecond
+
+// Func loads the configuration again and swaps it in. On error the current configuration has to
+// be kept.
+type Func func(ctx context.Context) error
+
+type entry struct {
+	name  string
+	files []string
+	fn    Func
+}
+
+// Reloader runs the registered reload functions.
+type Reloader struct {
+	sync.Mutex
+	entries []entry
+	watch   bool
+}
+
+// New creates a reloader, if watch is set files are watched for changes besides reloading on
+// SIGHUP.
+func New(watch bool) *Reloader {
+	return &Reloader{watch: watch}
+}
+
+// Register adds a reload function for the given files, empty file names are ignored. Without any
+// files the function isn't registered.
+func (r *Reloader) Register(name string, fn Func, files ...string) {
+	var fs []string
+	for _, f := range files {
+		if f != "" {
+			fs = append(fs, filepath.Clean(f))
+		}
+	}
+	if len(fs) == 0 {
+		return
+	}
+	r.Lock()
+	defer r.Unlock()
+	r.entries = append(r.entries, entry{name: name, files: fs, fn: fn})
+}
+
+// Run reloads on SIGHUP, and on changes to the files if watching, until the context is done.
+func (r *Reloader) Run(ctx context.Context) {
+	logger := logging.Extract(ctx)
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var events <-chan fsnotify.Event
+	var errs <-chan error
+	if r.watch {
+		watcher, err := r.newWatcher()
+		if err != nil {
+			logger.Error("Couldn't watch config files, only reloading on SIGHUP", "error", err)
+		} else {
+			defer watcher.Close()
+			events = watcher.Events
+			errs = watcher.Errors
+		}
+	}
+
+	// changed collects the directories that changed until the debounce timer fires.
+	changed := map[string]bool{}
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+	defer timer.Stop()
+	logger.Info("Waiting for config reloads")
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("Context done, stopping config reloads")
+			return
+		case <-hup:
+			logger.Info("Got SIGHUP, reloading config")
+			r.reload(ctx, func(entry) bool { return true })
+		case ev := <-events:
+			changed[filepath.Dir(ev.Name)] = true
+			timer.Reset(debounce)
+		case err := <-errs:
+			logger.Error("Error watching config files", "error", err)
+		case <-timer.C:
+			dirs := changed
+			changed = map[string]bool{}
+			r.reload(ctx, func(e entry) bool {
+				for _, f := range e.files {
+					if dirs[filepath.Dir(f)] {
+						return true
+					}
+				}
+				return false
+			})
+		}
+	}
+}
+
+// newWatcher watches the directories of the files, as files in mounted config maps and secrets
+// are replaced by swapping a symlink, which isn't seen when watching the file itself.
+func (r *Reloader) newWatcher() (*fsnotify.Watcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	r.Lock()
+	defer r.Unlock()
+	for _, e := range r.entries {
+		for _, f := range e.files {
+			if err := watcher.Add(filepath.Dir(f)); err != nil {
+				watcher.Close()
+				return nil, err
+			}
+		}
+	}
+	return watcher, nil
+}
+
+func (r *Reloader) reload(ctx context.Context, match func(entry) bool) {
+	logger := logging.Extract(ctx)
+	r.Lock()
+	entries := make([]entry, 0, len(r.entries))
+	for _, e := range r.entries {
+		if match(e) {
+			entries = append(entries, e)
+		}
+	}
+	r.Unlock()
+	for _, e := range entries {
+		logger.Info("Reloading config", "config", e.name)
+		if err := e.fn(ctx); err != nil {
+			logger.Error("Couldn't reload config, keeping the current one", "config", e.name, "error", err)
+		}
+	}
+}
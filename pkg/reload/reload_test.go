@@ -0,0 +1,70 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package reload_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/reload"
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestReloadOnChange(t *testing.T) {
+	dir := t.TempDir()
+	watched := filepath.Join(dir, "keys.json")
+	assert.NoError(t, os.WriteFile(watched, []byte("{}"), 0o600))
+	other := filepath.Join(t.TempDir(), "other.json")
+	assert.NoError(t, os.WriteFile(other, []byte("{}"), 0o600))
+
+	reloaded := make(chan string, 10)
+	r := reload.New(true)
+	r.Register("keys", func(context.Context) error {
+		reloaded <- "keys"
+		return nil
+	}, watched)
+	r.Register("other", func(context.Context) error {
+		reloaded <- "other"
+		return nil
+	}, other)
+	r.Register("nothing", func(context.Context) error {
+		reloaded <- "nothing"
+		return nil
+	}, "")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.Run(ctx)
+	}()
+	// Give the watcher time to start.
+	time.Sleep(100 * time.Millisecond)
+
+	assert.NoError(t, os.WriteFile(watched, []byte(`{"a": "b"}`), 0o600))
+	select {
+	case name := <-reloaded:
+		assert.Equal(t, "keys", name)
+	case <-time.After(5 * time.Second):
+		t.Fatal("config wasn't reloaded")
+	}
+	cancel()
+	<-done
+	assert.Equal(t, 0, len(reloaded))
+}
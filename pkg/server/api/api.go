@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/events"
 	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/logging"
 	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/types"
 	"github.com/gin-gonic/gin"
@@ -30,9 +31,14 @@ import (
 // Type Routes contains all the routes for the API.
 type Routes struct {
 	am types.AccessManager
+	cm types.ConsentManager
+	co types.ContributionOrchestrator
 	dc types.DataspaceConnector
+	eb events.Bus
 	pl types.ProviderLister
 	sl types.StudyLister
+	sm types.ShareManager
+	tm types.TransferManager
 }
 
 // New returns a new Routes instance with the appropriate connectors.
@@ -40,26 +46,51 @@ func New(
 	ps types.ProviderLister,
 	dc types.DataspaceConnector,
 	sl types.StudyLister,
+	am types.AccessManager,
+	sm types.ShareManager,
+	cm types.ConsentManager,
+	co types.ContributionOrchestrator,
+	tm types.TransferManager,
+	eb events.Bus,
 ) *Routes {
 	return &Routes{
 		pl: ps,
 		dc: dc,
 		sl: sl,
+		am: am,
+		sm: sm,
+		cm: cm,
+		co: co,
+		tm: tm,
+		eb: eb,
 	}
 }
 
 // AddRoutes adds all routes to the given router group.
 func (r *Routes) AddRoutes(rg *gin.RouterGroup) {
+	rg.GET("/consents", r.getConsents)
+	rg.GET("/events", r.getEvents)
 	rg.GET("/policies", r.getPolicies)
 	rg.POST("/policies", r.postPolicy)
 	rg.DELETE("/policies/:policy_id", r.deletePolicy)
 	rg.GET("/providers", r.getProviders)
 	rg.GET("/providers/:provider_id/files", r.getProviderFiles)
+	rg.GET("/providers/:provider_id/jwks", r.getProviderJWKS)
 	rg.GET("/providers/:provider_id/files/:file_id", r.getProviderFile)
 	rg.GET("/providers/:provider_id/files/:file_id/credentials", r.getDownloadCredentials)
+	rg.POST("/shares", r.postShare)
+	rg.GET("/shares/:share_id", r.getSharedFile)
 	rg.GET("/studies", r.getStudies)
 	rg.GET("/studies/:study_id", r.getStudyById)
+	rg.GET("/studies/:study_id/consents", r.getStudyConsents)
+	rg.POST("/studies/:study_id/consents", r.postStudyConsent)
+	rg.DELETE("/studies/:study_id/consents/:consent_id", r.deleteStudyConsent)
+	rg.GET("/studies/:study_id/consents/:consent_id/contribution", r.getStudyContribution)
 	rg.GET("/studies/:study_id/files", r.getStudyFiles)
+	rg.POST("/transfers", r.postTransfer)
+	rg.GET("/transfers/:transfer_id", r.getTransfer)
+	rg.DELETE("/transfers/:transfer_id", r.deleteTransfer)
+	rg.GET("/transfers/:transfer_id/content", r.getTransferContent)
 }
 
 func checkError(c *gin.Context, err error) bool {
@@ -91,6 +122,18 @@ func checkError(c *gin.Context, err error) bool {
 				Status: "Upstream service unavailable",
 				Error:  err.Error(),
 			})
+		case errors.Is(err, types.ErrConflict):
+			// As this is a defined error, we return the error message to the client.
+			c.JSON(http.StatusConflict, types.ErrorResponse{
+				Status: "Conflict",
+				Error:  err.Error(),
+			})
+		case errors.Is(err, types.ErrRangeNotSatisfiable):
+			// As this is a defined error, we return the error message to the client.
+			c.JSON(http.StatusRequestedRangeNotSatisfiable, types.ErrorResponse{
+				Status: "Range not satisfiable",
+				Error:  err.Error(),
+			})
 		default:
 			// Do we want to return the error to the client?
 			c.JSON(http.StatusInternalServerError, types.ErrorResponse{
@@ -17,16 +17,27 @@
 package api_test
 
 import (
+	"bufio"
 	"bytes"
+	"context"
+	"crypto/rsa"
+	"io"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	mtypes "github.com/HEALTH-X-dataLOFT/cma-backend/mocks/github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/types"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/events"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/identity"
 	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api"
 	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/types"
 	"github.com/alecthomas/assert/v2"
 	"github.com/gin-gonic/gin"
+	"github.com/go-jose/go-jose/v4"
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/mock"
 )
 
@@ -41,16 +52,24 @@ func TestAPIRoutes(t *testing.T) {
 		providerListerParams     []mockParams
 		dataspaceConnectorParams []mockParams
 		studyListerParams        []mockParams
+		accessManagerParams      []mockParams
+		shareManagerParams       []mockParams
+		consentManagerParams     []mockParams
+		orchestratorParams       []mockParams
+		transferManagerParams    []mockParams
 	}
 	type request struct {
-		method string
-		path   string
-		body   []byte
+		method  string
+		path    string
+		body    []byte
+		headers map[string]string
 	}
 	type expect struct {
 		status int
 		body   string
 	}
+	withdrawnAt := time.Date(2025, 2, 1, 12, 0, 0, 0, time.UTC)
+	pseudonymized := types.AccessTypePseudonymized
 	tests := []struct {
 		name    string
 		request request
@@ -90,6 +109,817 @@ func TestAPIRoutes(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "TestListPolicies",
+			request: request{
+				method: http.MethodGet,
+				path:   "/api/policies",
+				body:   nil,
+			},
+			expect: expect{
+				status: http.StatusOK,
+				body:   `[{"id":"5ba4ed5a-7ad0-4cbb-bb8e-6ab2e6e0ab1e","target":{"provider_id":"37737548-2926-4bd9-b2e6-48fa669e31aa","file_id":"842b90d4-4007-4f67-87ae-301317d728b6"},"assignee":{"id":"","name":"","description":"","logo_uri":"","contact_information":"","verifiable_credential":"","MetadataKey":"","provider_url":"","public_key":""},"access_type":1}]`,
+			},
+			mocks: mocks{
+				accessManagerParams: []mockParams{
+					{
+						method:    "ListPolicies",
+						arguments: []any{mock.Anything},
+						returns: []any{
+							[]types.Policy{
+								{
+									ID: uuid.MustParse("5ba4ed5a-7ad0-4cbb-bb8e-6ab2e6e0ab1e"),
+									Target: types.Target{
+										ProviderID: "37737548-2926-4bd9-b2e6-48fa669e31aa",
+										FileID:     uuid.MustParse("842b90d4-4007-4f67-87ae-301317d728b6"),
+									},
+									AccessType: types.AccessTypeAnonymized,
+								},
+							},
+							nil,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "TestListPoliciesWithoutCredentials",
+			request: request{
+				method: http.MethodGet,
+				path:   "/api/policies",
+				body:   nil,
+			},
+			expect: expect{
+				status: http.StatusUnauthorized,
+				body:   `{"status":"Invalid credentials","error":"invalid credentials"}`,
+			},
+			mocks: mocks{
+				accessManagerParams: []mockParams{
+					{
+						method:    "ListPolicies",
+						arguments: []any{mock.Anything},
+						returns:   []any{nil, types.ErrInvalidCredentials},
+					},
+				},
+			},
+		},
+		{
+			name: "TestDeletePolicy",
+			request: request{
+				method: http.MethodDelete,
+				path:   "/api/policies/5ba4ed5a-7ad0-4cbb-bb8e-6ab2e6e0ab1e",
+				body:   nil,
+			},
+			expect: expect{
+				status: http.StatusNoContent,
+				body:   ``,
+			},
+			mocks: mocks{
+				accessManagerParams: []mockParams{
+					{
+						method:    "DeletePolicy",
+						arguments: []any{mock.Anything, uuid.MustParse("5ba4ed5a-7ad0-4cbb-bb8e-6ab2e6e0ab1e")},
+						returns:   []any{nil},
+					},
+				},
+			},
+		},
+		{
+			name: "TestDeletePolicyInvalidID",
+			request: request{
+				method: http.MethodDelete,
+				path:   "/api/policies/not-a-uuid",
+				body:   nil,
+			},
+			expect: expect{
+				status: http.StatusBadRequest,
+				body:   `{"status":"Invalid ID","error":"The given policy ID is invalid"}`,
+			},
+		},
+		{
+			name: "TestPostShare",
+			request: request{
+				method: http.MethodPost,
+				path:   "/api/shares",
+				body:   []byte(`{"target":{"provider_id":"37737548-2926-4bd9-b2e6-48fa669e31aa","file_id":"842b90d4-4007-4f67-87ae-301317d728b6"}}`),
+			},
+			expect: expect{
+				status: http.StatusCreated,
+				body:   `{"ttl":3600,"download_uri":"https://cma.example.org/api/shares/2f3e2b6c-3a29-4bd4-8ad4-9e1f4b7d3c1a","bearer_token":"secret"}`,
+			},
+			mocks: mocks{
+				shareManagerParams: []mockParams{
+					{
+						method: "SubmitShare",
+						arguments: []any{mock.Anything, types.ShareRequest{
+							Target: types.Target{
+								ProviderID: "37737548-2926-4bd9-b2e6-48fa669e31aa",
+								FileID:     uuid.MustParse("842b90d4-4007-4f67-87ae-301317d728b6"),
+							},
+						}},
+						returns: []any{
+							types.ShareResponse{
+								TTL:         3600,
+								DownloadURI: "https://cma.example.org/api/shares/2f3e2b6c-3a29-4bd4-8ad4-9e1f4b7d3c1a",
+								BearerToken: "secret",
+							},
+							nil,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "TestPostShareRelativeURI",
+			request: request{
+				method:  http.MethodPost,
+				path:    "/api/shares",
+				headers: map[string]string{"X-Forwarded-Proto": "https", "X-Forwarded-Host": "cma.example.org"},
+				body:    []byte(`{"target":{"provider_id":"37737548-2926-4bd9-b2e6-48fa669e31aa","file_id":"842b90d4-4007-4f67-87ae-301317d728b6"}}`),
+			},
+			expect: expect{
+				status: http.StatusCreated,
+				body:   `{"ttl":3600,"download_uri":"https://cma.example.org/api/shares/2f3e2b6c-3a29-4bd4-8ad4-9e1f4b7d3c1a","bearer_token":"secret"}`,
+			},
+			mocks: mocks{
+				shareManagerParams: []mockParams{
+					{
+						method:    "SubmitShare",
+						arguments: []any{mock.Anything, mock.Anything},
+						returns: []any{
+							types.ShareResponse{
+								TTL:         3600,
+								DownloadURI: "/api/shares/2f3e2b6c-3a29-4bd4-8ad4-9e1f4b7d3c1a",
+								BearerToken: "secret",
+							},
+							nil,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "TestPostShareWithoutTarget",
+			request: request{
+				method: http.MethodPost,
+				path:   "/api/shares",
+				body:   []byte(`{"key":"abc"}`),
+			},
+			expect: expect{
+				status: http.StatusBadRequest,
+				body:   `{"status":"Invalid request","error":"invalid: share target has no provider"}`,
+			},
+		},
+		{
+			name: "TestGetSharedFile",
+			request: request{
+				method:  http.MethodGet,
+				path:    "/api/shares/2f3e2b6c-3a29-4bd4-8ad4-9e1f4b7d3c1a",
+				headers: map[string]string{"Authorization": "Bearer secret"},
+			},
+			expect: expect{
+				status: http.StatusOK,
+				body:   `{"heart_rate":60}`,
+			},
+			mocks: mocks{
+				shareManagerParams: []mockParams{
+					{
+						method:    "GetSharedFile",
+						arguments: []any{mock.Anything, uuid.MustParse("2f3e2b6c-3a29-4bd4-8ad4-9e1f4b7d3c1a"), "secret"},
+						returns: []any{
+							types.SharedFile{
+								Name:     "heart_rate.json",
+								MimeType: "application/json",
+								Size:     17,
+								Content:  io.NopCloser(strings.NewReader(`{"heart_rate":60}`)),
+							},
+							nil,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "TestGetProviderFileStreamed",
+			request: request{
+				method: http.MethodGet,
+				path:   "/api/providers/37737548-2926-4bd9-b2e6-48fa669e31aa/files/heart-rate",
+			},
+			expect: expect{
+				status: http.StatusOK,
+				body:   `{"heart_rate":60}`,
+			},
+			mocks: mocks{
+				providerListerParams: []mockParams{
+					{
+						method:    "GetProvider",
+						arguments: []any{mock.Anything, "37737548-2926-4bd9-b2e6-48fa669e31aa"},
+						returns: []any{
+							types.Provider{ID: "37737548-2926-4bd9-b2e6-48fa669e31aa", Name: "TestProvider"},
+							nil,
+						},
+					},
+				},
+				dataspaceConnectorParams: []mockParams{
+					{
+						method:    "GetProviderFileInfo",
+						arguments: []any{mock.Anything, "37737548-2926-4bd9-b2e6-48fa669e31aa", "heart-rate"},
+						returns:   []any{types.ProviderFile{ID: "heart-rate", Name: "heart_rate.json"}, nil},
+					},
+					{
+						method:    "GetProviderFile",
+						arguments: []any{mock.Anything, "37737548-2926-4bd9-b2e6-48fa669e31aa", "heart-rate", types.FileRange{}},
+						returns: []any{
+							io.NopCloser(strings.NewReader(`{"heart_rate":60}`)),
+							types.FileMetadata{Size: -1},
+							nil,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "TestGetProviderFileRange",
+			request: request{
+				method:  http.MethodGet,
+				path:    "/api/providers/37737548-2926-4bd9-b2e6-48fa669e31aa/files/heart-rate",
+				headers: map[string]string{"Range": "bytes=1-15"},
+			},
+			expect: expect{
+				status: http.StatusPartialContent,
+				body:   `"heart_rate":60`,
+			},
+			mocks: mocks{
+				providerListerParams: []mockParams{
+					{
+						method:    "GetProvider",
+						arguments: []any{mock.Anything, "37737548-2926-4bd9-b2e6-48fa669e31aa"},
+						returns: []any{
+							types.Provider{ID: "37737548-2926-4bd9-b2e6-48fa669e31aa", Name: "TestProvider"},
+							nil,
+						},
+					},
+				},
+				dataspaceConnectorParams: []mockParams{
+					{
+						method:    "GetProviderFileInfo",
+						arguments: []any{mock.Anything, "37737548-2926-4bd9-b2e6-48fa669e31aa", "heart-rate"},
+						returns:   []any{types.ProviderFile{ID: "heart-rate", Name: "heart_rate.json"}, nil},
+					},
+					{
+						method:    "GetProviderFile",
+						arguments: []any{mock.Anything, "37737548-2926-4bd9-b2e6-48fa669e31aa", "heart-rate", types.FileRange{Range: "bytes=1-15"}},
+						returns: []any{
+							io.NopCloser(strings.NewReader(`"heart_rate":60`)),
+							types.FileMetadata{Size: 15, ContentRange: "bytes 1-15/17", AcceptRanges: true},
+							nil,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "TestGetProviderFileRangeNotSatisfiable",
+			request: request{
+				method:  http.MethodGet,
+				path:    "/api/providers/37737548-2926-4bd9-b2e6-48fa669e31aa/files/heart-rate",
+				headers: map[string]string{"Range": "bytes=100-"},
+			},
+			expect: expect{
+				status: http.StatusRequestedRangeNotSatisfiable,
+				body:   `{"status":"Range not satisfiable","error":"range not satisfiable"}`,
+			},
+			mocks: mocks{
+				providerListerParams: []mockParams{
+					{
+						method:    "GetProvider",
+						arguments: []any{mock.Anything, "37737548-2926-4bd9-b2e6-48fa669e31aa"},
+						returns: []any{
+							types.Provider{ID: "37737548-2926-4bd9-b2e6-48fa669e31aa", Name: "TestProvider"},
+							nil,
+						},
+					},
+				},
+				dataspaceConnectorParams: []mockParams{
+					{
+						method:    "GetProviderFileInfo",
+						arguments: []any{mock.Anything, "37737548-2926-4bd9-b2e6-48fa669e31aa", "heart-rate"},
+						returns:   []any{types.ProviderFile{ID: "heart-rate", Name: "heart_rate.json"}, nil},
+					},
+					{
+						method:    "GetProviderFile",
+						arguments: []any{mock.Anything, "37737548-2926-4bd9-b2e6-48fa669e31aa", "heart-rate", types.FileRange{Range: "bytes=100-"}},
+						returns: []any{
+							nil,
+							types.FileMetadata{},
+							types.ErrRangeNotSatisfiable,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "TestPostStudyConsent",
+			request: request{
+				method: http.MethodPost,
+				path:   "/api/studies/d4ae2ca4-4bc5-4c2a-8cf5-0d9d5d8e3f1a/consents",
+				body:   []byte(`{"research_data":[{"name":"Heart rate","access_type":2}]}`),
+			},
+			expect: expect{
+				status: http.StatusCreated,
+				body:   `{"id":"0b7f5a0e-4d6c-4a0b-9c36-1f8e2a7d9e11","study_id":"d4ae2ca4-4bc5-4c2a-8cf5-0d9d5d8e3f1a","research_data":[{"name":"Heart rate","access_type":2}],"given_at":"2025-03-01T12:00:00Z"}`,
+			},
+			mocks: mocks{
+				studyListerParams: []mockParams{
+					{
+						method:    "GetStudy",
+						arguments: []any{mock.Anything, uuid.MustParse("d4ae2ca4-4bc5-4c2a-8cf5-0d9d5d8e3f1a")},
+						returns: []any{
+							types.Study{
+								ID:           uuid.MustParse("d4ae2ca4-4bc5-4c2a-8cf5-0d9d5d8e3f1a"),
+								ResearchData: []types.ResearchData{{Name: "Heart rate", AccessType: types.AccessTypePseudonymized}},
+							},
+							nil,
+						},
+					},
+					{
+						method:    "ListStudyFiles",
+						arguments: []any{mock.Anything, uuid.MustParse("d4ae2ca4-4bc5-4c2a-8cf5-0d9d5d8e3f1a")},
+						returns: []any{
+							[]types.ProviderFile{{
+								ID:       "heart-rate",
+								Provider: types.Provider{ID: "37737548-2926-4bd9-b2e6-48fa669e31aa"},
+								Matches:  []types.StudyFileMatch{{ResearchData: "Heart rate", Reason: "matches code 8867-4"}},
+							}},
+							nil,
+						},
+					},
+				},
+				orchestratorParams: []mockParams{
+					{
+						method:    "CancelContribution",
+						arguments: []any{mock.Anything, uuid.MustParse("6f1c3b2a-8e4d-4f7a-9b0c-2d5e8a1f3c47")},
+						returns:   []any{nil},
+					},
+					{
+						method:    "StartContribution",
+						arguments: []any{mock.Anything, mock.AnythingOfType("types.Consent"), mock.AnythingOfType("[]types.ProviderFile")},
+						returns:   []any{types.Contribution{Files: make([]types.FileContribution, 1)}, nil},
+					},
+				},
+				consentManagerParams: []mockParams{
+					{
+						method:    "ListStudyConsents",
+						arguments: []any{mock.Anything, uuid.MustParse("d4ae2ca4-4bc5-4c2a-8cf5-0d9d5d8e3f1a")},
+						returns: []any{
+							[]types.Consent{
+								{
+									ID:      uuid.MustParse("6f1c3b2a-8e4d-4f7a-9b0c-2d5e8a1f3c47"),
+									StudyID: uuid.MustParse("d4ae2ca4-4bc5-4c2a-8cf5-0d9d5d8e3f1a"),
+								},
+								{
+									ID:          uuid.MustParse("9a2d4c6e-1b3f-4a5d-8e7c-0f1a2b3c4d5e"),
+									StudyID:     uuid.MustParse("d4ae2ca4-4bc5-4c2a-8cf5-0d9d5d8e3f1a"),
+									WithdrawnAt: &withdrawnAt,
+								},
+							},
+							nil,
+						},
+					},
+					{
+						method: "SubmitConsent",
+						arguments: []any{
+							mock.Anything,
+							uuid.MustParse("d4ae2ca4-4bc5-4c2a-8cf5-0d9d5d8e3f1a"),
+							types.ConsentRequest{ResearchData: []types.ConsentRequestData{{Name: "Heart rate", AccessType: &pseudonymized}}},
+						},
+						returns: []any{
+							types.Consent{
+								ID:           uuid.MustParse("0b7f5a0e-4d6c-4a0b-9c36-1f8e2a7d9e11"),
+								StudyID:      uuid.MustParse("d4ae2ca4-4bc5-4c2a-8cf5-0d9d5d8e3f1a"),
+								ResearchData: []types.ConsentedData{{Name: "Heart rate", AccessType: types.AccessTypePseudonymized}},
+								GivenAt:      time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC),
+							},
+							nil,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "TestPostStudyConsentContributionFails",
+			request: request{
+				method: http.MethodPost,
+				path:   "/api/studies/d4ae2ca4-4bc5-4c2a-8cf5-0d9d5d8e3f1a/consents",
+				body:   []byte(`{"research_data":[{"name":"Heart rate","access_type":0}]}`),
+			},
+			expect: expect{
+				status: http.StatusBadGateway,
+				body:   `{"status":"Upstream service unavailable","error":"bad gateway"}`,
+			},
+			mocks: mocks{
+				studyListerParams: []mockParams{
+					{
+						method:    "GetStudy",
+						arguments: []any{mock.Anything, uuid.MustParse("d4ae2ca4-4bc5-4c2a-8cf5-0d9d5d8e3f1a")},
+						returns: []any{
+							types.Study{
+								ID:           uuid.MustParse("d4ae2ca4-4bc5-4c2a-8cf5-0d9d5d8e3f1a"),
+								ResearchData: []types.ResearchData{{Name: "Heart rate", AccessType: types.AccessTypeFull}},
+							},
+							nil,
+						},
+					},
+					{
+						method:    "ListStudyFiles",
+						arguments: []any{mock.Anything, uuid.MustParse("d4ae2ca4-4bc5-4c2a-8cf5-0d9d5d8e3f1a")},
+						returns:   []any{[]types.ProviderFile{}, nil},
+					},
+				},
+				orchestratorParams: []mockParams{
+					{
+						method:    "StartContribution",
+						arguments: []any{mock.Anything, mock.AnythingOfType("types.Consent"), mock.AnythingOfType("[]types.ProviderFile")},
+						returns:   []any{types.Contribution{}, types.ErrBadGateway},
+					},
+				},
+				consentManagerParams: []mockParams{
+					{
+						method:    "ListStudyConsents",
+						arguments: []any{mock.Anything, uuid.MustParse("d4ae2ca4-4bc5-4c2a-8cf5-0d9d5d8e3f1a")},
+						returns:   []any{[]types.Consent{}, nil},
+					},
+					{
+						method:    "SubmitConsent",
+						arguments: []any{mock.Anything, uuid.MustParse("d4ae2ca4-4bc5-4c2a-8cf5-0d9d5d8e3f1a"), mock.AnythingOfType("types.ConsentRequest")},
+						returns: []any{
+							types.Consent{
+								ID:      uuid.MustParse("0b7f5a0e-4d6c-4a0b-9c36-1f8e2a7d9e11"),
+								StudyID: uuid.MustParse("d4ae2ca4-4bc5-4c2a-8cf5-0d9d5d8e3f1a"),
+							},
+							nil,
+						},
+					},
+					{
+						method: "WithdrawConsent",
+						arguments: []any{
+							mock.Anything,
+							uuid.MustParse("d4ae2ca4-4bc5-4c2a-8cf5-0d9d5d8e3f1a"),
+							uuid.MustParse("0b7f5a0e-4d6c-4a0b-9c36-1f8e2a7d9e11"),
+						},
+						returns: []any{nil},
+					},
+				},
+			},
+		},
+		{
+			name: "TestPostStudyConsentCancelFails",
+			request: request{
+				method: http.MethodPost,
+				path:   "/api/studies/d4ae2ca4-4bc5-4c2a-8cf5-0d9d5d8e3f1a/consents",
+				body:   []byte(`{"research_data":[{"name":"Heart rate","access_type":0}]}`),
+			},
+			expect: expect{
+				status: http.StatusBadGateway,
+				body:   `{"status":"Upstream service unavailable","error":"bad gateway"}`,
+			},
+			mocks: mocks{
+				studyListerParams: []mockParams{
+					{
+						method:    "GetStudy",
+						arguments: []any{mock.Anything, uuid.MustParse("d4ae2ca4-4bc5-4c2a-8cf5-0d9d5d8e3f1a")},
+						returns: []any{
+							types.Study{
+								ID:           uuid.MustParse("d4ae2ca4-4bc5-4c2a-8cf5-0d9d5d8e3f1a"),
+								ResearchData: []types.ResearchData{{Name: "Heart rate", AccessType: types.AccessTypeFull}},
+							},
+							nil,
+						},
+					},
+					{
+						method:    "ListStudyFiles",
+						arguments: []any{mock.Anything, uuid.MustParse("d4ae2ca4-4bc5-4c2a-8cf5-0d9d5d8e3f1a")},
+						returns:   []any{[]types.ProviderFile{}, nil},
+					},
+				},
+				orchestratorParams: []mockParams{
+					{
+						method:    "CancelContribution",
+						arguments: []any{mock.Anything, uuid.MustParse("6f1c3b2a-8e4d-4f7a-9b0c-2d5e8a1f3c47")},
+						returns:   []any{types.ErrBadGateway},
+					},
+				},
+				// The superseded consent stays active, as the new one isn't submitted.
+				consentManagerParams: []mockParams{
+					{
+						method:    "ListStudyConsents",
+						arguments: []any{mock.Anything, uuid.MustParse("d4ae2ca4-4bc5-4c2a-8cf5-0d9d5d8e3f1a")},
+						returns: []any{
+							[]types.Consent{{
+								ID:      uuid.MustParse("6f1c3b2a-8e4d-4f7a-9b0c-2d5e8a1f3c47"),
+								StudyID: uuid.MustParse("d4ae2ca4-4bc5-4c2a-8cf5-0d9d5d8e3f1a"),
+							}},
+							nil,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "TestPostStudyConsentFilesUnavailable",
+			request: request{
+				method: http.MethodPost,
+				path:   "/api/studies/d4ae2ca4-4bc5-4c2a-8cf5-0d9d5d8e3f1a/consents",
+				body:   []byte(`{"research_data":[{"name":"Heart rate","access_type":0}]}`),
+			},
+			expect: expect{
+				status: http.StatusBadGateway,
+				body:   `{"status":"Upstream service unavailable","error":"bad gateway"}`,
+			},
+			mocks: mocks{
+				studyListerParams: []mockParams{
+					{
+						method:    "GetStudy",
+						arguments: []any{mock.Anything, uuid.MustParse("d4ae2ca4-4bc5-4c2a-8cf5-0d9d5d8e3f1a")},
+						returns: []any{
+							types.Study{
+								ID:           uuid.MustParse("d4ae2ca4-4bc5-4c2a-8cf5-0d9d5d8e3f1a"),
+								ResearchData: []types.ResearchData{{Name: "Heart rate", AccessType: types.AccessTypeFull}},
+							},
+							nil,
+						},
+					},
+					{
+						method:    "ListStudyFiles",
+						arguments: []any{mock.Anything, uuid.MustParse("d4ae2ca4-4bc5-4c2a-8cf5-0d9d5d8e3f1a")},
+						returns:   []any{[]types.ProviderFile(nil), types.ErrBadGateway},
+					},
+				},
+			},
+		},
+		{
+			name: "TestPostStudyConsentUnrequestedData",
+			request: request{
+				method: http.MethodPost,
+				path:   "/api/studies/d4ae2ca4-4bc5-4c2a-8cf5-0d9d5d8e3f1a/consents",
+				body:   []byte(`{"research_data":[{"name":"Steps","access_type":2}]}`),
+			},
+			expect: expect{
+				status: http.StatusBadRequest,
+				body:   `{"status":"Invalid request","error":"invalid: research data \"Steps\" is not requested by the study"}`,
+			},
+			mocks: mocks{
+				studyListerParams: []mockParams{
+					{
+						method:    "GetStudy",
+						arguments: []any{mock.Anything, uuid.MustParse("d4ae2ca4-4bc5-4c2a-8cf5-0d9d5d8e3f1a")},
+						returns: []any{
+							types.Study{
+								ID:           uuid.MustParse("d4ae2ca4-4bc5-4c2a-8cf5-0d9d5d8e3f1a"),
+								ResearchData: []types.ResearchData{{Name: "Heart rate", AccessType: types.AccessTypePseudonymized}},
+							},
+							nil,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "TestPostStudyConsentNoAccessType",
+			request: request{
+				method: http.MethodPost,
+				path:   "/api/studies/d4ae2ca4-4bc5-4c2a-8cf5-0d9d5d8e3f1a/consents",
+				body:   []byte(`{"research_data":[{"name":"Heart rate"}]}`),
+			},
+			expect: expect{
+				status: http.StatusBadRequest,
+				body:   `{"status":"Invalid request","error":"invalid: no access type for \"Heart rate\""}`,
+			},
+		},
+		{
+			name: "TestWithdrawStudyConsent",
+			request: request{
+				method: http.MethodDelete,
+				path:   "/api/studies/d4ae2ca4-4bc5-4c2a-8cf5-0d9d5d8e3f1a/consents/0b7f5a0e-4d6c-4a0b-9c36-1f8e2a7d9e11",
+			},
+			expect: expect{
+				status: http.StatusNoContent,
+				body:   "",
+			},
+			mocks: mocks{
+				consentManagerParams: []mockParams{
+					{
+						method: "WithdrawConsent",
+						arguments: []any{
+							mock.Anything,
+							uuid.MustParse("d4ae2ca4-4bc5-4c2a-8cf5-0d9d5d8e3f1a"),
+							uuid.MustParse("0b7f5a0e-4d6c-4a0b-9c36-1f8e2a7d9e11"),
+						},
+						returns: []any{nil},
+					},
+				},
+				orchestratorParams: []mockParams{
+					{
+						method:    "CancelContribution",
+						arguments: []any{mock.Anything, uuid.MustParse("0b7f5a0e-4d6c-4a0b-9c36-1f8e2a7d9e11")},
+						returns:   []any{nil},
+					},
+				},
+			},
+		},
+		{
+			name: "TestGetStudyContribution",
+			request: request{
+				method: http.MethodGet,
+				path:   "/api/studies/d4ae2ca4-4bc5-4c2a-8cf5-0d9d5d8e3f1a/consents/0b7f5a0e-4d6c-4a0b-9c36-1f8e2a7d9e11/contribution",
+			},
+			expect: expect{
+				status: http.StatusOK,
+				body:   `{"consent_id":"0b7f5a0e-4d6c-4a0b-9c36-1f8e2a7d9e11","study_id":"d4ae2ca4-4bc5-4c2a-8cf5-0d9d5d8e3f1a","created_at":"2025-03-01T12:00:00Z","files":[{"provider_id":"37737548-2926-4bd9-b2e6-48fa669e31aa","file_id":"heart-rate","name":"heart_rate.json","research_data":"Heart rate","access_type":2,"state":"completed","attempts":2,"updated_at":"2025-03-01T12:01:00Z"}]}`,
+			},
+			mocks: mocks{
+				orchestratorParams: []mockParams{
+					{
+						method:    "GetContribution",
+						arguments: []any{mock.Anything, uuid.MustParse("0b7f5a0e-4d6c-4a0b-9c36-1f8e2a7d9e11")},
+						returns: []any{
+							types.Contribution{
+								ConsentID: uuid.MustParse("0b7f5a0e-4d6c-4a0b-9c36-1f8e2a7d9e11"),
+								StudyID:   uuid.MustParse("d4ae2ca4-4bc5-4c2a-8cf5-0d9d5d8e3f1a"),
+								CreatedAt: time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC),
+								Files: []types.FileContribution{{
+									ProviderID:   "37737548-2926-4bd9-b2e6-48fa669e31aa",
+									FileID:       "heart-rate",
+									Name:         "heart_rate.json",
+									ResearchData: "Heart rate",
+									AccessType:   types.AccessTypePseudonymized,
+									State:        types.ContributionStateCompleted,
+									Attempts:     2,
+									UpdatedAt:    time.Date(2025, 3, 1, 12, 1, 0, 0, time.UTC),
+								}},
+							},
+							nil,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "TestPostTransfer",
+			request: request{
+				method: http.MethodPost,
+				path:   "/api/transfers",
+				body:   []byte(`{"target":{"provider_id":"37737548-2926-4bd9-b2e6-48fa669e31aa","file_id":"842b90d4-4007-4f67-87ae-301317d728b6"},"kind":"credentials"}`),
+			},
+			expect: expect{
+				status: http.StatusAccepted,
+				body:   `{"id":"6f0d3c1e-3b8a-4c55-9a57-2b4c4f0e8a10","kind":"credentials","target":{"provider_id":"37737548-2926-4bd9-b2e6-48fa669e31aa","file_id":"842b90d4-4007-4f67-87ae-301317d728b6"},"state":"pending","size":-1,"bytes_transferred":0,"created_at":"2025-03-01T12:00:00Z","updated_at":"2025-03-01T12:00:00Z"}`,
+			},
+			mocks: mocks{
+				transferManagerParams: []mockParams{
+					{
+						method: "StartTransfer",
+						arguments: []any{mock.Anything, types.TransferRequest{
+							Target: types.Target{
+								ProviderID: "37737548-2926-4bd9-b2e6-48fa669e31aa",
+								FileID:     uuid.MustParse("842b90d4-4007-4f67-87ae-301317d728b6"),
+							},
+							Kind: types.TransferKindCredentials,
+						}},
+						returns: []any{
+							types.TransferJob{
+								ID:   uuid.MustParse("6f0d3c1e-3b8a-4c55-9a57-2b4c4f0e8a10"),
+								Kind: types.TransferKindCredentials,
+								Target: types.Target{
+									ProviderID: "37737548-2926-4bd9-b2e6-48fa669e31aa",
+									FileID:     uuid.MustParse("842b90d4-4007-4f67-87ae-301317d728b6"),
+								},
+								State:     types.TransferStatePending,
+								Size:      -1,
+								CreatedAt: time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC),
+								UpdatedAt: time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC),
+							},
+							nil,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "TestPostTransferUnknownKind",
+			request: request{
+				method: http.MethodPost,
+				path:   "/api/transfers",
+				body:   []byte(`{"target":{"provider_id":"37737548-2926-4bd9-b2e6-48fa669e31aa","file_id":"842b90d4-4007-4f67-87ae-301317d728b6"},"kind":"upload"}`),
+			},
+			expect: expect{
+				status: http.StatusBadRequest,
+				body:   `{"status":"Invalid request","error":"invalid: unknown transfer kind \"upload\""}`,
+			},
+		},
+		{
+			name: "TestGetTransferContent",
+			request: request{
+				method: http.MethodGet,
+				path:   "/api/transfers/6f0d3c1e-3b8a-4c55-9a57-2b4c4f0e8a10/content",
+			},
+			expect: expect{
+				status: http.StatusOK,
+				body:   `{"heart_rate":60}`,
+			},
+			mocks: mocks{
+				transferManagerParams: []mockParams{
+					{
+						method:    "OpenTransferContent",
+						arguments: []any{mock.Anything, uuid.MustParse("6f0d3c1e-3b8a-4c55-9a57-2b4c4f0e8a10")},
+						returns: []any{
+							io.NopCloser(strings.NewReader(`{"heart_rate":60}`)),
+							types.TransferJob{Name: "heart_rate.json", MimeType: "application/json", Size: 17},
+							nil,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "TestGetTransferContentNotReady",
+			request: request{
+				method: http.MethodGet,
+				path:   "/api/transfers/6f0d3c1e-3b8a-4c55-9a57-2b4c4f0e8a10/content",
+			},
+			expect: expect{
+				status: http.StatusConflict,
+				body:   `{"status":"Conflict","error":"conflict"}`,
+			},
+			mocks: mocks{
+				transferManagerParams: []mockParams{
+					{
+						method:    "OpenTransferContent",
+						arguments: []any{mock.Anything, uuid.MustParse("6f0d3c1e-3b8a-4c55-9a57-2b4c4f0e8a10")},
+						returns:   []any{nil, types.TransferJob{}, types.ErrConflict},
+					},
+				},
+			},
+		},
+		{
+			name: "TestGetEventsWithoutUser",
+			request: request{
+				method: http.MethodGet,
+				path:   "/api/events",
+			},
+			expect: expect{
+				status: http.StatusUnauthorized,
+				body:   `{"status":"Invalid credentials","error":"invalid credentials: no verified identity"}`,
+			},
+		},
+		{
+			name: "TestGetProviderJWKS",
+			request: request{
+				method: http.MethodGet,
+				path:   "/api/providers/37737548-2926-4bd9-b2e6-48fa669e31aa/jwks",
+			},
+			expect: expect{
+				status: http.StatusOK,
+				body:   `{"keys":[{"use":"enc","kty":"RSA","kid":"k1","alg":"RSA-OAEP-256","n":"AQAB","e":"AQAB"}]}`,
+			},
+			mocks: mocks{
+				providerListerParams: []mockParams{
+					{
+						method:    "GetProviderKeys",
+						arguments: []any{mock.Anything, "37737548-2926-4bd9-b2e6-48fa669e31aa"},
+						returns: []any{
+							[]jose.JSONWebKey{{
+								Key:       &rsa.PublicKey{N: big.NewInt(65537), E: 65537},
+								KeyID:     "k1",
+								Algorithm: "RSA-OAEP-256",
+								Use:       "enc",
+							}},
+							nil,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "TestGetProviderJWKSWithoutKeys",
+			request: request{
+				method: http.MethodGet,
+				path:   "/api/providers/37737548-2926-4bd9-b2e6-48fa669e31aa/jwks",
+			},
+			expect: expect{
+				status: http.StatusOK,
+				body:   `{"keys":[]}`,
+			},
+			mocks: mocks{
+				providerListerParams: []mockParams{
+					{
+						method:    "GetProviderKeys",
+						arguments: []any{mock.Anything, "37737548-2926-4bd9-b2e6-48fa669e31aa"},
+						returns:   []any{[]jose.JSONWebKey(nil), nil},
+					},
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -97,7 +927,12 @@ func TestAPIRoutes(t *testing.T) {
 			ds := mtypes.NewMockDataspaceConnector(t)
 			sl := mtypes.NewMockStudyLister(t)
 			router := gin.New()
-			routes := api.New(pl, ds, sl)
+			am := mtypes.NewMockAccessManager(t)
+			sm := mtypes.NewMockShareManager(t)
+			cm := mtypes.NewMockConsentManager(t)
+			co := mtypes.NewMockContributionOrchestrator(t)
+			tm := mtypes.NewMockTransferManager(t)
+			routes := api.New(pl, ds, sl, am, sm, cm, co, tm, events.NewLocal())
 			routes.AddRoutes(router.Group("/api"))
 
 			for _, p := range tt.mocks.providerListerParams {
@@ -109,15 +944,76 @@ func TestAPIRoutes(t *testing.T) {
 			for _, p := range tt.mocks.studyListerParams {
 				sl.On(p.method, p.arguments...).Return(p.returns...)
 			}
+			for _, p := range tt.mocks.accessManagerParams {
+				am.On(p.method, p.arguments...).Return(p.returns...)
+			}
+			for _, p := range tt.mocks.shareManagerParams {
+				sm.On(p.method, p.arguments...).Return(p.returns...)
+			}
+			for _, p := range tt.mocks.consentManagerParams {
+				cm.On(p.method, p.arguments...).Return(p.returns...)
+			}
+			for _, p := range tt.mocks.orchestratorParams {
+				co.On(p.method, p.arguments...).Return(p.returns...)
+			}
+			for _, p := range tt.mocks.transferManagerParams {
+				tm.On(p.method, p.arguments...).Return(p.returns...)
+			}
 			body := bytes.NewReader(tt.request.body)
 
 			w := httptest.NewRecorder()
 			req, _ := http.NewRequest(tt.request.method, tt.request.path, body)
+			for k, v := range tt.request.headers {
+				req.Header.Set(k, v)
+			}
 			router.ServeHTTP(w, req)
 
 			assert.Equal(t, tt.expect.status, w.Code)
 			assert.Equal(t, tt.expect.body, w.Body.String())
 			pl.AssertExpectations(t)
+			am.AssertExpectations(t)
+			sm.AssertExpectations(t)
+			cm.AssertExpectations(t)
+			co.AssertExpectations(t)
+			tm.AssertExpectations(t)
+			ds.AssertExpectations(t)
 		})
 	}
 }
+
+func TestGetEvents(t *testing.T) {
+	bus := events.NewLocal()
+	routes := api.New(
+		mtypes.NewMockProviderLister(t),
+		mtypes.NewMockDataspaceConnector(t),
+		mtypes.NewMockStudyLister(t),
+		mtypes.NewMockAccessManager(t),
+		mtypes.NewMockShareManager(t),
+		mtypes.NewMockConsentManager(t),
+		mtypes.NewMockContributionOrchestrator(t),
+		mtypes.NewMockTransferManager(t),
+		bus,
+	)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(identity.Inject(c.Request.Context(), identity.Identity{Subject: "user-a"}))
+	})
+	routes.AddRoutes(router.Group("/api"))
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+
+	// The headers are sent before the first event.
+	client := &http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Get(srv.URL + "/api/events")
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	event, err := events.New(events.TypeTransferProgress, "user-a", map[string]string{"state": "completed"})
+	assert.NoError(t, err)
+	assert.NoError(t, bus.Publish(context.Background(), event))
+	line, err := bufio.NewReader(resp.Body).ReadString('\n')
+	assert.NoError(t, err)
+	assert.Equal(t, "event:transfer.progress\n", line)
+}
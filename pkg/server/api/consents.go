@@ -0,0 +1,160 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/logging"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/types"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// getConsents returns all consents of the user.
+func (r *Routes) getConsents(c *gin.Context) {
+	consents, err := r.cm.ListConsents(c.Request.Context())
+	if checkError(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, consents)
+}
+
+// getStudyConsents returns the consents of the user for the given study.
+func (r *Routes) getStudyConsents(c *gin.Context) {
+	i := c.Param("study_id")
+	studyID := parseID(c, i, "study")
+	if studyID == (uuid.UUID{}) {
+		return
+	}
+	consents, err := r.cm.ListStudyConsents(c.Request.Context(), studyID)
+	if checkError(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, consents)
+}
+
+// postStudyConsent records the consent of the user to share research data with the given study,
+// and starts contributing the matching files. The contributions of consents it supersedes are
+// cancelled. If the contribution can't be started, the consent is withdrawn again.
+func (r *Routes) postStudyConsent(c *gin.Context) {
+	logger := logging.Extract(c)
+	i := c.Param("study_id")
+	studyID := parseID(c, i, "study")
+	if studyID == (uuid.UUID{}) {
+		return
+	}
+	var consent types.ConsentRequest
+	if err := c.ShouldBindJSON(&consent); err != nil {
+		logger.Error("Could not parse request", "error", err)
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Status: "Could not parse request",
+			Error:  "The request could not be parsed",
+		})
+		return
+	}
+	if checkError(c, consent.Validate()) {
+		return
+	}
+	ctx := c.Request.Context()
+	study, err := r.sl.GetStudy(ctx, studyID)
+	if checkError(c, err) {
+		return
+	}
+	if checkError(c, consent.ValidateForStudy(study)) {
+		return
+	}
+	files, err := r.sl.ListStudyFiles(ctx, studyID)
+	if checkError(c, err) {
+		return
+	}
+	previous, err := r.cm.ListStudyConsents(ctx, studyID)
+	if checkError(c, err) {
+		return
+	}
+	// The contributions of the consents the new one supersedes are cancelled before they are
+	// withdrawn, so no contribution keeps running without an active consent.
+	for _, p := range previous {
+		if !p.Active() {
+			continue
+		}
+		if checkError(c, r.co.CancelContribution(ctx, p.ID)) {
+			return
+		}
+	}
+	resp, err := r.cm.SubmitConsent(ctx, studyID, consent)
+	if checkError(c, err) {
+		return
+	}
+	contribution, err := r.co.StartContribution(ctx, resp, files)
+	if err != nil {
+		r.rollbackConsent(c, resp)
+		checkError(c, err)
+		return
+	}
+	logger.Info("Started contribution", "consent_id", resp.ID, "files", len(contribution.Files))
+	c.JSON(http.StatusCreated, resp)
+}
+
+// rollbackConsent withdraws a consent whose contribution couldn't be started, so it doesn't look
+// like the files are being contributed.
+func (r *Routes) rollbackConsent(c *gin.Context, consent types.Consent) {
+	logger := logging.Extract(c)
+	if err := r.cm.WithdrawConsent(c.Request.Context(), consent.StudyID, consent.ID); err != nil {
+		logger.Error("Couldn't withdraw consent without contribution", "consent_id", consent.ID, "error", err)
+	}
+}
+
+// deleteStudyConsent withdraws the consent with the given ID.
+func (r *Routes) deleteStudyConsent(c *gin.Context) {
+	studyID := parseID(c, c.Param("study_id"), "study")
+	if studyID == (uuid.UUID{}) {
+		return
+	}
+	consentID := parseID(c, c.Param("consent_id"), "consent")
+	if consentID == (uuid.UUID{}) {
+		return
+	}
+	if checkError(c, r.cm.WithdrawConsent(c.Request.Context(), studyID, consentID)) {
+		return
+	}
+	if checkError(c, r.co.CancelContribution(c.Request.Context(), consentID)) {
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// getStudyContribution returns the transfer state of the files covered by the consent.
+func (r *Routes) getStudyContribution(c *gin.Context) {
+	studyID := parseID(c, c.Param("study_id"), "study")
+	if studyID == (uuid.UUID{}) {
+		return
+	}
+	consentID := parseID(c, c.Param("consent_id"), "consent")
+	if consentID == (uuid.UUID{}) {
+		return
+	}
+	contribution, err := r.co.GetContribution(c.Request.Context(), consentID)
+	if checkError(c, err) {
+		return
+	}
+	if contribution.StudyID != studyID {
+		checkError(c, fmt.Errorf("%w: contribution for consent %s not found", types.ErrNotFound, consentID))
+		return
+	}
+	c.JSON(http.StatusOK, contribution)
+}
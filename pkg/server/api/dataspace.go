@@ -16,25 +16,64 @@
 package api
 
 import (
-	"bytes"
+	"bufio"
 	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/logging"
 	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/types"
 	"github.com/gabriel-vasile/mimetype"
 	"github.com/gin-gonic/gin"
+	"github.com/go-jose/go-jose/v4"
 )
 
+// mimeSniffLength is the amount of bytes peeked at to detect the MIME type of a file.
+const mimeSniffLength = 3072
+
 // GetProviders returns all providers.
 func (r *Routes) getProviders(c *gin.Context) {
 	providers, err := r.pl.ListProviders(c.Request.Context())
 	if checkError(c, err) {
 		return
 	}
+	r.setSnapshotHeaders(c)
 	c.JSON(http.StatusOK, providers)
 }
 
+// setSnapshotHeaders tells the client how old the listed providers are, if they come from a
+// snapshot.
+func (r *Routes) setSnapshotHeaders(c *gin.Context) {
+	s, ok := r.pl.(types.ProviderSnapshotter)
+	if !ok {
+		return
+	}
+	updatedAt, err := s.SnapshotUpdatedAt(c.Request.Context())
+	if err != nil {
+		logging.Extract(c.Request.Context()).Error("Couldn't get provider snapshot time", "error", err)
+		return
+	}
+	if updatedAt.IsZero() {
+		return
+	}
+	c.Header("Last-Modified", updatedAt.UTC().Format(http.TimeFormat))
+	c.Header("X-Snapshot-Age", strconv.Itoa(int(time.Since(updatedAt).Seconds())))
+}
+
+// getProviderJWKS returns the public keys of the provider as JWK Set.
+func (r *Routes) getProviderJWKS(c *gin.Context) {
+	keys, err := r.pl.GetProviderKeys(c.Request.Context(), c.Param("provider_id"))
+	if checkError(c, err) {
+		return
+	}
+	if keys == nil {
+		keys = []jose.JSONWebKey{}
+	}
+	c.Header("Content-Type", "application/jwk-set+json")
+	c.JSON(http.StatusOK, jose.JSONWebKeySet{Keys: keys})
+}
+
 // getProviderFiles returns an index of all files the user has access to on a provider.
 func (r *Routes) getProviderFiles(c *gin.Context) {
 	provider := r.getProviderByID(c)
@@ -68,19 +107,46 @@ func (r *Routes) getProviderFile(c *gin.Context) {
 		return
 	}
 
-	fileContents, err := r.dc.GetProviderFile(c.Request.Context(), provider.ID, i)
+	fileRange := types.FileRange{
+		Range:   c.GetHeader("Range"),
+		IfRange: c.GetHeader("If-Range"),
+	}
+	fileContents, fileMeta, err := r.dc.GetProviderFile(c.Request.Context(), provider.ID, i, fileRange)
 	if checkError(c, err) {
 		return
 	}
+	defer fileContents.Close()
+
+	status := http.StatusOK
+	headers := map[string]string{"Content-Disposition": fmt.Sprintf("attachment; filename=\"%s\"", fileInfo.Name)}
+	if fileMeta.ContentRange != "" {
+		status = http.StatusPartialContent
+		headers["Content-Range"] = fileMeta.ContentRange
+	}
+	if fileMeta.AcceptRanges {
+		headers["Accept-Ranges"] = "bytes"
+	}
+	if fileMeta.ETag != "" {
+		headers["ETag"] = fileMeta.ETag
+	}
+	if fileMeta.LastModified != "" {
+		headers["Last-Modified"] = fileMeta.LastModified
+	}
+
+	reader := bufio.NewReaderSize(fileContents, mimeSniffLength)
 	mimeTypeData := fileInfo.MimeType
 	if mimeTypeData == "" {
-		mimeTypeData = mimetype.Detect(fileContents).String()
+		if status == http.StatusPartialContent {
+			// The start of the file is needed to detect its type.
+			mimeTypeData = "application/octet-stream"
+		} else {
+			// Peek returns an error for files shorter than the sniff length, the data is still usable.
+			head, _ := reader.Peek(mimeSniffLength)
+			mimeTypeData = mimetype.Detect(head).String()
+		}
 	}
 
-	c.DataFromReader(
-		http.StatusOK, int64(len(fileContents)), mimeTypeData, bytes.NewReader(fileContents),
-		map[string]string{"Content-Disposition": fmt.Sprintf("attachment; filename=\"%s\"", fileInfo.Name)},
-	)
+	c.DataFromReader(status, fileMeta.Size, mimeTypeData, reader, headers)
 }
 
 func (r *Routes) getDownloadCredentials(c *gin.Context) {
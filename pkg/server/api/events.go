@@ -0,0 +1,64 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/identity"
+	"github.com/gin-gonic/gin"
+)
+
+// keepaliveInterval is how often a comment is sent on an idle event stream, so proxies don't
+// close it.
+const keepaliveInterval = 30 * time.Second
+
+// getEvents streams the events of the backend to the client as server-sent events. The events
+// of a user are only streamed to the verified user.
+func (r *Routes) getEvents(c *gin.Context) {
+	ctx := c.Request.Context()
+	subject, err := identity.Subject(ctx)
+	if checkError(c, err) {
+		return
+	}
+	stream := r.eb.Subscribe(ctx, subject)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	// Send the headers right away, the first event can be a while.
+	c.Writer.Flush()
+	keepalive := time.NewTicker(keepaliveInterval)
+	defer keepalive.Stop()
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-keepalive.C:
+			_, err := io.WriteString(w, ": keepalive\n\n")
+			return err == nil
+		case event, ok := <-stream:
+			if !ok {
+				return false
+			}
+			c.SSEvent(string(event.Type), event.Data)
+			return true
+		}
+	})
+}
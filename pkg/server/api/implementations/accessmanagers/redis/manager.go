@@ -0,0 +1,129 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package amredis contains an access manager that stores the policies of every user in redis.
+package amredis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/identity"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/logging"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/types"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const storageKeyPrefix = "policies"
+
+var tracer trace.Tracer
+
+func init() {
+	tracer = otel.Tracer(
+		"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/implementations/accessmanagers/redis",
+	)
+}
+
+// AccessManager stores the policies of a user in a redis hash, keyed by the subject of the user.
+type AccessManager struct {
+	r *redis.Client
+}
+
+func New(redisClient *redis.Client) *AccessManager {
+	return &AccessManager{
+		r: redisClient,
+	}
+}
+
+// ListPolicies returns all policies of the user.
+func (am *AccessManager) ListPolicies(ctx context.Context) ([]types.Policy, error) {
+	logger := logging.Extract(ctx)
+	logger.Info("Listing policies")
+	ctx, span := tracer.Start(ctx, "RedisAccessManager.ListPolicies")
+	defer span.End()
+
+	key, err := storageKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	data, err := am.r.HVals(ctx, key).Result()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get policies: %w", err)
+	}
+	policies := make([]types.Policy, len(data))
+	for i, d := range data {
+		if err := json.Unmarshal([]byte(d), &policies[i]); err != nil {
+			return nil, fmt.Errorf("couldn't unmarshal policy: %w", err)
+		}
+	}
+	return policies, nil
+}
+
+// SubmitPolicy stores the policy for the user, a policy without ID gets a new one.
+func (am *AccessManager) SubmitPolicy(ctx context.Context, policy types.Policy) error {
+	logger := logging.Extract(ctx)
+	logger.Info("Submitting policy")
+	ctx, span := tracer.Start(ctx, "RedisAccessManager.SubmitPolicy")
+	defer span.End()
+
+	key, err := storageKey(ctx)
+	if err != nil {
+		return err
+	}
+	if policy.ID == uuid.Nil {
+		policy.ID = uuid.New()
+	}
+	data, err := json.Marshal(policy)
+	if err != nil {
+		return fmt.Errorf("couldn't marshal policy %s: %w", policy.ID, err)
+	}
+	if err := am.r.HSet(ctx, key, policy.ID.String(), data).Err(); err != nil {
+		return fmt.Errorf("couldn't save policy %s: %w", policy.ID, err)
+	}
+	return nil
+}
+
+// DeletePolicy deletes the policy with the given ID.
+func (am *AccessManager) DeletePolicy(ctx context.Context, policyID uuid.UUID) error {
+	logger := logging.Extract(ctx)
+	logger.Info("Deleting policy")
+	ctx, span := tracer.Start(ctx, "RedisAccessManager.DeletePolicy")
+	defer span.End()
+
+	key, err := storageKey(ctx)
+	if err != nil {
+		return err
+	}
+	deleted, err := am.r.HDel(ctx, key, policyID.String()).Result()
+	if err != nil {
+		return fmt.Errorf("couldn't delete policy %s: %w", policyID, err)
+	}
+	if deleted == 0 {
+		return fmt.Errorf("%w: policy %s not found", types.ErrNotFound, policyID)
+	}
+	return nil
+}
+
+func storageKey(ctx context.Context) (string, error) {
+	subject, err := identity.Subject(ctx)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s:%s", storageKeyPrefix, subject), nil
+}
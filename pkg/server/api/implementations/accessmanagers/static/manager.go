@@ -0,0 +1,116 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package static contains an in-memory access manager implementation, made for basic testing.
+package static
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/identity"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/logging"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/types"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer trace.Tracer
+
+func init() {
+	tracer = otel.Tracer(
+		"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/implementations/accessmanagers/static",
+	)
+}
+
+// AccessManager keeps the policies in memory, they are lost on restart.
+type AccessManager struct {
+	sync.Mutex
+	policies map[string]map[uuid.UUID]types.Policy
+}
+
+func New() *AccessManager {
+	return &AccessManager{
+		policies: make(map[string]map[uuid.UUID]types.Policy),
+	}
+}
+
+// ListPolicies returns all policies of the user.
+func (am *AccessManager) ListPolicies(ctx context.Context) ([]types.Policy, error) {
+	logger := logging.Extract(ctx)
+	logger.Info("Listing policies")
+	_, span := tracer.Start(ctx, "StaticAccessManager.ListPolicies")
+	defer span.End()
+
+	subject, err := identity.Subject(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	am.Lock()
+	defer am.Unlock()
+	policies := make([]types.Policy, 0, len(am.policies[subject]))
+	for _, p := range am.policies[subject] {
+		policies = append(policies, p)
+	}
+	return policies, nil
+}
+
+// SubmitPolicy stores the policy for the user, a policy without ID gets a new one.
+func (am *AccessManager) SubmitPolicy(ctx context.Context, policy types.Policy) error {
+	logger := logging.Extract(ctx)
+	logger.Info("Submitting policy")
+	_, span := tracer.Start(ctx, "StaticAccessManager.SubmitPolicy")
+	defer span.End()
+
+	subject, err := identity.Subject(ctx)
+	if err != nil {
+		return err
+	}
+	if policy.ID == uuid.Nil {
+		policy.ID = uuid.New()
+	}
+
+	am.Lock()
+	defer am.Unlock()
+	if am.policies[subject] == nil {
+		am.policies[subject] = make(map[uuid.UUID]types.Policy)
+	}
+	am.policies[subject][policy.ID] = policy
+	return nil
+}
+
+// DeletePolicy deletes the policy with the given ID.
+func (am *AccessManager) DeletePolicy(ctx context.Context, policyID uuid.UUID) error {
+	logger := logging.Extract(ctx)
+	logger.Info("Deleting policy")
+	_, span := tracer.Start(ctx, "StaticAccessManager.DeletePolicy")
+	defer span.End()
+
+	subject, err := identity.Subject(ctx)
+	if err != nil {
+		return err
+	}
+
+	am.Lock()
+	defer am.Unlock()
+	if _, ok := am.policies[subject][policyID]; !ok {
+		return fmt.Errorf("%w: policy %s not found", types.ErrNotFound, policyID)
+	}
+	delete(am.policies[subject], policyID)
+	return nil
+}
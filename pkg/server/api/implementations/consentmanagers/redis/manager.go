@@ -0,0 +1,218 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cmredis contains a consent manager that stores the consents of every user in redis.
+package cmredis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/identity"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/logging"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/types"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const storageKeyPrefix = "consents"
+
+var tracer trace.Tracer
+
+func init() {
+	tracer = otel.Tracer(
+		"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/implementations/consentmanagers/redis",
+	)
+}
+
+// ConsentManager stores the consents of a user in a redis hash, keyed by the subject of the user.
+// Consents are never deleted, withdrawing a consent only marks it as withdrawn.
+type ConsentManager struct {
+	r *redis.Client
+}
+
+func New(redisClient *redis.Client) *ConsentManager {
+	return &ConsentManager{
+		r: redisClient,
+	}
+}
+
+// ListConsents returns all consents of the user, oldest first.
+func (cm *ConsentManager) ListConsents(ctx context.Context) ([]types.Consent, error) {
+	logger := logging.Extract(ctx)
+	logger.Info("Listing consents")
+	ctx, span := tracer.Start(ctx, "RedisConsentManager.ListConsents")
+	defer span.End()
+
+	key, err := storageKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return loadConsents(ctx, cm.r, key)
+}
+
+// ListStudyConsents returns all consents of the user for the given study, oldest first.
+func (cm *ConsentManager) ListStudyConsents(ctx context.Context, studyID uuid.UUID) ([]types.Consent, error) {
+	logger := logging.Extract(ctx)
+	logger.Info("Listing study consents")
+	ctx, span := tracer.Start(ctx, "RedisConsentManager.ListStudyConsents")
+	defer span.End()
+
+	key, err := storageKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	consents, err := loadConsents(ctx, cm.r, key)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(consents, func(c types.Consent) bool {
+		return c.StudyID != studyID
+	}), nil
+}
+
+// SubmitConsent records the consent of the user for the study. An active consent the user gave
+// earlier for the same study is withdrawn, so there is at most one active consent per study.
+func (cm *ConsentManager) SubmitConsent(
+	ctx context.Context, studyID uuid.UUID, consent types.ConsentRequest,
+) (types.Consent, error) {
+	logger := logging.Extract(ctx)
+	logger.Info("Submitting consent")
+	ctx, span := tracer.Start(ctx, "RedisConsentManager.SubmitConsent")
+	defer span.End()
+
+	key, err := storageKey(ctx)
+	if err != nil {
+		return types.Consent{}, err
+	}
+
+	now := time.Now().UTC()
+	c := types.Consent{
+		ID:           uuid.New(),
+		StudyID:      studyID,
+		ResearchData: consent.Consented(),
+		GivenAt:      now,
+	}
+	err = cm.r.Watch(ctx, func(tx *redis.Tx) error {
+		consents, err := loadConsents(ctx, tx, key)
+		if err != nil {
+			return err
+		}
+		values := []any{}
+		for _, old := range consents {
+			if old.StudyID == studyID && old.Active() {
+				old.WithdrawnAt = &now
+				data, err := json.Marshal(old)
+				if err != nil {
+					return fmt.Errorf("couldn't marshal consent %s: %w", old.ID, err)
+				}
+				values = append(values, old.ID.String(), data)
+				logger.Info("Withdrawing superseded consent", "consent_id", old.ID)
+			}
+		}
+		data, err := json.Marshal(c)
+		if err != nil {
+			return fmt.Errorf("couldn't marshal consent %s: %w", c.ID, err)
+		}
+		values = append(values, c.ID.String(), data)
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			return pipe.HSet(ctx, key, values...).Err()
+		})
+		return err
+	}, key)
+	if err != nil {
+		return types.Consent{}, fmt.Errorf("couldn't save consent %s: %w", c.ID, err)
+	}
+	return c, nil
+}
+
+// WithdrawConsent marks the consent with the given ID as withdrawn. Withdrawing a consent that was
+// already withdrawn keeps the original withdrawal time.
+func (cm *ConsentManager) WithdrawConsent(ctx context.Context, studyID uuid.UUID, consentID uuid.UUID) error {
+	logger := logging.Extract(ctx)
+	logger.Info("Withdrawing consent")
+	ctx, span := tracer.Start(ctx, "RedisConsentManager.WithdrawConsent")
+	defer span.End()
+
+	key, err := storageKey(ctx)
+	if err != nil {
+		return err
+	}
+	err = cm.r.Watch(ctx, func(tx *redis.Tx) error {
+		data, err := tx.HGet(ctx, key, consentID.String()).Result()
+		if errors.Is(err, redis.Nil) {
+			return fmt.Errorf("%w: consent %s not found", types.ErrNotFound, consentID)
+		}
+		if err != nil {
+			return err
+		}
+		var c types.Consent
+		if err := json.Unmarshal([]byte(data), &c); err != nil {
+			return fmt.Errorf("couldn't unmarshal consent: %w", err)
+		}
+		if c.StudyID != studyID {
+			return fmt.Errorf("%w: consent %s not found", types.ErrNotFound, consentID)
+		}
+		if !c.Active() {
+			return nil
+		}
+		now := time.Now().UTC()
+		c.WithdrawnAt = &now
+		updated, err := json.Marshal(c)
+		if err != nil {
+			return fmt.Errorf("couldn't marshal consent %s: %w", c.ID, err)
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			return pipe.HSet(ctx, key, c.ID.String(), updated).Err()
+		})
+		return err
+	}, key)
+	if err != nil {
+		return fmt.Errorf("couldn't withdraw consent %s: %w", consentID, err)
+	}
+	return nil
+}
+
+// loadConsents returns the consents stored under the key, oldest first.
+func loadConsents(ctx context.Context, r redis.Cmdable, key string) ([]types.Consent, error) {
+	data, err := r.HVals(ctx, key).Result()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get consents: %w", err)
+	}
+	consents := make([]types.Consent, len(data))
+	for i, d := range data {
+		if err := json.Unmarshal([]byte(d), &consents[i]); err != nil {
+			return nil, fmt.Errorf("couldn't unmarshal consent: %w", err)
+		}
+	}
+	slices.SortFunc(consents, func(a, b types.Consent) int {
+		return a.GivenAt.Compare(b.GivenAt)
+	})
+	return consents, nil
+}
+
+func storageKey(ctx context.Context) (string, error) {
+	subject, err := identity.Subject(ctx)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s:%s", storageKeyPrefix, subject), nil
+}
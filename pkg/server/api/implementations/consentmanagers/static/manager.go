@@ -0,0 +1,154 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package static contains an in-memory consent manager implementation, made for basic testing.
+package static
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/identity"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/logging"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/types"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer trace.Tracer
+
+func init() {
+	tracer = otel.Tracer(
+		"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/implementations/consentmanagers/static",
+	)
+}
+
+// ConsentManager keeps the consents in memory, they are lost on restart. Like the redis consent
+// manager, withdrawing a consent only marks it as withdrawn.
+type ConsentManager struct {
+	sync.Mutex
+	// consents holds the consents of every user, oldest first.
+	consents map[string][]types.Consent
+}
+
+func New() *ConsentManager {
+	return &ConsentManager{
+		consents: make(map[string][]types.Consent),
+	}
+}
+
+// ListConsents returns all consents of the user, oldest first.
+func (cm *ConsentManager) ListConsents(ctx context.Context) ([]types.Consent, error) {
+	logger := logging.Extract(ctx)
+	logger.Info("Listing consents")
+	_, span := tracer.Start(ctx, "StaticConsentManager.ListConsents")
+	defer span.End()
+
+	subject, err := identity.Subject(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cm.Lock()
+	defer cm.Unlock()
+	return slices.Clone(cm.consents[subject]), nil
+}
+
+// ListStudyConsents returns all consents of the user for the given study, oldest first.
+func (cm *ConsentManager) ListStudyConsents(ctx context.Context, studyID uuid.UUID) ([]types.Consent, error) {
+	logger := logging.Extract(ctx)
+	logger.Info("Listing study consents")
+	_, span := tracer.Start(ctx, "StaticConsentManager.ListStudyConsents")
+	defer span.End()
+
+	subject, err := identity.Subject(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	cm.Lock()
+	defer cm.Unlock()
+	return slices.DeleteFunc(slices.Clone(cm.consents[subject]), func(c types.Consent) bool {
+		return c.StudyID != studyID
+	}), nil
+}
+
+// SubmitConsent records the consent of the user for the study. An active consent the user gave
+// earlier for the same study is withdrawn, so there is at most one active consent per study.
+func (cm *ConsentManager) SubmitConsent(
+	ctx context.Context, studyID uuid.UUID, consent types.ConsentRequest,
+) (types.Consent, error) {
+	logger := logging.Extract(ctx)
+	logger.Info("Submitting consent")
+	_, span := tracer.Start(ctx, "StaticConsentManager.SubmitConsent")
+	defer span.End()
+
+	subject, err := identity.Subject(ctx)
+	if err != nil {
+		return types.Consent{}, err
+	}
+
+	now := time.Now().UTC()
+	c := types.Consent{
+		ID:           uuid.New(),
+		StudyID:      studyID,
+		ResearchData: consent.Consented(),
+		GivenAt:      now,
+	}
+	cm.Lock()
+	defer cm.Unlock()
+	consents := cm.consents[subject]
+	for i, old := range consents {
+		if old.StudyID == studyID && old.Active() {
+			consents[i].WithdrawnAt = &now
+			logger.Info("Withdrawing superseded consent", "consent_id", old.ID)
+		}
+	}
+	cm.consents[subject] = append(consents, c)
+	return c, nil
+}
+
+// WithdrawConsent marks the consent with the given ID as withdrawn. Withdrawing a consent that was
+// already withdrawn keeps the original withdrawal time.
+func (cm *ConsentManager) WithdrawConsent(ctx context.Context, studyID uuid.UUID, consentID uuid.UUID) error {
+	logger := logging.Extract(ctx)
+	logger.Info("Withdrawing consent")
+	_, span := tracer.Start(ctx, "StaticConsentManager.WithdrawConsent")
+	defer span.End()
+
+	subject, err := identity.Subject(ctx)
+	if err != nil {
+		return err
+	}
+
+	cm.Lock()
+	defer cm.Unlock()
+	consents := cm.consents[subject]
+	i := slices.IndexFunc(consents, func(c types.Consent) bool {
+		return c.ID == consentID && c.StudyID == studyID
+	})
+	if i < 0 {
+		return fmt.Errorf("%w: consent %s not found", types.ErrNotFound, consentID)
+	}
+	if consents[i].Active() {
+		now := time.Now().UTC()
+		consents[i].WithdrawnAt = &now
+	}
+	return nil
+}
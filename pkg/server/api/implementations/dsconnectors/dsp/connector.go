@@ -17,8 +17,15 @@ package dspconnector
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
 
 	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/logging"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/middleware/authforwarder"
 	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/types"
 	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/transfer"
 	dspclient "github.com/go-dataspace/run-dsrpc/gen/go/dsp/v1alpha1"
@@ -37,14 +44,25 @@ func init() {
 }
 
 type DataspaceConnector struct {
-	dsp dspclient.ClientServiceClient
-	pl  types.ProviderLister
+	sync.Mutex
+	dsp                 dspclient.ClientServiceClient
+	pl                  types.ProviderLister
+	transfers           map[string]*openTransfer
+	transferIdleTimeout time.Duration
 }
 
-func New(client dspclient.ClientServiceClient, pl types.ProviderLister) *DataspaceConnector {
+// New creates a new DSP dataspace connector. Transfers of files that haven't been downloaded
+// completely are kept open for transferIdleTimeout, so that downloads can be resumed.
+func New(
+	client dspclient.ClientServiceClient,
+	pl types.ProviderLister,
+	transferIdleTimeout time.Duration,
+) *DataspaceConnector {
 	return &DataspaceConnector{
-		dsp: client,
-		pl:  pl,
+		dsp:                 client,
+		pl:                  pl,
+		transfers:           make(map[string]*openTransfer),
+		transferIdleTimeout: transferIdleTimeout,
 	}
 }
 
@@ -62,9 +80,10 @@ func (dc *DataspaceConnector) ListProviderFiles(
 	}
 	logger.Info("Listing files at provider", "provider", provider.Name)
 
-	catalogue, err := dc.dsp.GetProviderCatalogue(ctx, &dspclient.GetProviderCatalogueRequest{
-		ProviderUri: provider.ProviderUrl,
-	})
+	catalogue, err := dc.dsp.GetProviderCatalogue(
+		authforwarder.InjectProvider(ctx, provider.ID),
+		&dspclient.GetProviderCatalogueRequest{ProviderUri: provider.ProviderUrl},
+	)
 	if err != nil {
 		return nil, convertError(ctx, err)
 	}
@@ -109,37 +128,120 @@ func (dc *DataspaceConnector) GetProviderFileInfo(
 	return types.ProviderFile{}, types.ErrNotFound
 }
 
-// GetProviderFile returns file with the given ID hosted by the given provider.
-func (dc *DataspaceConnector) GetProviderFile(ctx context.Context, providerID string, fileID string,
-) ([]byte, error) {
+// GetProviderFile returns file with the given ID hosted by the given provider. The file is streamed
+// from the provider. Requested ranges are forwarded to the provider, and emulated if the provider
+// doesn't support them. The transfer is kept open until the file has been read completely, or the
+// transfer has been idle for too long, so that interrupted downloads can be resumed.
+func (dc *DataspaceConnector) GetProviderFile(
+	ctx context.Context, providerID string, fileID string, fileRange types.FileRange,
+) (io.ReadCloser, types.FileMetadata, error) {
 	logger := logging.Extract(ctx)
-	logger.Info("Downloading file")
+	logger.Info("Downloading file", "range", fileRange.Range)
 	ctx, span := tracer.Start(ctx, "dspconnector.DataspaceConnector.GetProviderFile")
 	defer span.End()
 
 	provider, err := dc.pl.GetProvider(ctx, providerID)
 	if err != nil {
-		return nil, convertError(ctx, err)
+		return nil, types.FileMetadata{}, convertError(ctx, err)
 	}
 
-	dlInfo, err := dc.dsp.GetProviderDatasetDownloadInformation(
-		ctx,
-		&dspclient.GetProviderDatasetDownloadInformationRequest{
-			ProviderUrl: provider.ProviderUrl,
-			DatasetId:   fileID,
-		},
-	)
+	t, err := dc.acquireTransfer(ctx, provider, fileID)
 	if err != nil {
-		logger.Error("Seems file for download could not be found", "error", err)
-		return nil, types.ErrNotFound
+		return nil, types.FileMetadata{}, err
 	}
-	defer dc.dsp.SignalTransferComplete(ctx, &dspclient.SignalTransferCompleteRequest{ //nolint:errcheck
-		TransferId: dlInfo.TransferId,
-	})
 
-	logger.Info("Got download information", "auth_type", dlInfo.PublishInfo.AuthenticationType)
+	header := http.Header{}
+	if fileRange.Range != "" {
+		header.Set("Range", fileRange.Range)
+		if fileRange.IfRange != "" {
+			header.Set("If-Range", fileRange.IfRange)
+		}
+	}
+	resp, err := transfer.StreamDSPFile(ctx, t.publishInfo, header)
+	if err != nil {
+		var statusErr transfer.StatusError
+		if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+			dc.releaseTransfer(t, false)
+			return nil, types.FileMetadata{}, types.ErrRangeNotSatisfiable
+		}
+		// The publish information might not be valid anymore, so don't reuse the transfer.
+		dc.releaseTransfer(t, true)
+		return nil, types.FileMetadata{}, err
+	}
 
-	return transfer.RetrieveDSPFile(ctx, dlInfo.PublishInfo)
+	reader, meta, coversEnd, err := selectRange(resp, fileRange)
+	if err != nil {
+		resp.Body.Close()
+		dc.releaseTransfer(t, false)
+		return nil, types.FileMetadata{}, err
+	}
+	return &transferReader{
+		Reader:    reader,
+		body:      resp.Body,
+		coversEnd: coversEnd,
+		release: func(completed bool) {
+			dc.releaseTransfer(t, completed)
+		},
+	}, meta, nil
+}
+
+// selectRange returns the requested part of the response body. If the provider returned the full
+// file even though a range was requested, the range is cut out of the full file. The returned
+// boolean tells if the content reaches until the end of the file.
+func selectRange(
+	resp *http.Response, fileRange types.FileRange,
+) (io.Reader, types.FileMetadata, bool, error) {
+	meta := types.FileMetadata{
+		Size:         resp.ContentLength,
+		AcceptRanges: resp.Header.Get("Accept-Ranges") == "bytes",
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+
+	if resp.StatusCode == http.StatusPartialContent {
+		meta.AcceptRanges = true
+		meta.ContentRange = resp.Header.Get("Content-Range")
+		br, size, err := transfer.ParseContentRange(meta.ContentRange)
+		if err != nil {
+			return nil, types.FileMetadata{}, false, fmt.Errorf("%w: %w", types.ErrBadGateway, err)
+		}
+		return resp.Body, meta, size >= 0 && br.End == size-1, nil
+	}
+
+	// We can cut ranges out of the full file ourselves, as long as we know its size.
+	if meta.Size >= 0 {
+		meta.AcceptRanges = true
+	}
+	if fileRange.Range == "" ||
+		!transfer.IfRangeMatches(fileRange.IfRange, meta.ETag, meta.LastModified) {
+		return resp.Body, meta, true, nil
+	}
+	br, ok, err := transfer.ParseRange(fileRange.Range, meta.Size)
+	if err != nil {
+		return nil, types.FileMetadata{}, false, fmt.Errorf("%w: %w", types.ErrRangeNotSatisfiable, err)
+	}
+	if !ok {
+		return resp.Body, meta, true, nil
+	}
+	if _, err := io.CopyN(io.Discard, resp.Body, br.Start); err != nil {
+		return nil, types.FileMetadata{}, false, fmt.Errorf("couldn't skip to start of range: %w", err)
+	}
+	fileSize := meta.Size
+	meta.ContentRange = br.ContentRange(fileSize)
+	meta.Size = br.Length()
+	return io.LimitReader(resp.Body, br.Length()), meta, br.End == fileSize-1, nil
+}
+
+// signalTransferComplete tells run-dsp that we're done with the transfer. This also has to happen
+// when the client aborted the download, so it doesn't use the cancellation of the request context.
+func (dc *DataspaceConnector) signalTransferComplete(ctx context.Context, transferID string) {
+	logger := logging.Extract(ctx)
+	_, err := dc.dsp.SignalTransferComplete(context.WithoutCancel(ctx), &dspclient.SignalTransferCompleteRequest{
+		TransferId: transferID,
+	})
+	if err != nil {
+		logger.Error("Couldn't signal transfer complete", "transfer_id", transferID, "error", err)
+	}
 }
 
 func (dc *DataspaceConnector) GetDownloadCredentials(
@@ -156,7 +258,7 @@ func (dc *DataspaceConnector) GetDownloadCredentials(
 	}
 
 	dlInfo, err := dc.dsp.GetProviderDatasetDownloadInformation(
-		ctx,
+		authforwarder.InjectProvider(ctx, provider.ID),
 		&dspclient.GetProviderDatasetDownloadInformationRequest{
 			ProviderUrl: provider.ProviderUrl,
 			DatasetId:   fileID,
@@ -184,7 +286,9 @@ func convertError(ctx context.Context, err error) error {
 			code := e.Code()
 			logger.Info("got status code", "code", code)
 			switch code { //nolint
-			case codes.Unavailable, codes.Unauthenticated, codes.PermissionDenied:
+			case codes.Unavailable:
+				return fmt.Errorf("%w: provider unavailable: %s", types.ErrBadGateway, e.Message())
+			case codes.Unauthenticated, codes.PermissionDenied:
 				return types.ErrInvalidCredentials
 			case codes.NotFound:
 				return types.ErrNotFound
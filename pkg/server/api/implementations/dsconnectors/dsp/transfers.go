@@ -0,0 +1,203 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dspconnector
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/identity"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/logging"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/middleware/authforwarder"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/types"
+	dspclient "github.com/go-dataspace/run-dsrpc/gen/go/dsp/v1alpha1"
+)
+
+// completeMargin is how long before the credentials of a transfer expire it is completed, so
+// run-dsp still accepts them.
+const completeMargin = 30 * time.Second
+
+// openTransfer is a negotiated DSP transfer. It is kept open after a download was interrupted, or
+// only a part of the file was downloaded, so that resumed downloads don't have to negotiate again.
+type openTransfer struct {
+	// key is empty for transfers without a verified user, they aren't shared.
+	key         string
+	transferID  string
+	publishInfo *dspclient.PublishInfo
+	readers     int
+	completed   bool
+	timer       *time.Timer
+	logger      *slog.Logger
+	// authorization is the latest authorization the transfer was acquired with, it is used to
+	// complete the transfer once no request is left. It expires at expiresAt, if known.
+	authorization string
+	expiresAt     time.Time
+}
+
+// acquireTransfer returns the open transfer of the user for the file, or negotiates a new one.
+// Every acquired transfer has to be released.
+func (dc *DataspaceConnector) acquireTransfer(
+	ctx context.Context, provider types.Provider, fileID string,
+) (*openTransfer, error) {
+	logger := logging.Extract(ctx)
+	key := transferKey(ctx, provider.ID, fileID)
+
+	dc.Lock()
+	if t, ok := dc.transfers[key]; ok && key != "" {
+		t.resume(ctx)
+		dc.Unlock()
+		logger.Info("Resuming open transfer", "transfer_id", t.transferID)
+		return t, nil
+	}
+	dc.Unlock()
+
+	dlInfo, err := dc.dsp.GetProviderDatasetDownloadInformation(
+		authforwarder.InjectProvider(ctx, provider.ID),
+		&dspclient.GetProviderDatasetDownloadInformationRequest{
+			ProviderUrl: provider.ProviderUrl,
+			DatasetId:   fileID,
+		},
+	)
+	if err != nil {
+		logger.Error("Seems file for download could not be found", "error", err)
+		return nil, types.ErrNotFound
+	}
+	logger.Info("Got download information", "auth_type", dlInfo.PublishInfo.AuthenticationType)
+
+	dc.Lock()
+	if t, ok := dc.transfers[key]; ok && key != "" {
+		// Another request negotiated the same transfer in the meantime, use that one instead.
+		t.resume(ctx)
+		dc.Unlock()
+		dc.signalTransferComplete(ctx, dlInfo.TransferId)
+		return t, nil
+	}
+	defer dc.Unlock()
+	t := &openTransfer{
+		key:         key,
+		transferID:  dlInfo.TransferId,
+		publishInfo: dlInfo.PublishInfo,
+		logger:      logger,
+	}
+	t.resume(ctx)
+	if key != "" {
+		dc.transfers[key] = t
+	}
+	return t, nil
+}
+
+// resume counts another reader of the transfer, and keeps the credentials of its request if they
+// are valid for longer. The connector has to be locked.
+func (t *openTransfer) resume(ctx context.Context) {
+	t.readers++
+	if t.timer != nil {
+		t.timer.Stop()
+	}
+	id, ok := identity.Extract(ctx)
+	if t.authorization == "" || (ok && id.ExpiresAt.After(t.expiresAt)) {
+		t.authorization = authforwarder.ExtractAuthorization(ctx)
+		t.expiresAt = id.ExpiresAt
+	}
+}
+
+// completeContext returns a context for completing the transfer, detached from the requests that
+// used it, with the latest credentials.
+func (t *openTransfer) completeContext() context.Context {
+	return authforwarder.InjectAuthorization(logging.Inject(context.Background(), t.logger), t.authorization)
+}
+
+// releaseTransfer releases an acquired transfer. Completed transfers are signalled as complete
+// once nobody is reading them anymore, others are kept open until they have been idle for the
+// configured timeout, or their credentials are about to expire.
+func (dc *DataspaceConnector) releaseTransfer(t *openTransfer, completed bool) {
+	dc.Lock()
+	t.readers--
+	t.completed = t.completed || completed
+	if t.readers > 0 {
+		dc.Unlock()
+		return
+	}
+	idle := dc.transferIdleTimeout
+	if !t.expiresAt.IsZero() {
+		idle = min(idle, time.Until(t.expiresAt)-completeMargin)
+	}
+	if !t.completed && t.key != "" && idle > 0 {
+		t.timer = time.AfterFunc(idle, func() {
+			dc.expireTransfer(t)
+		})
+		dc.Unlock()
+		return
+	}
+	if dc.transfers[t.key] == t {
+		delete(dc.transfers, t.key)
+	}
+	dc.Unlock()
+	dc.signalTransferComplete(t.completeContext(), t.transferID)
+}
+
+// expireTransfer closes a transfer that has been idle for too long.
+func (dc *DataspaceConnector) expireTransfer(t *openTransfer) {
+	dc.Lock()
+	if t.readers > 0 || dc.transfers[t.key] != t {
+		dc.Unlock()
+		return
+	}
+	delete(dc.transfers, t.key)
+	dc.Unlock()
+	t.logger.Info("Closing idle transfer", "transfer_id", t.transferID)
+	dc.signalTransferComplete(t.completeContext(), t.transferID)
+}
+
+// transferKey identifies the transfer of a file to a user, the user being identified by the
+// subject of their verified identity. Without one the key is empty.
+func transferKey(ctx context.Context, providerID string, fileID string) string {
+	subject, err := identity.Subject(ctx)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%s:%s:%s", subject, providerID, fileID)
+}
+
+// transferReader releases the transfer when the stream is closed. The transfer counts as completed
+// if the stream was read until the end of the file.
+type transferReader struct {
+	io.Reader
+	body       io.Closer
+	coversEnd  bool
+	reachedEOF bool
+	release    func(completed bool)
+	once       sync.Once
+}
+
+func (tr *transferReader) Read(p []byte) (int, error) {
+	n, err := tr.Reader.Read(p)
+	if err == io.EOF {
+		tr.reachedEOF = true
+	}
+	return n, err
+}
+
+func (tr *transferReader) Close() error {
+	err := tr.body.Close()
+	tr.once.Do(func() {
+		tr.release(tr.reachedEOF && tr.coversEnd)
+	})
+	return err
+}
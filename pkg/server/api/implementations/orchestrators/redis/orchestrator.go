@@ -0,0 +1,598 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package orchredis contains a contribution orchestrator that transfers the files a user consented
+// to share from their providers to the study, with a DSP transfer negotiated through run-dsp for
+// every file, and keeps the transfer state of every file in redis.
+package orchredis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/identity"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/logging"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/middleware/authforwarder"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/types"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/transfer"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/waitgroup"
+	dspclient "github.com/go-dataspace/run-dsrpc/gen/go/dsp/v1alpha1"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	storageKeyPrefix = "contributions"
+	// cancelChannel is the redis channel cancelled contributions are announced on, so that the
+	// replica running one stops it right away.
+	cancelChannel = "contributions:cancel"
+	// maxTxRetries limits how often a change of a contribution is retried when it was changed
+	// concurrently.
+	maxTxRetries = 10
+	// errorBodyLimit limits how much of an error response of the study is read.
+	errorBodyLimit = 4096
+)
+
+var tracer trace.Tracer
+
+// errCancelled stops a contribution that was cancelled, possibly on another replica.
+var errCancelled = errors.New("contribution was cancelled")
+
+func init() {
+	tracer = otel.Tracer(
+		"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/implementations/orchestrators/redis",
+	)
+}
+
+// permanentError is an error that retrying won't fix.
+type permanentError struct {
+	err error
+}
+
+func (pe *permanentError) Error() string { return pe.err.Error() }
+func (pe *permanentError) Unwrap() error { return pe.err }
+
+func permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// Orchestrator transfers the files of a consent one after the other, in the background. For every
+// file a DSP transfer is negotiated with its provider through run-dsp, and the file the provider
+// publishes is streamed to the contribution endpoint of the study. Failed transfers are retried
+// with an exponential backoff, up to maxAttempts times per file. Cancellations are stored in redis
+// and announced to all replicas, so a contribution can be cancelled on any of them.
+type Orchestrator struct {
+	sync.Mutex
+	// ctx is the context of the server, transfers are stopped when it is cancelled.
+	ctx             context.Context
+	r               *redis.Client
+	dsp             dspclient.ClientServiceClient
+	pl              types.ProviderLister
+	client          *http.Client
+	contributionURL string
+	maxAttempts     int
+	retryDelay      time.Duration
+	// running cancels the contributions running on this replica.
+	running map[uuid.UUID]context.CancelFunc
+}
+
+// New creates a new orchestrator, that receives cancellations until the context is done. Files are
+// posted to <contributionURL>/studies/<study_id>/files with the authorization of the user that
+// gave the consent.
+func New(
+	ctx context.Context,
+	redisClient *redis.Client,
+	client dspclient.ClientServiceClient,
+	pl types.ProviderLister,
+	contributionURL string,
+	maxAttempts int,
+	retryDelay time.Duration,
+) *Orchestrator {
+	o := &Orchestrator{
+		ctx: ctx,
+		r:   redisClient,
+		dsp: client,
+		pl:  pl,
+		client: &http.Client{
+			Transport: authforwarder.AuthRoundTripper{Proxied: http.DefaultTransport},
+		},
+		contributionURL: contributionURL,
+		maxAttempts:     max(maxAttempts, 1),
+		retryDelay:      retryDelay,
+		running:         make(map[uuid.UUID]context.CancelFunc),
+	}
+	wg := waitgroup.Extract(ctx)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		o.receiveCancellations(ctx)
+	}()
+	return o
+}
+
+// StartContribution records the files covered by the consent as pending, and starts transferring
+// them. A file is covered when one of its study matches is research data the user consented to
+// share, other files are left out. Files are transferred as their provider publishes them, so
+// files the user only consented to share anonymized or pseudonymized are recorded as failed.
+func (o *Orchestrator) StartContribution(
+	ctx context.Context, consent types.Consent, files []types.ProviderFile,
+) (types.Contribution, error) {
+	logger := logging.Extract(ctx).With("consent_id", consent.ID)
+	logger.Info("Starting contribution")
+	ctx, span := tracer.Start(ctx, "RedisOrchestrator.StartContribution")
+	defer span.End()
+
+	key, err := storageKey(ctx)
+	if err != nil {
+		return types.Contribution{}, err
+	}
+
+	now := time.Now().UTC()
+	c := types.Contribution{
+		ConsentID: consent.ID,
+		StudyID:   consent.StudyID,
+		CreatedAt: now,
+		Files:     make([]types.FileContribution, 0, len(files)),
+	}
+	for _, f := range files {
+		data, ok := consent.Covers(f)
+		if !ok {
+			continue
+		}
+		fc := types.FileContribution{
+			ProviderID:   f.Provider.ID,
+			FileID:       f.ID,
+			Name:         f.Name,
+			ResearchData: data.Name,
+			AccessType:   data.AccessType,
+			State:        types.ContributionStatePending,
+			UpdatedAt:    now,
+		}
+		if data.AccessType != types.AccessTypeFull {
+			fc.State = types.ContributionStateFailed
+			fc.Error = fmt.Sprintf(
+				"the provider publishes the full file, which %s access doesn't allow to share", data.AccessType,
+			)
+		}
+		c.Files = append(c.Files, fc)
+	}
+	if err := o.save(ctx, key, c); err != nil {
+		return types.Contribution{}, err
+	}
+	logger.Info("Contributing files", "count", len(c.Files))
+
+	// The transfer outlives the request, but still has to be done on behalf of the user, with
+	// their token. The identity tells until when the token can be used.
+	workCtx := authforwarder.InjectAuthorization(
+		logging.Inject(o.ctx, logger), authforwarder.ExtractAuthorization(ctx),
+	)
+	if id, ok := identity.Extract(ctx); ok {
+		workCtx = identity.Inject(workCtx, id)
+	}
+	workCtx, cancel := context.WithCancel(workCtx)
+	o.Lock()
+	o.running[consent.ID] = cancel
+	o.Unlock()
+
+	wg := waitgroup.Extract(o.ctx)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer o.forget(consent.ID)
+		o.run(workCtx, key, c)
+	}()
+	return c, nil
+}
+
+// GetContribution returns the current state of the contribution of the consent.
+func (o *Orchestrator) GetContribution(ctx context.Context, consentID uuid.UUID) (types.Contribution, error) {
+	logger := logging.Extract(ctx)
+	logger.Info("Getting contribution", "consent_id", consentID)
+	ctx, span := tracer.Start(ctx, "RedisOrchestrator.GetContribution")
+	defer span.End()
+
+	key, err := storageKey(ctx)
+	if err != nil {
+		return types.Contribution{}, err
+	}
+	return o.load(ctx, o.r, key, consentID)
+}
+
+// CancelContribution marks the files of the consent that haven't been transferred yet as
+// cancelled, and announces the cancellation so that the replica running the contribution stops
+// it.
+func (o *Orchestrator) CancelContribution(ctx context.Context, consentID uuid.UUID) error {
+	logger := logging.Extract(ctx)
+	logger.Info("Cancelling contribution", "consent_id", consentID)
+	ctx, span := tracer.Start(ctx, "RedisOrchestrator.CancelContribution")
+	defer span.End()
+
+	key, err := storageKey(ctx)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	err = o.modify(ctx, key, consentID, func(c *types.Contribution) bool {
+		return cancelUnfinished(c, now)
+	})
+	if errors.Is(err, types.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := o.r.Publish(ctx, cancelChannel, consentID.String()).Err(); err != nil {
+		return fmt.Errorf("couldn't announce cancellation of contribution %s: %w", consentID, err)
+	}
+	return nil
+}
+
+// receiveCancellations stops the contributions running on this replica when their cancellation
+// is announced.
+func (o *Orchestrator) receiveCancellations(ctx context.Context) {
+	logger := logging.Extract(ctx).With("channel", cancelChannel)
+	pubsub := o.r.Subscribe(ctx, cancelChannel)
+	defer pubsub.Close()
+	// The channel of the subscription reconnects by itself when the connection is lost.
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			consentID, err := uuid.Parse(msg.Payload)
+			if err != nil {
+				logger.Error("Couldn't parse cancelled contribution", "payload", msg.Payload, "error", err)
+				continue
+			}
+			o.Lock()
+			if cancel, ok := o.running[consentID]; ok {
+				logger.Info("Stopping cancelled contribution", "consent_id", consentID)
+				cancel()
+			}
+			o.Unlock()
+		}
+	}
+}
+
+func (o *Orchestrator) forget(consentID uuid.UUID) {
+	o.Lock()
+	defer o.Unlock()
+	if cancel, ok := o.running[consentID]; ok {
+		cancel()
+		delete(o.running, consentID)
+	}
+}
+
+// run transfers the files of the contribution, and records the state of each of them.
+func (o *Orchestrator) run(ctx context.Context, key string, c types.Contribution) {
+	logger := logging.Extract(ctx)
+	stopped := false
+	for i := range c.Files {
+		if c.Files[i].State.Final() {
+			continue
+		}
+		if !o.transferFile(ctx, key, c, i) {
+			stopped = true
+			break
+		}
+	}
+	if !stopped {
+		logger.Info("Contribution finished")
+		return
+	}
+	logger.Info("Contribution stopped")
+	// A contribution that is stopped as the server shuts down isn't marked as cancelled yet.
+	now := time.Now().UTC()
+	err := o.modify(context.WithoutCancel(ctx), key, c.ConsentID, func(c *types.Contribution) bool {
+		return cancelUnfinished(c, now)
+	})
+	if err != nil {
+		logger.Error("Couldn't save cancelled contribution", "error", err)
+	}
+}
+
+// transferFile transfers the file with the given index, and retries if that fails. It returns
+// false if the contribution was stopped. The file is transferred with the token of the user, so
+// it isn't retried once the token expired, and fails instead.
+func (o *Orchestrator) transferFile(ctx context.Context, key string, c types.Contribution, i int) bool {
+	f := c.Files[i]
+	logger := logging.Extract(ctx).With("provider_id", f.ProviderID, "file_id", f.FileID)
+	ctx = logging.Inject(ctx, logger)
+	for {
+		if tokenExpired(ctx, time.Now()) {
+			logger.Error("Couldn't contribute file, the token of the user expired", "attempts", f.Attempts)
+			f.State = types.ContributionStateFailed
+			f.Error = "the token of the user expired before the file was contributed"
+			return o.update(ctx, key, c.ConsentID, i, &f)
+		}
+		f.Attempts++
+		f.State = types.ContributionStateTransferring
+		if !o.update(ctx, key, c.ConsentID, i, &f) {
+			return false
+		}
+		err := o.contributeFile(ctx, key, c, i, &f)
+		if err == nil {
+			logger.Info("File contributed", "attempts", f.Attempts)
+			f.State = types.ContributionStateCompleted
+			f.Error = ""
+			return o.update(ctx, key, c.ConsentID, i, &f)
+		}
+		if errors.Is(err, errCancelled) || ctx.Err() != nil {
+			return false
+		}
+		var pe *permanentError
+		if errors.As(err, &pe) || f.Attempts >= o.maxAttempts {
+			logger.Error("Couldn't contribute file", "attempts", f.Attempts, "error", err)
+			f.State = types.ContributionStateFailed
+			f.Error = err.Error()
+			return o.update(ctx, key, c.ConsentID, i, &f)
+		}
+		delay := o.retryDelay << (f.Attempts - 1)
+		if tokenExpired(ctx, time.Now().Add(delay)) {
+			logger.Error("Couldn't contribute file, the token of the user expires before the next attempt",
+				"attempts", f.Attempts, "error", err)
+			f.State = types.ContributionStateFailed
+			f.Error = fmt.Sprintf("%s, and the token of the user expires before the next attempt", err)
+			return o.update(ctx, key, c.ConsentID, i, &f)
+		}
+		logger.Warn("Couldn't contribute file, retrying", "attempts", f.Attempts, "delay", delay, "error", err)
+		f.Error = err.Error()
+		if !o.update(ctx, key, c.ConsentID, i, &f) {
+			return false
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(delay):
+		}
+	}
+}
+
+// contributeFile negotiates a DSP transfer of the file with its provider through run-dsp, and
+// streams the file the provider publishes to the contribution endpoint of the study.
+func (o *Orchestrator) contributeFile(
+	ctx context.Context, key string, c types.Contribution, i int, f *types.FileContribution,
+) error {
+	if o.contributionURL == "" {
+		return permanent(errors.New("no contribution endpoint configured"))
+	}
+	provider, err := o.pl.GetProvider(ctx, f.ProviderID)
+	if err != nil {
+		if errors.Is(err, types.ErrNotFound) {
+			return permanent(fmt.Errorf("couldn't get provider: %w", err))
+		}
+		return fmt.Errorf("couldn't get provider: %w", err)
+	}
+
+	dlInfo, err := o.dsp.GetProviderDatasetDownloadInformation(
+		authforwarder.InjectProvider(ctx, provider.ID),
+		&dspclient.GetProviderDatasetDownloadInformationRequest{
+			ProviderUrl: provider.ProviderUrl,
+			DatasetId:   f.FileID,
+		},
+	)
+	if err != nil {
+		code := status.Code(err)
+		err = fmt.Errorf("couldn't negotiate transfer with provider: %w", err)
+		if code == codes.NotFound || code == codes.PermissionDenied ||
+			code == codes.Unauthenticated || code == codes.InvalidArgument {
+			return permanent(err)
+		}
+		return err
+	}
+	// run-dsp has to be told that we're done with the transfer, whether it worked or not.
+	defer o.signalTransferComplete(ctx, dlInfo.TransferId)
+	if dlInfo.PublishInfo == nil {
+		return errors.New("provider published no file")
+	}
+	f.TransferID = dlInfo.TransferId
+	if !o.update(ctx, key, c.ConsentID, i, f) {
+		return errCancelled
+	}
+
+	resp, err := transfer.StreamDSPFile(ctx, dlInfo.PublishInfo, nil)
+	if err != nil {
+		err = fmt.Errorf("couldn't get file from provider: %w", err)
+		var statusErr transfer.StatusError
+		if errors.As(err, &statusErr) && permanentStatus(statusErr.StatusCode) {
+			return permanent(err)
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	return o.upload(ctx, c, *f, resp.Body, resp.ContentLength)
+}
+
+// upload posts the content of the file to the contribution endpoint of the study.
+func (o *Orchestrator) upload(
+	ctx context.Context, c types.Contribution, f types.FileContribution, body io.Reader, size int64,
+) error {
+	u := fmt.Sprintf("%s/studies/%s/files", o.contributionURL, c.StudyID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, body)
+	if err != nil {
+		return permanent(fmt.Errorf("couldn't create contribution request: %w", err))
+	}
+	if size >= 0 {
+		req.ContentLength = size
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": f.Name}))
+	req.Header.Set("X-Consent-ID", c.ConsentID.String())
+	req.Header.Set("X-Provider-ID", f.ProviderID)
+	req.Header.Set("X-File-ID", f.FileID)
+	req.Header.Set("X-Transfer-ID", f.TransferID)
+	req.Header.Set("X-Research-Data", f.ResearchData)
+	req.Header.Set("X-Access-Type", strconv.Itoa(int(f.AccessType)))
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("couldn't push file to study: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, errorBodyLimit))
+	err = fmt.Errorf("study returned status code %d: %s", resp.StatusCode, msg)
+	if permanentStatus(resp.StatusCode) {
+		return permanent(err)
+	}
+	return err
+}
+
+// signalTransferComplete tells run-dsp that we're done with the transfer. This also has to happen
+// when the contribution was cancelled, so it doesn't use the cancellation of the context.
+func (o *Orchestrator) signalTransferComplete(ctx context.Context, transferID string) {
+	_, err := o.dsp.SignalTransferComplete(context.WithoutCancel(ctx), &dspclient.SignalTransferCompleteRequest{
+		TransferId: transferID,
+	})
+	if err != nil {
+		logging.Extract(ctx).Error("Couldn't signal transfer complete", "transfer_id", transferID, "error", err)
+	}
+}
+
+// update saves the state of the file with the given index, unless the contribution was cancelled
+// in the meantime, it returns if it did. The state is saved even if the context was cancelled, so
+// shutting down records the state.
+func (o *Orchestrator) update(
+	ctx context.Context, key string, consentID uuid.UUID, i int, f *types.FileContribution,
+) bool {
+	f.UpdatedAt = time.Now().UTC()
+	cancelled := false
+	err := o.modify(context.WithoutCancel(ctx), key, consentID, func(c *types.Contribution) bool {
+		if i >= len(c.Files) {
+			return false
+		}
+		if c.Files[i].State == types.ContributionStateCancelled {
+			cancelled = true
+			return false
+		}
+		c.Files[i] = *f
+		return true
+	})
+	if err != nil {
+		logging.Extract(ctx).Error("Couldn't save contribution state", "error", err)
+	}
+	return !cancelled
+}
+
+// modify changes the stored contribution in a transaction, that is retried if the contribution
+// was changed concurrently. The contribution is only saved if change returns true.
+func (o *Orchestrator) modify(
+	ctx context.Context, key string, consentID uuid.UUID, change func(c *types.Contribution) bool,
+) error {
+	for range maxTxRetries {
+		err := o.r.Watch(ctx, func(tx *redis.Tx) error {
+			c, err := o.load(ctx, tx, key, consentID)
+			if err != nil {
+				return err
+			}
+			if !change(&c) {
+				return nil
+			}
+			data, err := json.Marshal(c)
+			if err != nil {
+				return fmt.Errorf("couldn't marshal contribution %s: %w", consentID, err)
+			}
+			_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+				return pipe.HSet(ctx, key, consentID.String(), data).Err()
+			})
+			return err
+		}, key)
+		if !errors.Is(err, redis.TxFailedErr) {
+			return err
+		}
+	}
+	return fmt.Errorf("couldn't save contribution %s: %w", consentID, redis.TxFailedErr)
+}
+
+func (o *Orchestrator) save(ctx context.Context, key string, c types.Contribution) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return fmt.Errorf("couldn't marshal contribution %s: %w", c.ConsentID, err)
+	}
+	if err := o.r.HSet(ctx, key, c.ConsentID.String(), data).Err(); err != nil {
+		return fmt.Errorf("couldn't save contribution %s: %w", c.ConsentID, err)
+	}
+	return nil
+}
+
+func (o *Orchestrator) load(
+	ctx context.Context, r redis.Cmdable, key string, consentID uuid.UUID,
+) (types.Contribution, error) {
+	data, err := r.HGet(ctx, key, consentID.String()).Result()
+	if errors.Is(err, redis.Nil) {
+		return types.Contribution{}, fmt.Errorf("%w: contribution %s not found", types.ErrNotFound, consentID)
+	}
+	if err != nil {
+		return types.Contribution{}, fmt.Errorf("couldn't get contribution %s: %w", consentID, err)
+	}
+	var c types.Contribution
+	if err := json.Unmarshal([]byte(data), &c); err != nil {
+		return types.Contribution{}, fmt.Errorf("couldn't unmarshal contribution: %w", err)
+	}
+	return c, nil
+}
+
+// cancelUnfinished marks all files that haven't been transferred as cancelled, it returns if any
+// file was changed.
+func cancelUnfinished(c *types.Contribution, now time.Time) bool {
+	changed := false
+	for i, f := range c.Files {
+		if !f.State.Final() {
+			c.Files[i].State = types.ContributionStateCancelled
+			c.Files[i].UpdatedAt = now
+			changed = true
+		}
+	}
+	return changed
+}
+
+// tokenExpired tells if the token of the user the contribution is made for has expired at the
+// given time.
+func tokenExpired(ctx context.Context, at time.Time) bool {
+	id, ok := identity.Extract(ctx)
+	return ok && !id.ExpiresAt.IsZero() && !at.Before(id.ExpiresAt)
+}
+
+// permanentStatus tells if a request that failed with the HTTP status code won't succeed when
+// retried.
+func permanentStatus(code int) bool {
+	return code >= 400 && code < 500 && code != http.StatusRequestTimeout && code != http.StatusTooManyRequests
+}
+
+func storageKey(ctx context.Context) (string, error) {
+	subject, err := identity.Subject(ctx)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s:%s", storageKeyPrefix, subject), nil
+}
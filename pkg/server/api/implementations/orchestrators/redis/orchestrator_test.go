@@ -0,0 +1,449 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package orchredis_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	mtypes "github.com/HEALTH-X-dataLOFT/cma-backend/mocks/github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/types"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/identity"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/middleware/authforwarder"
+	orchredis "github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/implementations/orchestrators/redis"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/types"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/waitgroup"
+	"github.com/alecthomas/assert/v2"
+	"github.com/alicebob/miniredis/v2"
+	dspclient "github.com/go-dataspace/run-dsrpc/gen/go/dsp/v1alpha1"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var (
+	consentID = uuid.MustParse("0b7f5a0e-4d6c-4a0b-9c36-1f8e2a7d9e11")
+	studyID   = uuid.MustParse("d4ae2ca4-4bc5-4c2a-8cf5-0d9d5d8e3f1a")
+	provider  = types.Provider{ID: "provider-a", ProviderUrl: "https://provider-a.example.com/dsp"}
+)
+
+// fakeDSP negotiates transfers of the files published by a test server, like run-dsp would.
+type fakeDSP struct {
+	dspclient.ClientServiceClient
+	sync.Mutex
+	publishURL string
+	// negotiateErr fails the negotiation of transfers.
+	negotiateErr error
+	providers    []string
+	negotiated   int
+	completed    []string
+}
+
+func (d *fakeDSP) GetProviderDatasetDownloadInformation(
+	ctx context.Context, in *dspclient.GetProviderDatasetDownloadInformationRequest, _ ...grpc.CallOption,
+) (*dspclient.GetProviderDatasetDownloadInformationResponse, error) {
+	d.Lock()
+	defer d.Unlock()
+	d.providers = append(d.providers, authforwarder.ExtractProvider(ctx))
+	if d.negotiateErr != nil {
+		return nil, d.negotiateErr
+	}
+	d.negotiated++
+	return &dspclient.GetProviderDatasetDownloadInformationResponse{
+		TransferId: "transfer-" + in.DatasetId,
+		PublishInfo: &dspclient.PublishInfo{
+			Url:                d.publishURL + "/" + in.DatasetId,
+			AuthenticationType: dspclient.AuthenticationType_AUTHENTICATION_TYPE_BEARER,
+			Password:           "publish-token",
+		},
+	}, nil
+}
+
+func (d *fakeDSP) SignalTransferComplete(
+	_ context.Context, in *dspclient.SignalTransferCompleteRequest, _ ...grpc.CallOption,
+) (*dspclient.SignalTransferCompleteResponse, error) {
+	d.Lock()
+	defer d.Unlock()
+	d.completed = append(d.completed, in.TransferId)
+	return &dspclient.SignalTransferCompleteResponse{}, nil
+}
+
+func (d *fakeDSP) negotiations() int {
+	d.Lock()
+	defer d.Unlock()
+	return d.negotiated
+}
+
+func (d *fakeDSP) completedTransfers() []string {
+	d.Lock()
+	defer d.Unlock()
+	return append([]string(nil), d.completed...)
+}
+
+type env struct {
+	ctx context.Context
+	dsp *fakeDSP
+	pl  *mtypes.MockProviderLister
+	// published serves the files of the provider, study receives the contributed files.
+	published *httptest.Server
+	study     *httptest.Server
+	// newOrchestrator creates an orchestrator sharing the redis server, like another replica.
+	newOrchestrator func(retryDelay time.Duration) *orchredis.Orchestrator
+}
+
+func setup(t *testing.T, published, study http.HandlerFunc) env {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	pl := mtypes.NewMockProviderLister(t)
+	pl.On("GetProvider", mock.Anything, provider.ID).Return(provider, nil).Maybe()
+	publishedSrv := httptest.NewServer(published)
+	t.Cleanup(publishedSrv.Close)
+	studySrv := httptest.NewServer(study)
+	t.Cleanup(studySrv.Close)
+	dsp := &fakeDSP{publishURL: publishedSrv.URL}
+
+	wg := &sync.WaitGroup{}
+	serverCtx, cancel := context.WithCancel(waitgroup.Inject(context.Background(), wg))
+	t.Cleanup(func() {
+		cancel()
+		wg.Wait()
+	})
+	ctx := identity.Inject(context.Background(), identity.Identity{Subject: "user-a"})
+	return env{
+		ctx:       authforwarder.InjectAuthorization(ctx, "Bearer user-token"),
+		dsp:       dsp,
+		pl:        pl,
+		published: publishedSrv,
+		study:     studySrv,
+		newOrchestrator: func(retryDelay time.Duration) *orchredis.Orchestrator {
+			r := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+			t.Cleanup(func() { r.Close() })
+			return orchredis.New(serverCtx, r, dsp, pl, studySrv.URL, 3, retryDelay)
+		},
+	}
+}
+
+func consent(accessType types.AccessType) types.Consent {
+	return types.Consent{
+		ID:           consentID,
+		StudyID:      studyID,
+		ResearchData: []types.ConsentedData{{Name: "Heart rate", AccessType: accessType}},
+	}
+}
+
+var files = []types.ProviderFile{
+	{
+		ID:       "heart-rate",
+		Name:     "heart-rate.json",
+		Provider: provider,
+		Matches:  []types.StudyFileMatch{{ResearchData: "Heart rate"}},
+	},
+	{
+		ID:       "steps",
+		Name:     "steps.json",
+		Provider: provider,
+		Matches:  []types.StudyFileMatch{{ResearchData: "Steps"}},
+	},
+}
+
+func eventually(t *testing.T, cond func() bool, msg string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal(msg)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// waitForState waits until the first file of the contribution is in the given state.
+func waitForState(
+	t *testing.T, ctx context.Context, o *orchredis.Orchestrator, state types.ContributionState,
+) types.FileContribution {
+	t.Helper()
+	var f types.FileContribution
+	eventually(t, func() bool {
+		c, err := o.GetContribution(ctx, consentID)
+		assert.NoError(t, err)
+		f = c.Files[0]
+		return f.State == state
+	}, "file didn't reach state "+string(state))
+	return f
+}
+
+func servePublished(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("Authorization") != "Bearer publish-token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	_, _ = io.WriteString(w, `{"heart_rate":[72,75]}`)
+}
+
+func TestContribute(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		received *http.Request
+		body     string
+	)
+	e := setup(t, servePublished, func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		defer mu.Unlock()
+		received, body = r, string(data)
+		w.WriteHeader(http.StatusCreated)
+	})
+	o := e.newOrchestrator(time.Millisecond)
+
+	c, err := o.StartContribution(e.ctx, consent(types.AccessTypeFull), files)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(c.Files))
+	assert.Equal(t, types.ContributionStatePending, c.Files[0].State)
+
+	f := waitForState(t, e.ctx, o, types.ContributionStateCompleted)
+	assert.Equal(t, 1, f.Attempts)
+	assert.Equal(t, "transfer-heart-rate", f.TransferID)
+	eventually(t, func() bool { return len(e.dsp.completedTransfers()) == 1 }, "transfer wasn't completed")
+	assert.Equal(t, []string{"transfer-heart-rate"}, e.dsp.completedTransfers())
+	e.dsp.Lock()
+	assert.Equal(t, []string{provider.ID}, e.dsp.providers)
+	e.dsp.Unlock()
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, `{"heart_rate":[72,75]}`, body)
+	assert.Equal(t, "/studies/"+studyID.String()+"/files", received.URL.Path)
+	assert.Equal(t, "Bearer user-token", received.Header.Get("Authorization"))
+	assert.Equal(t, consentID.String(), received.Header.Get("X-Consent-ID"))
+	assert.Equal(t, "transfer-heart-rate", received.Header.Get("X-Transfer-ID"))
+	assert.Equal(t, "0", received.Header.Get("X-Access-Type"))
+}
+
+func TestContributeOnlyFullAccess(t *testing.T) {
+	for _, accessType := range []types.AccessType{types.AccessTypeAnonymized, types.AccessTypePseudonymized} {
+		t.Run(accessType.String(), func(t *testing.T) {
+			e := setup(t, servePublished, func(w http.ResponseWriter, _ *http.Request) {
+				t.Error("file was contributed")
+				w.WriteHeader(http.StatusCreated)
+			})
+			o := e.newOrchestrator(time.Millisecond)
+
+			c, err := o.StartContribution(e.ctx, consent(accessType), files)
+			assert.NoError(t, err)
+			assert.Equal(t, types.ContributionStateFailed, c.Files[0].State)
+			assert.Contains(t, c.Files[0].Error, accessType.String())
+
+			// Give the contribution time to run, it mustn't negotiate any transfer.
+			time.Sleep(50 * time.Millisecond)
+			stored, err := o.GetContribution(e.ctx, consentID)
+			assert.NoError(t, err)
+			assert.Equal(t, types.ContributionStateFailed, stored.Files[0].State)
+			assert.Equal(t, 0, e.dsp.negotiations())
+		})
+	}
+}
+
+func TestContributeRetries(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests int
+	)
+	e := setup(t, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		first := requests == 1
+		mu.Unlock()
+		if first {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		servePublished(w, r)
+	}, func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+	o := e.newOrchestrator(time.Millisecond)
+
+	_, err := o.StartContribution(e.ctx, consent(types.AccessTypeFull), files)
+	assert.NoError(t, err)
+	f := waitForState(t, e.ctx, o, types.ContributionStateCompleted)
+	assert.Equal(t, 2, f.Attempts)
+	assert.Equal(t, "", f.Error)
+	// Every attempt is its own DSP transfer, and all of them are completed.
+	eventually(t, func() bool { return len(e.dsp.completedTransfers()) == 2 }, "transfers weren't completed")
+}
+
+func TestContributeFails(t *testing.T) {
+	tests := []struct {
+		name         string
+		negotiateErr error
+		studyStatus  int
+		attempts     int
+	}{
+		{
+			name:         "DatasetNotFound",
+			negotiateErr: status.Error(codes.NotFound, "no such dataset"),
+			studyStatus:  http.StatusCreated,
+			attempts:     1,
+		},
+		{
+			name:         "ProviderUnavailable",
+			negotiateErr: status.Error(codes.Unavailable, "connection refused"),
+			studyStatus:  http.StatusCreated,
+			attempts:     3,
+		},
+		{
+			name:        "StudyRejects",
+			studyStatus: http.StatusBadRequest,
+			attempts:    1,
+		},
+		{
+			name:        "StudyUnavailable",
+			studyStatus: http.StatusBadGateway,
+			attempts:    3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := setup(t, servePublished, func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tt.studyStatus)
+			})
+			e.dsp.negotiateErr = tt.negotiateErr
+			o := e.newOrchestrator(time.Millisecond)
+
+			_, err := o.StartContribution(e.ctx, consent(types.AccessTypeFull), files)
+			assert.NoError(t, err)
+			f := waitForState(t, e.ctx, o, types.ContributionStateFailed)
+			assert.Equal(t, tt.attempts, f.Attempts)
+			assert.NotEqual(t, "", f.Error)
+		})
+	}
+}
+
+func TestContributeTokenExpires(t *testing.T) {
+	tests := []struct {
+		name      string
+		expiresIn time.Duration
+		attempts  int
+		err       string
+	}{
+		{
+			name:      "BeforeRetry",
+			expiresIn: time.Minute,
+			attempts:  1,
+			err:       "study returned status code 502: , and the token of the user expires before the next attempt",
+		},
+		{
+			name:      "BeforeFirstAttempt",
+			expiresIn: -time.Minute,
+			attempts:  0,
+			err:       "the token of the user expired before the file was contributed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := setup(t, servePublished, func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusBadGateway)
+			})
+			o := e.newOrchestrator(time.Hour)
+			ctx := identity.Inject(e.ctx, identity.Identity{
+				Subject:   "user-a",
+				ExpiresAt: time.Now().Add(tt.expiresIn),
+			})
+
+			_, err := o.StartContribution(ctx, consent(types.AccessTypeFull), files)
+			assert.NoError(t, err)
+			f := waitForState(t, ctx, o, types.ContributionStateFailed)
+			assert.Equal(t, tt.attempts, f.Attempts)
+			assert.Equal(t, tt.err, f.Error)
+		})
+	}
+}
+
+func TestCancelOnOtherReplica(t *testing.T) {
+	uploading := make(chan struct{})
+	aborted := make(chan struct{})
+	e := setup(t, servePublished, func(_ http.ResponseWriter, r *http.Request) {
+		_, _ = io.ReadAll(r.Body)
+		close(uploading)
+		// The upload only ends when the orchestrator aborts it.
+		<-r.Context().Done()
+		close(aborted)
+	})
+	a := e.newOrchestrator(time.Millisecond)
+	b := e.newOrchestrator(time.Millisecond)
+
+	_, err := a.StartContribution(e.ctx, consent(types.AccessTypeFull), files)
+	assert.NoError(t, err)
+	select {
+	case <-uploading:
+	case <-time.After(5 * time.Second):
+		t.Fatal("file wasn't uploaded")
+	}
+
+	assert.NoError(t, b.CancelContribution(e.ctx, consentID))
+	select {
+	case <-aborted:
+	case <-time.After(5 * time.Second):
+		t.Fatal("upload wasn't aborted")
+	}
+	f := waitForState(t, e.ctx, b, types.ContributionStateCancelled)
+	assert.Equal(t, 1, f.Attempts)
+	eventually(t, func() bool { return len(e.dsp.completedTransfers()) == 1 }, "transfer wasn't completed")
+
+	// The cancelled state isn't overwritten by the stopped contribution.
+	time.Sleep(50 * time.Millisecond)
+	c, err := a.GetContribution(e.ctx, consentID)
+	assert.NoError(t, err)
+	assert.Equal(t, types.ContributionStateCancelled, c.Files[0].State)
+}
+
+func TestCancelFinished(t *testing.T) {
+	e := setup(t, servePublished, func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+	o := e.newOrchestrator(time.Millisecond)
+
+	_, err := o.StartContribution(e.ctx, consent(types.AccessTypeFull), files)
+	assert.NoError(t, err)
+	waitForState(t, e.ctx, o, types.ContributionStateCompleted)
+
+	assert.NoError(t, o.CancelContribution(e.ctx, consentID))
+	c, err := o.GetContribution(e.ctx, consentID)
+	assert.NoError(t, err)
+	assert.Equal(t, types.ContributionStateCompleted, c.Files[0].State)
+
+	// Cancelling the contribution of a consent without one does nothing.
+	assert.NoError(t, o.CancelContribution(e.ctx, uuid.New()))
+}
+
+func TestContributeWithoutUser(t *testing.T) {
+	e := setup(t, servePublished, func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+	o := e.newOrchestrator(time.Millisecond)
+
+	_, err := o.StartContribution(context.Background(), consent(types.AccessTypeFull), files)
+	assert.Error(t, err)
+	_, err = o.GetContribution(context.Background(), consentID)
+	assert.Error(t, err)
+}
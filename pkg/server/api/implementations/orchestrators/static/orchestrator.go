@@ -0,0 +1,135 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package static contains an in-memory contribution orchestrator implementation, made for basic
+// testing. It doesn't transfer any files.
+package static
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/identity"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/logging"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/types"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer trace.Tracer
+
+func init() {
+	tracer = otel.Tracer(
+		"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/implementations/orchestrators/static",
+	)
+}
+
+// Orchestrator keeps the contributions in memory, they are lost on restart. The files covered by
+// a consent are recorded as completed right away, without transferring them, so the app can be
+// tried without providers and a study to contribute to.
+type Orchestrator struct {
+	sync.Mutex
+	contributions map[string]map[uuid.UUID]types.Contribution
+}
+
+func New() *Orchestrator {
+	return &Orchestrator{
+		contributions: make(map[string]map[uuid.UUID]types.Contribution),
+	}
+}
+
+// StartContribution records the files covered by the consent as completed. Like with the redis
+// orchestrator, files the user only consented to share anonymized or pseudonymized are recorded as
+// failed.
+func (o *Orchestrator) StartContribution(
+	ctx context.Context, consent types.Consent, files []types.ProviderFile,
+) (types.Contribution, error) {
+	logger := logging.Extract(ctx).With("consent_id", consent.ID)
+	logger.Info("Starting contribution")
+	_, span := tracer.Start(ctx, "StaticOrchestrator.StartContribution")
+	defer span.End()
+
+	subject, err := identity.Subject(ctx)
+	if err != nil {
+		return types.Contribution{}, err
+	}
+
+	now := time.Now().UTC()
+	c := types.Contribution{
+		ConsentID: consent.ID,
+		StudyID:   consent.StudyID,
+		CreatedAt: now,
+		Files:     make([]types.FileContribution, 0, len(files)),
+	}
+	for _, f := range files {
+		data, ok := consent.Covers(f)
+		if !ok {
+			continue
+		}
+		fc := types.FileContribution{
+			ProviderID:   f.Provider.ID,
+			FileID:       f.ID,
+			Name:         f.Name,
+			ResearchData: data.Name,
+			AccessType:   data.AccessType,
+			State:        types.ContributionStateCompleted,
+			UpdatedAt:    now,
+		}
+		if data.AccessType != types.AccessTypeFull {
+			fc.State = types.ContributionStateFailed
+			fc.Error = fmt.Sprintf("files can't be shared at %s access", data.AccessType)
+		}
+		c.Files = append(c.Files, fc)
+	}
+
+	o.Lock()
+	defer o.Unlock()
+	if o.contributions[subject] == nil {
+		o.contributions[subject] = make(map[uuid.UUID]types.Contribution)
+	}
+	o.contributions[subject][consent.ID] = c
+	return c, nil
+}
+
+// GetContribution returns the contribution of the consent.
+func (o *Orchestrator) GetContribution(ctx context.Context, consentID uuid.UUID) (types.Contribution, error) {
+	logger := logging.Extract(ctx)
+	logger.Info("Getting contribution", "consent_id", consentID)
+	_, span := tracer.Start(ctx, "StaticOrchestrator.GetContribution")
+	defer span.End()
+
+	subject, err := identity.Subject(ctx)
+	if err != nil {
+		return types.Contribution{}, err
+	}
+
+	o.Lock()
+	defer o.Unlock()
+	c, ok := o.contributions[subject][consentID]
+	if !ok {
+		return types.Contribution{}, fmt.Errorf("%w: contribution %s not found", types.ErrNotFound, consentID)
+	}
+	return c, nil
+}
+
+// CancelContribution does nothing, as all files are completed or failed right away.
+func (o *Orchestrator) CancelContribution(ctx context.Context, consentID uuid.UUID) error {
+	logger := logging.Extract(ctx)
+	logger.Info("Cancelling contribution", "consent_id", consentID)
+	return nil
+}
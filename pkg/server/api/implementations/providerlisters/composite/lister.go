@@ -0,0 +1,231 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package composite contains a provider lister that merges the providers of several listers, so
+// providers not registered in the federated catalogue yet can be listed besides it.
+package composite
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/logging"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/types"
+	"github.com/go-jose/go-jose/v4"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer trace.Tracer
+
+func init() {
+	tracer = otel.Tracer(
+		"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/implementations/providerlisters/composite",
+	)
+}
+
+// Source is a provider lister the composite lister merges the providers of.
+type Source struct {
+	Name   string
+	Lister types.ProviderLister
+}
+
+// ProviderLister merges the providers of its sources. Providers with the same provider URL or
+// credential subject are the same provider; of their fields the value of the first source in
+// order of precedence that has it set is used, including the ID.
+type ProviderLister struct {
+	sources []Source
+}
+
+// member is a provider as listed by one of the sources.
+type member struct {
+	source   Source
+	provider types.Provider
+}
+
+// merged is a provider merged from the providers of several sources, in order of precedence.
+type merged struct {
+	provider types.Provider
+	members  []member
+}
+
+// New creates a composite provider lister, the sources are in order of precedence.
+func New(sources ...Source) *ProviderLister {
+	return &ProviderLister{sources: sources}
+}
+
+// ListProviders returns the merged providers of all sources.
+func (pl *ProviderLister) ListProviders(ctx context.Context) ([]types.Provider, error) {
+	logger := logging.Extract(ctx)
+	logger.Info("Listing providers")
+	ctx, span := tracer.Start(ctx, "compositeProviderLister.ListProviders")
+	defer span.End()
+	providers, err := pl.merge(ctx)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]types.Provider, len(providers))
+	for i, p := range providers {
+		list[i] = p.provider
+	}
+	return list, nil
+}
+
+// GetProvider returns the provider with the given ID, which can be its ID in any of the sources.
+func (pl *ProviderLister) GetProvider(ctx context.Context, providerID string) (types.Provider, error) {
+	logger := logging.Extract(ctx)
+	logger.Info("Getting single provider")
+	ctx, span := tracer.Start(ctx, "compositeProviderLister.GetProvider")
+	defer span.End()
+	p, err := pl.find(ctx, providerID)
+	return p.provider, err
+}
+
+// GetProviderURL returns the provider URL of the provider with the given ID.
+func (pl *ProviderLister) GetProviderURL(ctx context.Context, providerID string) (string, error) {
+	logger := logging.Extract(ctx)
+	logger.Info("Getting provider URL")
+	ctx, span := tracer.Start(ctx, "compositeProviderLister.GetProviderURL")
+	defer span.End()
+	p, err := pl.find(ctx, providerID)
+	return p.provider.ProviderUrl, err
+}
+
+// GetProviderKeys returns the keys of the first source in order of precedence that has keys for
+// the provider.
+func (pl *ProviderLister) GetProviderKeys(ctx context.Context, providerID string) ([]jose.JSONWebKey, error) {
+	logger := logging.Extract(ctx)
+	logger.Info("Getting provider keys")
+	ctx, span := tracer.Start(ctx, "compositeProviderLister.GetProviderKeys")
+	defer span.End()
+	p, err := pl.find(ctx, providerID)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range p.members {
+		keys, err := m.source.Lister.GetProviderKeys(ctx, m.provider.ID)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't get keys from %s: %w", m.source.Name, err)
+		}
+		if len(keys) > 0 {
+			return keys, nil
+		}
+	}
+	return []jose.JSONWebKey{}, nil
+}
+
+func (pl *ProviderLister) find(ctx context.Context, providerID string) (merged, error) {
+	providers, err := pl.merge(ctx)
+	if err != nil {
+		return merged{}, err
+	}
+	for _, p := range providers {
+		for _, m := range p.members {
+			if m.provider.ID == providerID {
+				return p, nil
+			}
+		}
+	}
+	return merged{}, fmt.Errorf("%w: provider not found", types.ErrNotFound)
+}
+
+// merge lists the providers of all sources and merges the duplicates. A source that fails is
+// logged and left out, unless all of them fail.
+func (pl *ProviderLister) merge(ctx context.Context) ([]merged, error) {
+	logger := logging.Extract(ctx)
+	var (
+		providers []merged
+		errs      []error
+	)
+	byURL := map[string]int{}
+	bySubject := map[string]int{}
+	for _, s := range pl.sources {
+		list, err := s.Lister.ListProviders(ctx)
+		if err != nil {
+			logger.Error("Couldn't list providers of source", "source", s.Name, "error", err)
+			errs = append(errs, fmt.Errorf("%s: %w", s.Name, err))
+			continue
+		}
+		for _, p := range list {
+			url, subject := normaliseURL(p.ProviderUrl), credentialSubject(p)
+			i, ok := byURL[url]
+			if !ok && subject != "" {
+				i, ok = bySubject[subject]
+			}
+			if !ok {
+				i = len(providers)
+				providers = append(providers, merged{provider: p})
+			} else {
+				providers[i].provider = mergeProvider(providers[i].provider, p)
+			}
+			providers[i].members = append(providers[i].members, member{source: s, provider: p})
+			if url != "" {
+				byURL[url] = i
+			}
+			if subject != "" {
+				bySubject[subject] = i
+			}
+		}
+	}
+	if len(errs) > 0 && len(errs) == len(pl.sources) {
+		return nil, fmt.Errorf("couldn't list providers: %w", errors.Join(errs...))
+	}
+	return providers, nil
+}
+
+// mergeProvider fills the empty fields of the provider from a provider of a source with lower
+// precedence. The public key and its source are taken together.
+func mergeProvider(p, other types.Provider) types.Provider {
+	fill := func(field *string, value string) {
+		if *field == "" {
+			*field = value
+		}
+	}
+	fill(&p.Name, other.Name)
+	fill(&p.Description, other.Description)
+	fill(&p.LogoURI, other.LogoURI)
+	fill(&p.ContactInformation, other.ContactInformation)
+	fill(&p.VerifiableCredential, other.VerifiableCredential)
+	fill(&p.MetadataKey, other.MetadataKey)
+	fill(&p.ProviderUrl, other.ProviderUrl)
+	if p.PublicKey == "" {
+		p.PublicKey = other.PublicKey
+		p.PublicKeySource = other.PublicKeySource
+	}
+	return p
+}
+
+func normaliseURL(u string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(u)), "/")
+}
+
+// credentialSubject returns the ID of the subject of the provider's verifiable credential.
+func credentialSubject(p types.Provider) string {
+	if p.VerifiableCredential == "" {
+		return ""
+	}
+	var vc struct {
+		CredentialSubject struct {
+			ID string `json:"id"`
+		} `json:"credentialSubject"`
+	}
+	if err := json.Unmarshal([]byte(p.VerifiableCredential), &vc); err != nil {
+		return ""
+	}
+	return vc.CredentialSubject.ID
+}
@@ -0,0 +1,114 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package composite_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	mtypes "github.com/HEALTH-X-dataLOFT/cma-backend/mocks/github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/types"
+	plcomposite "github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/implementations/providerlisters/composite"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/types"
+	"github.com/alecthomas/assert/v2"
+	"github.com/go-jose/go-jose/v4"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestProviderLister(t *testing.T) {
+	ctx := context.Background()
+	catalogue := mtypes.NewMockProviderLister(t)
+	file := mtypes.NewMockProviderLister(t)
+	catalogue.EXPECT().ListProviders(mock.Anything).Return([]types.Provider{
+		{
+			ID:                   "fc-a",
+			Name:                 "Hospital A",
+			ProviderUrl:          "https://dsp.hospital-a.example.com/",
+			VerifiableCredential: `{"credentialSubject": {"id": "did:web:hospital-a.example.com"}}`,
+		},
+		{
+			ID:                   "fc-b",
+			Name:                 "Hospital B",
+			ProviderUrl:          "https://dsp.hospital-b.example.com",
+			VerifiableCredential: `{"credentialSubject": {"id": "did:web:hospital-b.example.com"}}`,
+		},
+	}, nil)
+	file.EXPECT().ListProviders(mock.Anything).Return([]types.Provider{
+		{
+			ID:          "file-a",
+			Name:        "Pilot hospital A",
+			Description: "Onboarded from the file",
+			ProviderUrl: "https://DSP.hospital-a.example.com",
+			PublicKey:   "key",
+		},
+		{
+			ID:          "file-c",
+			Name:        "Hospital C",
+			ProviderUrl: "https://dsp.hospital-c.example.com",
+		},
+	}, nil)
+	pl := plcomposite.New(
+		plcomposite.Source{Name: "fc", Lister: catalogue},
+		plcomposite.Source{Name: "file", Lister: file},
+	)
+
+	providers, err := pl.ListProviders(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(providers))
+	assert.Equal(t, "fc-a", providers[0].ID)
+	assert.Equal(t, "Hospital A", providers[0].Name)
+	assert.Equal(t, "Onboarded from the file", providers[0].Description)
+	assert.Equal(t, "key", providers[0].PublicKey)
+	assert.Equal(t, "file-c", providers[2].ID)
+
+	p, err := pl.GetProvider(ctx, "file-a")
+	assert.NoError(t, err)
+	assert.Equal(t, "fc-a", p.ID)
+
+	url, err := pl.GetProviderURL(ctx, "file-c")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://dsp.hospital-c.example.com", url)
+
+	key := jose.JSONWebKey{KeyID: "a-1"}
+	catalogue.EXPECT().GetProviderKeys(mock.Anything, "fc-a").Return(nil, nil)
+	file.EXPECT().GetProviderKeys(mock.Anything, "file-a").Return([]jose.JSONWebKey{key}, nil)
+	keys, err := pl.GetProviderKeys(ctx, "fc-a")
+	assert.NoError(t, err)
+	assert.Equal(t, []jose.JSONWebKey{key}, keys)
+
+	_, err = pl.GetProvider(ctx, "unknown")
+	assert.IsError(t, err, types.ErrNotFound)
+}
+
+func TestProviderListerFailingSource(t *testing.T) {
+	ctx := context.Background()
+	catalogue := mtypes.NewMockProviderLister(t)
+	file := mtypes.NewMockProviderLister(t)
+	catalogue.EXPECT().ListProviders(mock.Anything).Return(nil, errors.New("catalogue down"))
+	file.EXPECT().ListProviders(mock.Anything).Return([]types.Provider{{ID: "file-c"}}, nil).Once()
+	pl := plcomposite.New(
+		plcomposite.Source{Name: "fc", Lister: catalogue},
+		plcomposite.Source{Name: "file", Lister: file},
+	)
+
+	providers, err := pl.ListProviders(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(providers))
+
+	file.EXPECT().ListProviders(mock.Anything).Return(nil, errors.New("file broken"))
+	_, err = pl.ListProviders(ctx)
+	assert.Error(t, err)
+}
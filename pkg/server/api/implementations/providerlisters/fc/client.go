@@ -0,0 +1,220 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fc
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// tokenExpiryLeeway is how long before it expires an access token is replaced.
+	tokenExpiryLeeway = 30 * time.Second
+	// pageSize is the number of items requested per page from the catalogue.
+	pageSize = 100
+	// maxPages limits paging, in case the catalogue keeps returning full pages.
+	maxPages = 1000
+)
+
+// ClientConfig configures how the catalogue is accessed. The client authenticates with the
+// client certificate and with OAuth2 client credentials if they are set.
+type ClientConfig struct {
+	Timeout      time.Duration
+	CAFile       string
+	CertFile     string
+	KeyFile      string
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+}
+
+// NewHTTPClient creates the client the catalogue is accessed with.
+func NewHTTPClient(cfg ClientConfig) (*http.Client, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't read catalogue CA: %w", err)
+		}
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in catalogue CA %s", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = roots
+	}
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't load catalogue client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	client := &http.Client{Timeout: cfg.Timeout, Transport: transport}
+	if cfg.TokenURL == "" {
+		return client, nil
+	}
+	if cfg.ClientID == "" {
+		return nil, errors.New("catalogue client ID is required with a token URL")
+	}
+	client.Transport = &clientCredentials{
+		next: transport,
+		// The token is fetched with its own client, so it isn't sent with the token request.
+		client: &http.Client{Timeout: cfg.Timeout, Transport: transport},
+		cfg:    cfg,
+	}
+	return client, nil
+}
+
+// clientCredentials adds an access token obtained with the OAuth2 client credentials grant to
+// requests, fetching a new one when it is about to expire.
+type clientCredentials struct {
+	sync.Mutex
+	next      http.RoundTripper
+	client    *http.Client
+	cfg       ClientConfig
+	token     string
+	expiresAt time.Time
+}
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+func (cc *clientCredentials) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := cc.accessToken(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	// A RoundTripper must not modify the request it was given.
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+	return cc.next.RoundTrip(req)
+}
+
+func (cc *clientCredentials) accessToken(ctx context.Context) (string, error) {
+	cc.Lock()
+	defer cc.Unlock()
+	if cc.token != "" && time.Now().Before(cc.expiresAt) {
+		return cc.token, nil
+	}
+
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(cc.cfg.Scopes) > 0 {
+		form.Set("scope", strings.Join(cc.cfg.Scopes, " "))
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cc.cfg.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Add("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(cc.cfg.ClientID), url.QueryEscape(cc.cfg.ClientSecret))
+	resp, err := cc.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("couldn't get catalogue access token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("HTTP error when getting catalogue access token: %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	var tr tokenResponse
+	if err := json.Unmarshal(body, &tr); err != nil {
+		return "", fmt.Errorf("couldn't parse catalogue access token: %w", err)
+	}
+	if tr.AccessToken == "" || (tr.TokenType != "" && !strings.EqualFold(tr.TokenType, "bearer")) {
+		return "", fmt.Errorf("unsupported catalogue access token of type %q", tr.TokenType)
+	}
+	cc.token = tr.AccessToken
+	// Without expires_in the token is fetched again for the next request.
+	cc.expiresAt = time.Now().Add(time.Duration(tr.ExpiresIn)*time.Second - tokenExpiryLeeway)
+	return cc.token, nil
+}
+
+// doJSON sends a request to the catalogue, with the body marshalled as JSON if given, and
+// unmarshals the JSON response into out.
+func (pl *ProviderLister) doJSON(ctx context.Context, method string, target string, body any, out any) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reqBody)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Add("Content-Type", "application/json")
+	}
+	req.Header.Add("Accept", "application/json")
+	resp, err := pl.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("HTTP error when getting %s: %d", target, resp.StatusCode)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// getPages fetches all pages of a listing. fetchPage returns the items from the offset on, and
+// the total count of items if the catalogue reports it.
+func getPages[T any](
+	ctx context.Context,
+	fetchPage func(ctx context.Context, offset, limit int) ([]T, int64, error),
+) ([]T, error) {
+	var items []T
+	for page := 0; page < maxPages; page++ {
+		pageItems, total, err := fetchPage(ctx, page*pageSize, pageSize)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't get page %d: %w", page, err)
+		}
+		items = append(items, pageItems...)
+		if len(pageItems) < pageSize || (total > 0 && int64(len(items)) >= total) {
+			return items, nil
+		}
+	}
+	return nil, fmt.Errorf("more than %d pages", maxPages)
+}
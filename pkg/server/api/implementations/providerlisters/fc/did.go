@@ -0,0 +1,167 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/logging"
+	"github.com/go-jose/go-jose/v4"
+)
+
+const (
+	didWebPrefix = "did:web:"
+	// didCacheTTL is how long a resolved DID document is used before it is fetched again.
+	didCacheTTL = 15 * time.Minute
+)
+
+// didDocument is the part of a DID document needed to find the key of a verification method.
+type didDocument struct {
+	ID                 string               `json:"id"`
+	VerificationMethod []verificationMethod `json:"verificationMethod"`
+}
+
+type verificationMethod struct {
+	ID                 string           `json:"id"`
+	Controller         string           `json:"controller"`
+	PublicKeyJWK       *jose.JSONWebKey `json:"publicKeyJwk"`
+	PublicKeyMultibase string           `json:"publicKeyMultibase"`
+}
+
+type cachedDocument struct {
+	doc       didDocument
+	fetchedAt time.Time
+}
+
+// DIDWebResolver resolves the keys of did:web verification methods. Resolved DID documents are
+// cached for a while, as the participants are verified on every update of the catalogue.
+type DIDWebResolver struct {
+	sync.Mutex
+	client *http.Client
+	cache  map[string]cachedDocument
+}
+
+// NewDIDWebResolver creates a resolver that fetches DID documents with the given client.
+func NewDIDWebResolver(client *http.Client) *DIDWebResolver {
+	return &DIDWebResolver{
+		client: client,
+		cache:  map[string]cachedDocument{},
+	}
+}
+
+// ResolveKey returns the public key of the verification method, a DID URL like
+// did:web:example.com#key-1. The key is either a JWK or an Ed25519 Multikey.
+func (r *DIDWebResolver) ResolveKey(ctx context.Context, method string) (jose.JSONWebKey, error) {
+	did, fragment, _ := strings.Cut(method, "#")
+	doc, err := r.document(ctx, did)
+	if err != nil {
+		return jose.JSONWebKey{}, err
+	}
+	for _, vm := range doc.VerificationMethod {
+		if vm.ID != method && vm.ID != "#"+fragment {
+			continue
+		}
+		if vm.PublicKeyJWK == nil && vm.PublicKeyMultibase != "" {
+			key, err := decodeMultikey(vm.PublicKeyMultibase)
+			if err != nil {
+				return jose.JSONWebKey{}, fmt.Errorf("verification method %s: %w", method, err)
+			}
+			return jose.JSONWebKey{Key: key, KeyID: vm.ID}, nil
+		}
+		if vm.PublicKeyJWK == nil {
+			return jose.JSONWebKey{}, fmt.Errorf("verification method %s has no public key", method)
+		}
+		if !vm.PublicKeyJWK.IsPublic() || !vm.PublicKeyJWK.Valid() {
+			return jose.JSONWebKey{}, fmt.Errorf("verification method %s has no valid public key", method)
+		}
+		return *vm.PublicKeyJWK, nil
+	}
+	return jose.JSONWebKey{}, fmt.Errorf("verification method %s not found in %s", method, did)
+}
+
+func (r *DIDWebResolver) document(ctx context.Context, did string) (didDocument, error) {
+	r.Lock()
+	defer r.Unlock()
+	if cached, ok := r.cache[did]; ok && time.Since(cached.fetchedAt) < didCacheTTL {
+		return cached.doc, nil
+	}
+
+	docURL, err := DIDWebURL(did)
+	if err != nil {
+		return didDocument{}, err
+	}
+	logging.Extract(ctx).Info("Resolving DID", "did", did, "url", docURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, docURL, nil)
+	if err != nil {
+		return didDocument{}, err
+	}
+	req.Header.Add("Accept", "application/json")
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return didDocument{}, fmt.Errorf("couldn't resolve %s: %w", did, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return didDocument{}, fmt.Errorf("HTTP error when resolving %s: %d", did, resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return didDocument{}, err
+	}
+	var doc didDocument
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return didDocument{}, fmt.Errorf("couldn't parse DID document of %s: %w", did, err)
+	}
+	if doc.ID != did {
+		return didDocument{}, fmt.Errorf("DID document of %s is for %s", did, doc.ID)
+	}
+	r.cache[did] = cachedDocument{doc: doc, fetchedAt: time.Now()}
+	return doc, nil
+}
+
+// DIDWebURL returns the URL of the DID document of a did:web DID, as defined by the did:web
+// method specification.
+func DIDWebURL(did string) (string, error) {
+	id, ok := strings.CutPrefix(did, didWebPrefix)
+	if !ok || id == "" {
+		return "", fmt.Errorf("unsupported DID %q, only did:web is supported", did)
+	}
+	parts := strings.Split(id, ":")
+	host, err := url.PathUnescape(parts[0])
+	if err != nil || host == "" || strings.ContainsAny(host, "/?#") {
+		return "", fmt.Errorf("invalid host in DID %q", did)
+	}
+	if len(parts) == 1 {
+		return fmt.Sprintf("https://%s/.well-known/did.json", host), nil
+	}
+	path := make([]string, 0, len(parts)-1)
+	for _, p := range parts[1:] {
+		segment, err := url.PathUnescape(p)
+		if err != nil || segment == "" {
+			return "", fmt.Errorf("invalid path in DID %q", did)
+		}
+		path = append(path, url.PathEscape(segment))
+	}
+	return fmt.Sprintf("https://%s/%s/did.json", host, strings.Join(path, "/")), nil
+}
@@ -21,19 +21,22 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/http"
+	"sync/atomic"
 	"time"
 
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/events"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/leader"
 	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/logging"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/providerkeys"
 	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/types"
+	"github.com/go-jose/go-jose/v4"
 	"github.com/redis/go-redis/v9"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/trace"
 )
 
-const (
-	storageKey   = "catalogue:fc"
-	pollInterval = 1
-)
+const storageKey = "catalogue:fc"
 
 var tracer trace.Tracer
 
@@ -46,25 +49,47 @@ func init() {
 }
 
 type ProviderLister struct {
-	r                  *redis.Client
-	catalogURL         string
-	providerPublicKeys map[string]string
+	r          *redis.Client
+	bus        events.Bus
+	catalogURL string
+	client     *http.Client
+	query      *Query
+	verifier   *Verifier
+	// elector decides whether this replica polls the catalogue, the others read the providers the
+	// leader stored.
+	elector *leader.Elector
+	// providerPublicKeys and rules are swapped when their files are reloaded.
+	providerPublicKeys atomic.Pointer[providerkeys.Keys]
+	rules              atomic.Pointer[Rules]
+	// suspiciousPolls counts the polls in a row that returned too few providers, it is only used
+	// by the monitor.
+	suspiciousPolls int
 }
 
-// New creates a new federated catalogue provider lister.
+// New creates a new federated catalogue provider lister, the providers are polled with
+// UpdateParticipants.
 func New(
-	ctx context.Context,
 	redisClient *redis.Client,
+	bus events.Bus,
 	catalogURL string,
-	publicKeys map[string]string,
+	client *http.Client,
+	query *Query,
+	publicKeys providerkeys.Keys,
+	verifier *Verifier,
+	rules *Rules,
+	elector *leader.Elector,
 ) (*ProviderLister, error) {
 	pl := &ProviderLister{
-		r:                  redisClient,
-		catalogURL:         catalogURL,
-		providerPublicKeys: publicKeys,
+		r:          redisClient,
+		bus:        bus,
+		catalogURL: catalogURL,
+		client:     client,
+		query:      query,
+		verifier:   verifier,
+		elector:    elector,
 	}
-	t := time.NewTicker(pollInterval * time.Minute)
-	go pl.monitorParticipants(ctx, t)
+	pl.providerPublicKeys.Store(&publicKeys)
+	pl.rules.Store(rules)
 	return pl, nil
 }
 
@@ -87,10 +112,6 @@ func (pl *ProviderLister) ListProviders(ctx context.Context) ([]types.Provider,
 			}
 			return nil, fmt.Errorf("couldn't convert provider: %w", err)
 		}
-		pr.PublicKey = pl.providerPublicKeys[pr.ProviderUrl]
-		if pr.PublicKey == "" {
-			pr.PublicKey = "No key found"
-		}
 		providers = append(providers, pr)
 	}
 	return providers, nil
@@ -109,7 +130,71 @@ func (pl *ProviderLister) GetProvider(ctx context.Context, providerID string) (t
 		}
 		return types.Provider{}, fmt.Errorf("couldn't get provider: %w", err)
 	}
-	return pl.convertProvider(p)
+	pr, err := pl.convertProvider(p)
+	if errors.Is(err, ErrProviderExcluded) {
+		return types.Provider{}, fmt.Errorf("%w: provider not found", types.ErrNotFound)
+	}
+	return pr, err
+}
+
+// SetPublicKeys replaces the keys from the public key file, and logs what changed.
+func (pl *ProviderLister) SetPublicKeys(ctx context.Context, keys providerkeys.Keys) {
+	logger := logging.Extract(ctx)
+	old := pl.providerPublicKeys.Swap(&keys)
+	changes := providerkeys.Diff(*old, keys)
+	for _, c := range changes {
+		logger.Info("Provider public key changed", "change", c)
+	}
+	logger.Info("Provider public keys reloaded", "changes", len(changes))
+}
+
+// SetRules replaces the rules deciding which providers are listed.
+func (pl *ProviderLister) SetRules(ctx context.Context, rules *Rules) {
+	pl.rules.Store(rules)
+	logging.Extract(ctx).Info("Provider rules reloaded", "rules", len(rules.Rules))
+}
+
+// GetProviderKeys returns the public keys of the provider, the keys from the public key file if
+// there are any, else the key published in the catalogue.
+func (pl *ProviderLister) GetProviderKeys(ctx context.Context, providerID string) ([]jose.JSONWebKey, error) {
+	logger := logging.Extract(ctx)
+	logger.Info("Getting provider keys")
+	ctx, span := tracer.Start(ctx, "fcProviderLister.GetProviderKeys")
+	defer span.End()
+	p, err := pl.r.HGet(ctx, storageKey, providerID).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, fmt.Errorf("%w: provider not found", types.ErrNotFound)
+		}
+		return nil, fmt.Errorf("couldn't get provider: %w", err)
+	}
+	var prov ProviderInfo
+	if err := json.Unmarshal([]byte(p), &prov); err != nil {
+		return nil, fmt.Errorf("couldn't unmarshal provider: %w", err)
+	}
+	if err := pl.checkProvider(prov); err != nil {
+		return nil, fmt.Errorf("%w: provider not found", types.ErrNotFound)
+	}
+	keys, _ := pl.providerKeys(prov)
+	return keys, nil
+}
+
+// providerKeys returns the keys of the provider and where they came from. Keys in the public key
+// file override the key in the catalogue, so a key can be pinned, or replaced if the catalogue
+// publishes an invalid one.
+func (pl *ProviderLister) providerKeys(prov ProviderInfo) ([]jose.JSONWebKey, types.KeySource) {
+	if keys := (*pl.providerPublicKeys.Load())[providerURL(prov)]; len(keys) > 0 {
+		return keys, types.KeySourceFile
+	}
+	if prov.PublicKey == "" {
+		return nil, ""
+	}
+	// Invalid catalogue keys are logged when the providers are retrieved.
+	key, err := providerkeys.Parse(prov.PublicKey)
+	if err != nil {
+		return nil, ""
+	}
+	return []jose.JSONWebKey{key}, types.KeySourceCatalogue
 }
 
 // GetProviderURL returns the provider URL for the given provider ID.
@@ -130,8 +215,11 @@ func (pl *ProviderLister) GetProviderURL(ctx context.Context, providerID string)
 	if err != nil {
 		return "", fmt.Errorf("couldn't unmarshal provider: %w", err)
 	}
+	if err := pl.checkProvider(prov); err != nil {
+		return "", fmt.Errorf("%w: provider not found", types.ErrNotFound)
+	}
 
-	return prov.Host, nil
+	return providerURL(prov), nil
 }
 
 func (pl *ProviderLister) convertProvider(p string) (types.Provider, error) {
@@ -140,15 +228,48 @@ func (pl *ProviderLister) convertProvider(p string) (types.Provider, error) {
 	if err != nil {
 		return types.Provider{}, err
 	}
+	if err := pl.checkProvider(prov); err != nil {
+		return types.Provider{}, err
+	}
 	vc, err := json.Marshal(prov.VerifiableCredential)
+	if err != nil {
+		return types.Provider{}, err
+	}
 
-	return types.Provider{
+	pr := types.Provider{
 		ID:                   prov.ID,
 		Name:                 prov.Name,
-		Description:          "No description available.",
-		LogoURI:              "",
-		ContactInformation:   "No contact information available.",
+		Description:          prov.Details.Description,
+		LogoURI:              prov.Details.LogoURI,
+		ContactInformation:   prov.Details.ContactInformation,
 		VerifiableCredential: string(vc),
-		ProviderUrl:          fmt.Sprintf("%s://%s", prov.Protocol, prov.Host),
-	}, err
+		ProviderUrl:          providerURL(prov),
+	}
+	if pr.Description == "" {
+		pr.Description = "No description available."
+	}
+	if pr.ContactInformation == "" {
+		pr.ContactInformation = "No contact information available."
+	}
+	// Providers without a valid key are listed with an empty public key.
+	keys, source := pl.providerKeys(prov)
+	if len(keys) > 0 {
+		pr.PublicKey, err = providerkeys.Encode(keys[0])
+		pr.PublicKeySource = source
+	}
+	return pr, err
+}
+
+// checkProvider returns an error wrapping ErrProviderExcluded if the provider shouldn't be listed.
+// Providers are checked when they are read, as the rules can be reloaded and the credential can
+// expire between two updates of the catalogue.
+func (pl *ProviderLister) checkProvider(prov ProviderInfo) error {
+	if expired(prov.VerifiableCredential, time.Now()) {
+		return fmt.Errorf("%w: credential of %s expired", ErrProviderExcluded, prov.Name)
+	}
+	return pl.rules.Load().Check(prov)
+}
+
+func providerURL(prov ProviderInfo) string {
+	return endpointURL(prov.Protocol, prov.Host, prov.Port, prov.Path)
 }
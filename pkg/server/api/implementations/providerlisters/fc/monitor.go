@@ -16,74 +16,50 @@
 package fc
 
 import (
-	"bytes"
 	"context"
 	"crypto/sha256"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"net/http"
-	"time"
+	"slices"
 
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/events"
 	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/logging"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/providerkeys"
 )
 
 const (
 	participantsPath     = "participants"
 	selfDescriptionsPath = "self-descriptions"
 	queryPath            = "query"
-	participantQuery     = "MATCH (provider:LegalParticipant) <-[:providedBy]- (offer:ServiceOffering) -[:aggregationOf]-> (s:SoftwareResource) <-[:instanceOf]- (rh:InstantiatedVirtualResource) -[:serviceAccessPoint]-> (access:ServiceAccessPoint) WHERE access.name = 'ids' return {provider: provider.legalName, protocol: access.protocol, port: access.port, host: access.host}" //nolint:lll
 )
 
 func (pl *ProviderLister) getProviderQueryData(ctx context.Context) ([]FCProviderInfo, error) {
-	selfDescriptionsUrl := fmt.Sprintf("%s/%s", pl.catalogURL, selfDescriptionsPath)
 	queryUrl := fmt.Sprintf("%s/%s", pl.catalogURL, queryPath)
-	logger := logging.Extract(ctx).With("self-descriptions-url", selfDescriptionsUrl, "query-url", queryUrl)
+	logger := logging.Extract(ctx).With("query-url", queryUrl)
 	ctx, span := tracer.Start(ctx, "fcProviderLister.getProviderQueryData")
 	defer span.End()
 
-	query := FCQuery{
-		Statement: participantQuery,
-	}
-	jsonBody, err := json.Marshal(query)
-	if err != nil {
-		return nil, err
-	}
-	req, err := http.NewRequestWithContext(ctx, "POST", queryUrl, bytes.NewBuffer(jsonBody))
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Add("Content-Type", "application/json")
-	req.Header.Add("Accept", "application/json")
-
 	logger.Info("Retrieving provider info")
-	client := &http.Client{}
-	piResp, err := client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	if piResp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP error when getting providers: %d", piResp.StatusCode)
-	}
-	defer piResp.Body.Close()
-	body, err := io.ReadAll(piResp.Body)
-	if err != nil {
-		return nil, err
+	fetchPage := func(ctx context.Context, offset, limit int) ([]map[string]FCProviderInfo, int64, error) {
+		pr := &FCProviderResponse{}
+		if err := pl.doJSON(ctx, http.MethodPost, queryUrl, pl.query.page(offset, limit), pr); err != nil {
+			return nil, 0, err
+		}
+		return pr.Items, pr.TotalCount, nil
 	}
-
-	pr := &FCProviderResponse{}
-	err = json.Unmarshal(body, pr)
+	entries, err := getPages(ctx, fetchPage)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("couldn't get providers: %w", err)
 	}
 
 	var providerInfoList []FCProviderInfo
-	for _, entry := range pr.Items {
+	for _, entry := range entries {
 		for _, pi := range entry {
 			providerInfoList = append(providerInfoList, pi)
 		}
 	}
-
 	return providerInfoList, nil
 }
 
@@ -92,37 +68,27 @@ func (pl *ProviderLister) getParticipants(ctx context.Context) ([]ParticipantInf
 	logger := logging.Extract(ctx).With("participants-url", participantsUrl)
 	ctx, span := tracer.Start(ctx, "fcProviderLister.getParticipants")
 	defer span.End()
-	req, err := http.NewRequestWithContext(ctx, "GET", participantsUrl, nil)
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Add("Accept", "application/json")
 
 	logger.Info("Retrieving participant info")
-	client := &http.Client{}
-	piResp, err := client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	if piResp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP error when getting participants: %d", piResp.StatusCode)
-	}
-	defer piResp.Body.Close()
-	body, err := io.ReadAll(piResp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	participantInfoResponse := &FCParticipantsResponse{}
-	err = json.Unmarshal(body, participantInfoResponse)
+	items, err := getPages(ctx, func(ctx context.Context, offset, limit int) ([]ParticipantInfo, int64, error) {
+		pageUrl := fmt.Sprintf("%s?offset=%d&limit=%d", participantsUrl, offset, limit)
+		pr := &FCParticipantsResponse{}
+		if err := pl.doJSON(ctx, http.MethodGet, pageUrl, nil, pr); err != nil {
+			return nil, 0, err
+		}
+		return pr.Items, pr.TotalCount, nil
+	})
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("couldn't get participants: %w", err)
 	}
 
 	var participants []ParticipantInfoWithVP
-	for _, pi := range participantInfoResponse.Items {
-		vp := &VerifiablePresentation{}
-		err = json.Unmarshal([]byte(pi.SelfDescription), vp)
+	for _, pi := range items {
+		vp, err := pl.verifier.Verify(ctx, []byte(pi.SelfDescription))
+		if errors.Is(err, ErrProviderExcluded) {
+			logger.Warn("Excluding participant", "participant", pi.ID, "name", pi.Name, "error", err)
+			continue
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -131,93 +97,136 @@ func (pl *ProviderLister) getParticipants(ctx context.Context) ([]ParticipantInf
 			Name:                   pi.Name,
 			PublicKey:              pi.PublicKey,
 			SelfDescription:        pi.SelfDescription,
-			VerifiablePresentation: *vp,
+			VerifiablePresentation: vp,
 		})
 	}
 	return participants, nil
 }
 
-func (pl *ProviderLister) monitorParticipants(ctx context.Context, t *time.Ticker) {
-	logger := logging.Extract(ctx).With("monitor_type", "federated catalog provider lister")
-	logger.Info("Starting monitor")
-	pl.updateParticipants(ctx)
-	for {
-		select {
-		case <-ctx.Done():
-			logger.Info("Context done, stopping monitor")
-			t.Stop()
-			logger.Info("Timer stopped")
-			return
-		case <-t.C:
-			pl.updateParticipants(ctx)
-		}
-	}
-}
-
-func (pl *ProviderLister) updateParticipants(ctx context.Context) {
+// UpdateParticipants polls the catalogue and saves its providers, it is only done by the leader.
+func (pl *ProviderLister) UpdateParticipants(ctx context.Context) error {
 	selfDescriptionsUrl := fmt.Sprintf("%s/%s", pl.catalogURL, selfDescriptionsPath)
 	queryUrl := fmt.Sprintf("%s/%s", pl.catalogURL, queryPath)
 	logger := logging.Extract(ctx).With("self-descriptions-url", selfDescriptionsUrl, "query-url", queryUrl)
-	ctx, span := tracer.Start(ctx, "fcProviderLister.updateParticipants")
+	if !pl.elector.IsLeader() {
+		logger.Debug("Not the leader, skipping update")
+		return nil
+	}
+	ctx, span := tracer.Start(ctx, "fcProviderLister.UpdateParticipants")
 	defer span.End()
 
 	participants, err := pl.getParticipants(ctx)
 	if err != nil {
-		logger.Error("Failed to get participants", "error", err)
-		return
+		return fmt.Errorf("failed to get participants: %w", err)
 	}
 
+	// Without the provider info no provider can be listed, so the last snapshot is kept.
 	providerInfoList, err := pl.getProviderQueryData(ctx)
 	if err != nil {
-		logger.Error("Failed to get provider info", "error", err)
+		return fmt.Errorf("failed to get provider info: %w", err)
 	}
 	logger.Info("provider info", "provider-info", providerInfoList)
 
-	receivedProviders, err := normaliseProviders(ctx, participants, providerInfoList)
+	// Without the self-descriptions the providers are listed with the details in their
+	// participant presentation.
+	selfDescriptions, err := pl.getSelfDescriptions(ctx)
+	if err != nil {
+		logger.Error("Failed to get self-descriptions", "error", err)
+	}
+	details := DetailsFromSelfDescriptions(ctx, selfDescriptions)
+
+	accessPoints := pl.query.selectAccessPoints(providerInfoList)
+	receivedProviders, err := normaliseProviders(ctx, participants, accessPoints, details)
+	if err != nil {
+		return fmt.Errorf("error normalising providers: %w", err)
+	}
+	received := len(receivedProviders)
+	if pl.keepSnapshot(ctx, received) {
+		return nil
+	}
+	// Excluded providers are left out, so no events are published for them.
+	receivedProviders = slices.DeleteFunc(receivedProviders, func(p ProviderInfo) bool {
+		err := pl.checkProvider(p)
+		if err != nil {
+			logger.Info("Excluding provider", "provider", p.Name, "reason", err)
+		}
+		return err != nil
+	})
+	previous, err := pl.r.HGetAll(ctx, storageKey).Result()
 	if err != nil {
-		logger.Error("Error normalising providers", "error", err)
-		return
+		logger.Error("Couldn't get saved providers", "error", err)
+		previous = nil
+	}
+	if err := pl.saveProviders(ctx, receivedProviders, received); err != nil {
+		return fmt.Errorf("error saving providers: %w", err)
 	}
-	if err := pl.saveProviders(ctx, receivedProviders); err != nil {
-		logger.Error("Error saving providers", "error", err)
+	// Without the saved providers every provider would seem added.
+	if previous != nil {
+		pl.publishChanges(ctx, previous, receivedProviders)
 	}
+	return nil
 }
 
-func (pl *ProviderLister) saveProviders(ctx context.Context, providers []ProviderInfo) error {
-	logger := logging.Extract(ctx)
-	ctx, span := tracer.Start(ctx, "fcProviderLister.saveProviders")
-	defer span.End()
+// providerEvent is the data of the provider added and removed events.
+type providerEvent struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
 
-	logger.Info("Saving providers")
-	if err := pl.r.Del(ctx, storageKey).Err(); err != nil {
-		logger.Error("couldn't clear providers", "error", err)
-	}
+// publishChanges publishes an event for every provider that was added or removed compared to the
+// previously saved providers.
+func (pl *ProviderLister) publishChanges(ctx context.Context, previous map[string]string, providers []ProviderInfo) {
+	logger := logging.Extract(ctx)
+	var changes []events.Event
+	current := make(map[string]struct{}, len(providers))
 	for _, p := range providers {
-		jd, err := json.Marshal(p)
+		current[p.ID] = struct{}{}
+		if _, ok := previous[p.ID]; ok {
+			continue
+		}
+		event, err := events.New(events.TypeProviderAdded, "", providerEvent{ID: p.ID, Name: p.Name})
 		if err != nil {
-			return fmt.Errorf("couldn't marshal provider %s: %w", p.ID, err)
+			logger.Error("Couldn't create provider event", "error", err)
+			continue
+		}
+		changes = append(changes, event)
+	}
+	for id, data := range previous {
+		if _, ok := current[id]; ok {
+			continue
 		}
-		if err := pl.r.HSet(ctx, storageKey, p.ID, jd).Err(); err != nil {
-			return fmt.Errorf("couldn't save provider %s: %w", p.ID, err)
+		var p ProviderInfo
+		if err := json.Unmarshal([]byte(data), &p); err != nil {
+			logger.Error("Couldn't unmarshal saved provider", "error", err)
+		}
+		event, err := events.New(events.TypeProviderRemoved, "", providerEvent{ID: id, Name: p.Name})
+		if err != nil {
+			logger.Error("Couldn't create provider event", "error", err)
+			continue
+		}
+		changes = append(changes, event)
+	}
+	for _, event := range changes {
+		if err := pl.bus.Publish(ctx, event); err != nil {
+			logger.Error("Couldn't publish provider event", "error", err)
 		}
 	}
-	return nil
 }
 
 func normaliseProviders(
 	ctx context.Context,
 	p []ParticipantInfoWithVP,
-	piList []FCProviderInfo,
+	providerInfo map[string]FCProviderInfo,
+	details map[string]ProviderDetails,
 ) ([]ProviderInfo, error) {
 	logger := logging.Extract(ctx)
 
-	providerInfo := make(map[string]FCProviderInfo)
-	for _, data := range piList {
-		providerInfo[data.Provider] = data
-	}
-
 	n := make([]ProviderInfo, 0)
 	for _, rp := range p {
+		if len(rp.VerifiablePresentation.VerifiableCredential) == 0 {
+			logger.Info("Participant has no credentials", "participant", rp.ID)
+			continue
+		}
 		vc := rp.VerifiablePresentation.VerifiableCredential[0]
 
 		pi, exists := providerInfo[vc.CredentialSubject.LegalName]
@@ -231,6 +240,14 @@ func normaliseProviders(
 			return nil, fmt.Errorf("Failed to generate hash from ID %s", vc.CredentialSubject.ID)
 		}
 
+		own := DetailsFromSelfDescriptions(ctx, []FCSelfDescriptionsEntry{{Content: rp.SelfDescription}})
+		d := details[vc.CredentialSubject.ID].merge(own[vc.CredentialSubject.ID])
+
+		if rp.PublicKey != "" {
+			if _, err := providerkeys.Parse(rp.PublicKey); err != nil {
+				logger.Warn("Provider published an invalid key", "provider", vc.CredentialSubject.LegalName, "error", err)
+			}
+		}
 		n = append(n, ProviderInfo{
 			ID:                   fmt.Sprintf("%x", h.Sum(nil)),
 			Name:                 vc.CredentialSubject.LegalName,
@@ -240,7 +257,10 @@ func normaliseProviders(
 			Host:                 pi.Host,
 			Protocol:             pi.Protocol,
 			Provider:             pi.Provider,
-			Port:                 pi.Port,
+			Port:                 string(pi.Port),
+			Path:                 pi.Path,
+			AccessPoint:          pi.Name,
+			Details:              d,
 		})
 	}
 	return n, nil
@@ -0,0 +1,65 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fc
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// ed25519MulticodecPrefix is the varint multicodec prefix of an Ed25519 public key in a Multikey.
+var ed25519MulticodecPrefix = []byte{0xed, 0x01}
+
+// decodeMultibase decodes a base58btc multibase value, the only base used by the data integrity
+// cryptosuites and Multikeys.
+func decodeMultibase(value string) ([]byte, error) {
+	encoded, ok := strings.CutPrefix(value, "z")
+	if !ok {
+		return nil, errors.New("only base58btc multibase values are supported")
+	}
+	n := new(big.Int)
+	radix := big.NewInt(int64(len(base58Alphabet)))
+	for _, c := range encoded {
+		digit := strings.IndexRune(base58Alphabet, c)
+		if digit < 0 {
+			return nil, fmt.Errorf("invalid base58 character %q", c)
+		}
+		n.Mul(n, radix)
+		n.Add(n, big.NewInt(int64(digit)))
+	}
+	// Leading zero bytes are encoded as leading ones.
+	zeros := len(encoded) - len(strings.TrimLeft(encoded, "1"))
+	return append(make([]byte, zeros), n.Bytes()...), nil
+}
+
+// decodeMultikey returns the Ed25519 public key of a Multikey publicKeyMultibase value.
+func decodeMultikey(value string) (ed25519.PublicKey, error) {
+	decoded, err := decodeMultibase(value)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := bytes.CutPrefix(decoded, ed25519MulticodecPrefix)
+	if !ok || len(key) != ed25519.PublicKeySize {
+		return nil, errors.New("only Ed25519 Multikeys are supported")
+	}
+	return ed25519.PublicKey(key), nil
+}
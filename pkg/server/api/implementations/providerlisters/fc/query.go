@@ -0,0 +1,148 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"text/template"
+)
+
+// defaultQueryTemplate finds the access points of the providers, of the types in $accessPoints.
+const defaultQueryTemplate = "MATCH (provider:LegalParticipant) <-[:providedBy]- (offer:ServiceOffering) -[:aggregationOf]-> (s:SoftwareResource) <-[:instanceOf]- (rh:InstantiatedVirtualResource) -[:serviceAccessPoint]-> (access:ServiceAccessPoint) WHERE access.name IN $accessPoints return {provider: provider.legalName, name: access.name, protocol: access.protocol, port: access.port, host: access.host, path: access.path}" //nolint:lll
+
+// defaultPorts are the ports left out of provider URLs.
+var defaultPorts = map[string]string{"https": "443", "http": "80"}
+
+// Query is the query for the access points of the providers in the catalogue.
+type Query struct {
+	statement    string
+	accessPoints []string
+}
+
+// queryData is what the query template is rendered with.
+type queryData struct {
+	AccessPoints []string
+}
+
+// LoadQuery reads the query template from the file, or uses the default one if no file is given.
+// The template is rendered with the access point types, which are also passed to the query as
+// $accessPoints. Their order is the order of preference when a provider has several access points.
+func LoadQuery(file string, accessPoints []string) (*Query, error) {
+	if len(accessPoints) == 0 {
+		return nil, errors.New("at least one access point type is required")
+	}
+	text := defaultQueryTemplate
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't read catalogue query: %w", err)
+		}
+		text = string(data)
+	}
+	tmpl, err := template.New("query").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse catalogue query: %w", err)
+	}
+	var statement bytes.Buffer
+	if err := tmpl.Execute(&statement, queryData{AccessPoints: accessPoints}); err != nil {
+		return nil, fmt.Errorf("couldn't render catalogue query: %w", err)
+	}
+	s := strings.TrimSpace(statement.String())
+	if s == "" {
+		return nil, errors.New("catalogue query is empty")
+	}
+	return &Query{statement: s, accessPoints: accessPoints}, nil
+}
+
+// page returns the query for a page of the results.
+func (q *Query) page(offset, limit int) FCQuery {
+	return FCQuery{
+		Statement: q.statement + " SKIP $offset LIMIT $limit",
+		Parameters: map[string]any{
+			"accessPoints": q.accessPoints,
+			"offset":       offset,
+			"limit":        limit,
+		},
+	}
+}
+
+// selectAccessPoints returns the preferred access point of every provider, by legal name.
+// Access points of types that weren't asked for are only used if a provider has no other.
+func (q *Query) selectAccessPoints(piList []FCProviderInfo) map[string]FCProviderInfo {
+	preference := func(pi FCProviderInfo) int {
+		if i := slices.Index(q.accessPoints, pi.Name); i >= 0 {
+			return i
+		}
+		return len(q.accessPoints)
+	}
+	selected := make(map[string]FCProviderInfo)
+	for _, pi := range piList {
+		current, ok := selected[pi.Provider]
+		if !ok || preference(pi) < preference(current) {
+			selected[pi.Provider] = pi
+		}
+	}
+	return selected
+}
+
+// Port is the port of an access point, which the catalogue can return as number or string.
+type Port string
+
+func (p *Port) UnmarshalJSON(data []byte) error {
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err == nil {
+		*p = Port(n.String())
+		return nil
+	}
+	var s *string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("invalid port %s: %w", data, err)
+	}
+	if s != nil {
+		*p = Port(*s)
+	}
+	return nil
+}
+
+// URL returns the URL of the access point.
+func (pi FCProviderInfo) URL() string {
+	return endpointURL(pi.Protocol, pi.Host, string(pi.Port), pi.Path)
+}
+
+// endpointURL builds the URL of an endpoint, the port is left out if it is the default port of
+// the protocol.
+func endpointURL(protocol, host, port, path string) string {
+	scheme := strings.ToLower(protocol)
+	if scheme == "" {
+		scheme = "https"
+	}
+	if port != "" && port != defaultPorts[scheme] {
+		host = net.JoinHostPort(host, port)
+	}
+	if path != "" && !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	u := url.URL{Scheme: scheme, Host: host, Path: path}
+	return u.String()
+}
@@ -0,0 +1,55 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fc_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/implementations/providerlisters/fc"
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestAccessPointURL(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want string
+	}{
+		{name: "HostOnly", json: `{"protocol": "https", "host": "provider.example.com"}`, want: "https://provider.example.com"},
+		{name: "DefaultPort", json: `{"protocol": "https", "host": "provider.example.com", "port": 443}`, want: "https://provider.example.com"},       //nolint:lll
+		{name: "NumericPort", json: `{"protocol": "https", "host": "provider.example.com", "port": 8443}`, want: "https://provider.example.com:8443"}, //nolint:lll
+		{name: "StringPort", json: `{"protocol": "HTTP", "host": "provider.example.com", "port": "8080"}`, want: "http://provider.example.com:8080"},  //nolint:lll
+		{name: "Path", json: `{"protocol": "https", "host": "provider.example.com", "path": "dsp/v1"}`, want: "https://provider.example.com/dsp/v1"},  //nolint:lll
+		{name: "IPv6", json: `{"protocol": "https", "host": "::1", "port": 8443, "path": "/dsp"}`, want: "https://[::1]:8443/dsp"},                    //nolint:lll
+		{name: "NoProtocol", json: `{"host": "provider.example.com", "port": null}`, want: "https://provider.example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pi fc.FCProviderInfo
+			assert.NoError(t, json.Unmarshal([]byte(tt.json), &pi))
+			assert.Equal(t, tt.want, pi.URL())
+		})
+	}
+}
+
+func TestLoadQuery(t *testing.T) {
+	_, err := fc.LoadQuery("", nil)
+	assert.Error(t, err)
+
+	_, err = fc.LoadQuery("", []string{"dsp", "ids"})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,146 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/logging"
+)
+
+// Action is what a rule does with the providers it matches.
+type Action string
+
+const (
+	ActionInclude Action = "include"
+	ActionExclude Action = "exclude"
+)
+
+// Rules decide which providers of the catalogue are listed. The first rule that matches a
+// provider decides, providers no rule matches get the default action.
+type Rules struct {
+	Default Action `json:"default"`
+	Rules   []Rule `json:"rules"`
+}
+
+// Rule matches providers on all the fields that are set. Fields are glob patterns as supported by
+// path.Match, so "*.staging.example.com" matches every host of the staging dataspace.
+type Rule struct {
+	Action             Action `json:"action"`
+	LegalName          string `json:"legal_name,omitempty"`
+	CountryCode        string `json:"country_code,omitempty"`
+	RegistrationNumber string `json:"registration_number,omitempty"`
+	Issuer             string `json:"issuer,omitempty"`
+	Host               string `json:"host,omitempty"`
+}
+
+// LoadRules reads the rules from a JSON file, without a file all providers are included.
+func LoadRules(ctx context.Context, file string) (*Rules, error) {
+	if file == "" {
+		return &Rules{Default: ActionInclude}, nil
+	}
+	logging.Extract(ctx).Info("Loading provider rules", "file", file)
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read provider rules: %w", err)
+	}
+	var rules Rules
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("couldn't parse provider rules: %w", err)
+	}
+	if rules.Default == "" {
+		rules.Default = ActionInclude
+	}
+	if err := rules.validate(); err != nil {
+		return nil, err
+	}
+	return &rules, nil
+}
+
+func (r *Rules) validate() error {
+	if !validAction(r.Default) {
+		return fmt.Errorf("invalid default action %q", r.Default)
+	}
+	for i, rule := range r.Rules {
+		if !validAction(rule.Action) {
+			return fmt.Errorf("rule %d: invalid action %q", i, rule.Action)
+		}
+		for _, pattern := range rule.patterns() {
+			// The pattern is matched against an empty name only to check its syntax.
+			if _, err := path.Match(pattern, ""); err != nil {
+				return fmt.Errorf("rule %d: invalid pattern %q: %w", i, pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+func validAction(a Action) bool {
+	return a == ActionInclude || a == ActionExclude
+}
+
+// Check returns an error wrapping ErrProviderExcluded if the rules exclude the provider.
+func (r *Rules) Check(prov ProviderInfo) error {
+	action, reason := r.Default, "default action"
+	for i, rule := range r.Rules {
+		if rule.matches(prov) {
+			action, reason = rule.Action, fmt.Sprintf("rule %d", i)
+			break
+		}
+	}
+	if action == ActionExclude {
+		return fmt.Errorf("%w: %s excluded by %s", ErrProviderExcluded, prov.Name, reason)
+	}
+	return nil
+}
+
+func (rule Rule) patterns() []string {
+	var patterns []string
+	for _, p := range []string{rule.LegalName, rule.CountryCode, rule.RegistrationNumber, rule.Issuer, rule.Host} {
+		if p != "" {
+			patterns = append(patterns, p)
+		}
+	}
+	return patterns
+}
+
+func (rule Rule) matches(prov ProviderInfo) bool {
+	subject := prov.VerifiableCredential.CredentialSubject
+	return match(rule.LegalName, subject.LegalName) &&
+		match(rule.CountryCode, subject.HeadQuarterAddress.CountryCode, subject.LegalAddress.CountryCode) &&
+		match(rule.RegistrationNumber, subject.LegalRegistrationNumber.ID) &&
+		match(rule.Issuer, prov.VerifiableCredential.Issuer) &&
+		match(rule.Host, prov.Host)
+}
+
+// match reports whether the pattern matches any of the values, an empty pattern matches
+// everything.
+func match(pattern string, values ...string) bool {
+	if pattern == "" {
+		return true
+	}
+	for _, v := range values {
+		// The patterns are validated when the rules are loaded.
+		if ok, _ := path.Match(pattern, v); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fc_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/implementations/providerlisters/fc"
+	"github.com/alecthomas/assert/v2"
+)
+
+const rulesJSON = `{
+	"default": "exclude",
+	"rules": [
+		{"action": "exclude", "host": "*.staging.example.com"},
+		{"action": "include", "country_code": "DE"},
+		{"action": "include", "issuer": "did:web:notary.example.com", "registration_number": "HRB*"}
+	]
+}`
+
+func provider(name, host, country, registration, issuer string) fc.ProviderInfo {
+	p := fc.ProviderInfo{Name: name, Host: host}
+	p.VerifiableCredential.Issuer = issuer
+	p.VerifiableCredential.CredentialSubject.LegalName = name
+	p.VerifiableCredential.CredentialSubject.LegalAddress.CountryCode = country
+	p.VerifiableCredential.CredentialSubject.LegalRegistrationNumber.ID = registration
+	return p
+}
+
+func TestRules(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "rules.json")
+	assert.NoError(t, os.WriteFile(file, []byte(rulesJSON), 0o600))
+	rules, err := fc.LoadRules(context.Background(), file)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		provider fc.ProviderInfo
+		excluded bool
+	}{
+		{name: "Country", provider: provider("A", "a.example.com", "DE", "", "")},
+		{name: "StagingHost", provider: provider("B", "b.staging.example.com", "DE", "", ""), excluded: true},
+		{name: "IssuerAndRegistration", provider: provider("C", "c.example.com", "NL", "HRB 1234", "did:web:notary.example.com")},      //nolint:lll
+		{name: "OtherIssuer", provider: provider("D", "d.example.com", "NL", "HRB 1234", "did:web:other.example.com"), excluded: true}, //nolint:lll
+		{name: "Default", provider: provider("E", "e.example.com", "FR", "", ""), excluded: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := rules.Check(tt.provider)
+			if tt.excluded {
+				assert.IsError(t, err, fc.ErrProviderExcluded)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestLoadRulesInvalid(t *testing.T) {
+	for name, data := range map[string]string{
+		"Action":  `{"rules": [{"action": "hide"}]}`,
+		"Default": `{"default": "maybe"}`,
+		"Pattern": `{"rules": [{"action": "exclude", "host": "["}]}`,
+	} {
+		t.Run(name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "rules.json")
+			assert.NoError(t, os.WriteFile(file, []byte(data), 0o600))
+			_, err := fc.LoadRules(context.Background(), file)
+			assert.Error(t, err)
+		})
+	}
+}
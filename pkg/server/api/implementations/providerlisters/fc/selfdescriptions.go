@@ -0,0 +1,216 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/logging"
+)
+
+const (
+	legalParticipantType = "gx:LegalParticipant"
+	serviceOfferingType  = "gx:ServiceOffering"
+)
+
+// Fields of the credential subjects the provider details are taken from, the first one set is
+// used.
+var (
+	descriptionFields = []string{"gx:description", "schema:description", "dct:description"}
+	logoFields        = []string{"schema:logo", "gx:logo", "foaf:logo"}
+	contactFields     = []string{"gx:contactInformation", "schema:email", "vcard:email", "schema:telephone"}
+)
+
+// ProviderDetails are the details of a provider shown to the user, taken from the
+// self-descriptions in the catalogue.
+type ProviderDetails struct {
+	Description        string `json:"description,omitempty"`
+	LogoURI            string `json:"logoUri,omitempty"`
+	ContactInformation string `json:"contactInformation,omitempty"`
+}
+
+// merge fills the empty details from other.
+func (d ProviderDetails) merge(other ProviderDetails) ProviderDetails {
+	if d.Description == "" {
+		d.Description = other.Description
+	}
+	if d.LogoURI == "" {
+		d.LogoURI = other.LogoURI
+	}
+	if d.ContactInformation == "" {
+		d.ContactInformation = other.ContactInformation
+	}
+	return d
+}
+
+func (pl *ProviderLister) getSelfDescriptions(ctx context.Context) ([]FCSelfDescriptionsEntry, error) {
+	selfDescriptionsUrl := fmt.Sprintf("%s/%s", pl.catalogURL, selfDescriptionsPath)
+	logger := logging.Extract(ctx).With("self-descriptions-url", selfDescriptionsUrl)
+	ctx, span := tracer.Start(ctx, "fcProviderLister.getSelfDescriptions")
+	defer span.End()
+
+	logger.Info("Retrieving self-descriptions")
+	return getPages(ctx, func(ctx context.Context, offset, limit int) ([]FCSelfDescriptionsEntry, int64, error) {
+		pageUrl := fmt.Sprintf("%s?withContent=true&statuses=active&offset=%d&limit=%d",
+			selfDescriptionsUrl, offset, limit)
+		sdr := &FCSelfDescriptionResponse{}
+		if err := pl.doJSON(ctx, http.MethodGet, pageUrl, nil, sdr); err != nil {
+			return nil, 0, err
+		}
+		return sdr.Items, sdr.TotalCount, nil
+	})
+}
+
+// DetailsFromSelfDescriptions returns the details of the providers, by the ID of their legal
+// participant credential subject. Details of the legal participant take precedence over those of
+// the service offerings it provides. Self-descriptions that can't be parsed are skipped.
+func DetailsFromSelfDescriptions(
+	ctx context.Context,
+	entries []FCSelfDescriptionsEntry,
+) map[string]ProviderDetails {
+	logger := logging.Extract(ctx)
+	participants := map[string]ProviderDetails{}
+	offerings := map[string]ProviderDetails{}
+	for _, e := range entries {
+		content := e.Content
+		if content == "" {
+			content = e.Meta.Content
+		}
+		subjects, err := credentialSubjects(content)
+		if err != nil {
+			logger.Warn("Couldn't parse self-description", "id", e.Meta.ID, "error", err)
+			continue
+		}
+		for _, s := range subjects {
+			subjectTypes := stringValues(s["type"])
+			switch {
+			case slices.Contains(subjectTypes, legalParticipantType):
+				id := stringValue(s["id"])
+				participants[id] = participants[id].merge(subjectDetails(s))
+			case slices.Contains(subjectTypes, serviceOfferingType):
+				id := reference(s["gx:providedBy"])
+				offerings[id] = offerings[id].merge(subjectDetails(s))
+			}
+		}
+	}
+	for id, d := range offerings {
+		participants[id] = participants[id].merge(d)
+	}
+	delete(participants, "")
+	return participants
+}
+
+// credentialSubjects returns the credential subjects of the credentials in a verifiable
+// presentation.
+func credentialSubjects(content string) ([]map[string]any, error) {
+	var vp struct {
+		VerifiableCredential []struct {
+			CredentialSubject json.RawMessage `json:"credentialSubject"`
+		} `json:"verifiableCredential"`
+	}
+	if err := json.Unmarshal([]byte(content), &vp); err != nil {
+		return nil, err
+	}
+	var subjects []map[string]any
+	for _, vc := range vp.VerifiableCredential {
+		// A credential can have a single subject or a list of them.
+		var many []map[string]any
+		if err := json.Unmarshal(vc.CredentialSubject, &many); err == nil {
+			subjects = append(subjects, many...)
+			continue
+		}
+		var one map[string]any
+		if err := json.Unmarshal(vc.CredentialSubject, &one); err != nil {
+			return nil, fmt.Errorf("invalid credential subject: %w", err)
+		}
+		subjects = append(subjects, one)
+	}
+	return subjects, nil
+}
+
+func subjectDetails(subject map[string]any) ProviderDetails {
+	d := ProviderDetails{
+		Description:        firstField(subject, descriptionFields),
+		ContactInformation: firstField(subject, contactFields),
+	}
+	// Only web URLs are passed on to the app as logo.
+	if logo := firstField(subject, logoFields); logo != "" {
+		if u, err := url.Parse(logo); err == nil && (u.Scheme == "https" || u.Scheme == "http") && u.Host != "" {
+			d.LogoURI = logo
+		}
+	}
+	return d
+}
+
+func firstField(subject map[string]any, fields []string) string {
+	for _, f := range fields {
+		if v := stringValue(subject[f]); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// stringValue returns a JSON-LD value as string, either a plain string, a value object or a
+// reference to a node.
+func stringValue(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case map[string]any:
+		if s, ok := v["@value"].(string); ok {
+			return s
+		}
+		return reference(v)
+	case []any:
+		if len(v) > 0 {
+			return stringValue(v[0])
+		}
+	}
+	return ""
+}
+
+func stringValues(v any) []string {
+	if l, ok := v.([]any); ok {
+		values := make([]string, 0, len(l))
+		for _, e := range l {
+			values = append(values, stringValue(e))
+		}
+		return values
+	}
+	return []string{stringValue(v)}
+}
+
+// reference returns the ID of a node reference like {"id": "did:web:example.com"}.
+func reference(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case map[string]any:
+		if id, ok := v["id"].(string); ok {
+			return id
+		}
+		if id, ok := v["@id"].(string); ok {
+			return id
+		}
+	}
+	return ""
+}
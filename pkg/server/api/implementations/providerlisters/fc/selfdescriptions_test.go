@@ -0,0 +1,57 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fc_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/implementations/providerlisters/fc"
+	"github.com/alecthomas/assert/v2"
+)
+
+const (
+	participantSD = `{"verifiableCredential": [{"credentialSubject": {
+		"id": "did:web:provider.example.com",
+		"type": "gx:LegalParticipant",
+		"gx:description": "Hospital sharing heart rate data",
+		"schema:logo": "javascript:alert(1)"
+	}}]}`
+	offeringSD = `{"verifiableCredential": [{"credentialSubject": [{
+		"id": "https://provider.example.com/offering",
+		"type": ["gx:ServiceOffering"],
+		"gx:providedBy": {"id": "did:web:provider.example.com"},
+		"gx:description": {"@value": "Heart rate export"},
+		"schema:logo": "https://provider.example.com/logo.png",
+		"schema:email": "data@provider.example.com"
+	}]}]}`
+)
+
+func TestDetailsFromSelfDescriptions(t *testing.T) {
+	details := fc.DetailsFromSelfDescriptions(context.Background(), []fc.FCSelfDescriptionsEntry{
+		{Content: offeringSD},
+		{Meta: fc.FCSelfDescriptionsPart{ID: "broken"}, Content: "{"},
+		{Meta: fc.FCSelfDescriptionsPart{Content: participantSD}},
+	})
+
+	assert.Equal(t, map[string]fc.ProviderDetails{
+		"did:web:provider.example.com": {
+			Description:        "Hospital sharing heart rate data",
+			LogoURI:            "https://provider.example.com/logo.png",
+			ContactInformation: "data@provider.example.com",
+		},
+	}, details)
+}
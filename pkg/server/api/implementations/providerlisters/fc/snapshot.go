@@ -0,0 +1,123 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/logging"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// snapshotMetaKey holds the version of the current snapshot, when it was saved and how many
+	// providers the catalogue returned for it.
+	snapshotMetaKey = storageKey + ":meta"
+	// minSnapshotRatio is the share of the providers of the previous poll a poll has to return for
+	// its snapshot to replace the previous one.
+	minSnapshotRatio = 0.5
+	// maxSuspiciousPolls is how many polls in a row can return too few providers before their
+	// snapshot is accepted anyway, as the providers really left the catalogue.
+	maxSuspiciousPolls = 3
+)
+
+// SnapshotUpdatedAt returns when the providers were last saved from the catalogue, or the zero
+// time if they never were.
+func (pl *ProviderLister) SnapshotUpdatedAt(ctx context.Context) (time.Time, error) {
+	ctx, span := tracer.Start(ctx, "fcProviderLister.SnapshotUpdatedAt")
+	defer span.End()
+	updatedAt, err := pl.r.HGet(ctx, snapshotMetaKey, "updated_at").Int64()
+	if errors.Is(err, redis.Nil) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, fmt.Errorf("couldn't get snapshot time: %w", err)
+	}
+	return time.Unix(updatedAt, 0), nil
+}
+
+// keepSnapshot reports whether the last-known-good snapshot should be kept instead of saving the
+// providers of this poll, because the catalogue returned suspiciously few of them.
+func (pl *ProviderLister) keepSnapshot(ctx context.Context, received int) bool {
+	logger := logging.Extract(ctx)
+	previous, err := pl.r.HGet(ctx, snapshotMetaKey, "received").Int()
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			logger.Error("Couldn't get previous snapshot size", "error", err)
+		}
+		return false
+	}
+	if float64(received) >= minSnapshotRatio*float64(previous) {
+		pl.suspiciousPolls = 0
+		return false
+	}
+	pl.suspiciousPolls++
+	if pl.suspiciousPolls >= maxSuspiciousPolls {
+		logger.Warn("Catalogue keeps returning few providers, replacing snapshot",
+			"previous", previous, "received", received)
+		pl.suspiciousPolls = 0
+		return false
+	}
+	logger.Warn("Catalogue returned suspiciously few providers, keeping last snapshot",
+		"previous", previous, "received", received, "polls", pl.suspiciousPolls)
+	return true
+}
+
+// saveProviders writes the providers as a new version of the snapshot and swaps it in atomically,
+// so readers see either the previous or the new providers. Received is the number of providers the
+// catalogue returned, before any were excluded.
+func (pl *ProviderLister) saveProviders(ctx context.Context, providers []ProviderInfo, received int) error {
+	logger := logging.Extract(ctx)
+	ctx, span := tracer.Start(ctx, "fcProviderLister.saveProviders")
+	defer span.End()
+
+	values := make([]any, 0, 2*len(providers))
+	for _, p := range providers {
+		jd, err := json.Marshal(p)
+		if err != nil {
+			return fmt.Errorf("couldn't marshal provider %s: %w", p.ID, err)
+		}
+		values = append(values, p.ID, jd)
+	}
+	version, err := pl.r.HIncrBy(ctx, snapshotMetaKey, "version", 1).Result()
+	if err != nil {
+		return fmt.Errorf("couldn't get snapshot version: %w", err)
+	}
+	snapshotKey := fmt.Sprintf("%s:snapshot:%d", storageKey, version)
+
+	logger.Info("Saving providers", "version", version, "providers", len(providers))
+	_, err = pl.r.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if len(values) == 0 {
+			pipe.Del(ctx, storageKey)
+		} else {
+			pipe.HSet(ctx, snapshotKey, values...)
+			pipe.Rename(ctx, snapshotKey, storageKey)
+		}
+		pipe.HSet(ctx, snapshotMetaKey,
+			"updated_at", strconv.FormatInt(time.Now().Unix(), 10),
+			"received", received)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't save snapshot %d: %w", version, err)
+	}
+	return nil
+}
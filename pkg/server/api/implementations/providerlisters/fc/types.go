@@ -48,16 +48,21 @@ type ProviderInfo struct {
 	Protocol             string               `json:"protocol,omitempty"`
 	Provider             string               `json:"provider,omitempty"`
 	Port                 string               `json:"port,omitempty"`
+	Path                 string               `json:"path,omitempty"`
+	AccessPoint          string               `json:"accessPoint,omitempty"`
+	Details              ProviderDetails      `json:"details,omitempty"`
 }
 
 type FCQuery struct {
-	Statement string `json:"statement"`
+	Statement  string         `json:"statement"`
+	Parameters map[string]any `json:"parameters,omitempty"`
 }
 
 type VerifiablePresentation struct {
 	Context              []string               `json:"@context"`
 	ID                   string                 `json:"id"`
 	Type                 []string               `json:"type"`
+	Holder               string                 `json:"holder,omitempty"`
 	VerifiableCredential []VerifiableCredential `json:"verifiableCredential"`
 	Proof                Proof                  `json:"proof"`
 }
@@ -155,9 +160,12 @@ type FCSelfDescriptionResponse struct {
 	Items      []FCSelfDescriptionsEntry `json:"items,omitempty"`
 }
 
+// FCProviderInfo is an access point of a provider.
 type FCProviderInfo struct {
 	Host     string `json:"host,omitempty"`
 	Protocol string `json:"protocol,omitempty"`
 	Provider string `json:"provider,omitempty"`
-	Port     string `json:"port,omitempty"`
+	Port     Port   `json:"port,omitempty"`
+	Name     string `json:"name,omitempty"`
+	Path     string `json:"path,omitempty"`
 }
@@ -0,0 +1,286 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fc
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	_ "crypto/sha256" // Registers the hash of the P-256 and Ed25519 proofs.
+	_ "crypto/sha512" // Registers the hash of the P-384 proofs.
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"math/big"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+)
+
+const (
+	// proofType is the supported proof type, a data integrity proof of one of the JCS cryptosuites.
+	proofType = "DataIntegrityProof"
+	// dateLeeway allows for clock skew when checking the validity period of credentials.
+	dateLeeway = time.Minute
+)
+
+// jcsCryptosuites are the supported data integrity cryptosuites. Both canonicalise the proof
+// options and the document with the JSON Canonicalization Scheme.
+var jcsCryptosuites = []string{"eddsa-jcs-2022", "ecdsa-jcs-2019"}
+
+// rdfcProofTypes are proof types, and rdfcCryptosuites cryptosuites, that are computed over the
+// URDNA2015 canonical form of the JSON-LD document. Verifying them needs a JSON-LD processor, so
+// they are rejected rather than checked over a different canonical form.
+var (
+	rdfcProofTypes = []string{
+		"JsonWebSignature2020", "Ed25519Signature2018", "Ed25519Signature2020",
+		"EcdsaSecp256k1Signature2019", "RsaSignature2018", "BbsBlsSignature2020",
+	}
+	rdfcCryptosuites = []string{"eddsa-rdfc-2022", "ecdsa-rdfc-2019", "ecdsa-sd-2023", "bbs-2023"}
+)
+
+// KeyResolver returns the public key of a verification method.
+type KeyResolver func(ctx context.Context, verificationMethod string) (jose.JSONWebKey, error)
+
+// Verifier checks the verifiable presentations participants publish in the catalogue.
+type Verifier struct {
+	trustedIssuers []string
+	verifyProofs   bool
+	resolveKey     KeyResolver
+}
+
+// NewVerifier creates a verifier that only accepts credentials of the trusted issuers, or of any
+// issuer if none are given. Proofs are only checked if verifyProofs is set, a credential has to be
+// signed by its issuer and the presentation by its holder.
+func NewVerifier(trustedIssuers []string, verifyProofs bool, resolveKey KeyResolver) *Verifier {
+	return &Verifier{
+		trustedIssuers: trustedIssuers,
+		verifyProofs:   verifyProofs,
+		resolveKey:     resolveKey,
+	}
+}
+
+// Verify parses the verifiable presentation in the self-description of a participant and checks
+// the validity period and issuer of its credentials, and the proofs of the presentation and of
+// every credential. Participants that can't be trusted are rejected with ErrProviderExcluded.
+func (v *Verifier) Verify(ctx context.Context, selfDescription []byte) (VerifiablePresentation, error) {
+	var vp VerifiablePresentation
+	if err := json.Unmarshal(selfDescription, &vp); err != nil {
+		return VerifiablePresentation{}, err
+	}
+	if err := v.verify(ctx, selfDescription, vp); err != nil {
+		return VerifiablePresentation{}, fmt.Errorf("%w: %w", ErrProviderExcluded, err)
+	}
+	return vp, nil
+}
+
+func (v *Verifier) verify(ctx context.Context, selfDescription []byte, vp VerifiablePresentation) error {
+	if vp.Holder == "" {
+		return errors.New("presentation has no holder")
+	}
+	if len(vp.VerifiableCredential) == 0 {
+		return errors.New("presentation contains no credentials")
+	}
+	for i, vc := range vp.VerifiableCredential {
+		if err := v.checkCredential(vc, time.Now()); err != nil {
+			return fmt.Errorf("credential %d: %w", i, err)
+		}
+	}
+	if !v.verifyProofs {
+		return nil
+	}
+
+	// The proofs are checked over the document as published, not as parsed into our types.
+	dec := json.NewDecoder(bytes.NewReader(selfDescription))
+	dec.UseNumber()
+	var doc map[string]any
+	if err := dec.Decode(&doc); err != nil {
+		return fmt.Errorf("couldn't parse presentation: %w", err)
+	}
+	if err := v.verifyProof(ctx, doc, vp.Holder); err != nil {
+		return fmt.Errorf("presentation: %w", err)
+	}
+	credentials, _ := doc["verifiableCredential"].([]any)
+	for i, c := range credentials {
+		credential, ok := c.(map[string]any)
+		if !ok {
+			return fmt.Errorf("credential %d isn't an object", i)
+		}
+		if err := v.verifyProof(ctx, credential, vp.VerifiableCredential[i].Issuer); err != nil {
+			return fmt.Errorf("credential %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (v *Verifier) checkCredential(vc VerifiableCredential, now time.Time) error {
+	if vc.Issuer == "" {
+		return errors.New("no issuer")
+	}
+	if len(v.trustedIssuers) > 0 && !slices.Contains(v.trustedIssuers, vc.Issuer) {
+		return fmt.Errorf("issuer %q isn't trusted", vc.Issuer)
+	}
+	if vc.IssuanceDate.IsZero() {
+		return errors.New("no issuance date")
+	}
+	if vc.IssuanceDate.After(now.Add(dateLeeway)) {
+		return fmt.Errorf("not valid before %s", vc.IssuanceDate)
+	}
+	if expired(vc, now) {
+		return fmt.Errorf("expired at %s", vc.ExpirationDate)
+	}
+	return nil
+}
+
+func expired(vc VerifiableCredential, now time.Time) bool {
+	return !vc.ExpirationDate.IsZero() && vc.ExpirationDate.Before(now.Add(-dateLeeway))
+}
+
+// verifyProof checks the proof of a presentation or credential, whose key has to be one of the
+// verification methods of the controller.
+func (v *Verifier) verifyProof(ctx context.Context, doc map[string]any, controller string) error {
+	proof, ok := doc["proof"].(map[string]any)
+	if !ok {
+		return errors.New("no proof")
+	}
+	typ, _ := proof["type"].(string)
+	suite, _ := proof["cryptosuite"].(string)
+	switch {
+	case slices.Contains(rdfcProofTypes, typ):
+		return fmt.Errorf("proof type %q needs URDNA2015 canonicalization, which isn't supported", typ)
+	case typ == proofType && slices.Contains(rdfcCryptosuites, suite):
+		return fmt.Errorf("cryptosuite %q needs URDNA2015 canonicalization, which isn't supported", suite)
+	case typ != proofType:
+		return fmt.Errorf("unsupported proof type %q", typ)
+	case !slices.Contains(jcsCryptosuites, suite):
+		return fmt.Errorf("unsupported cryptosuite %q", suite)
+	}
+	if controller == "" {
+		return errors.New("no controller to check the verification method against")
+	}
+	method, _ := proof["verificationMethod"].(string)
+	if method != controller && !strings.HasPrefix(method, controller+"#") {
+		return fmt.Errorf("verification method %q isn't controlled by %s", method, controller)
+	}
+	proofValue, _ := proof["proofValue"].(string)
+	if proofValue == "" {
+		return errors.New("proof has no proof value")
+	}
+	signature, err := decodeMultibase(proofValue)
+	if err != nil {
+		return fmt.Errorf("invalid proof value: %w", err)
+	}
+
+	options := maps.Clone(proof)
+	delete(options, "proofValue")
+	unsigned := maps.Clone(doc)
+	delete(unsigned, "proof")
+	if proofContext, ok := options["@context"]; ok {
+		// The proof options may repeat the context of the document, and the document is then
+		// hashed with the context of the proof.
+		if !contextHasPrefix(unsigned["@context"], proofContext) {
+			return errors.New("proof context doesn't match the document context")
+		}
+		unsigned["@context"] = proofContext
+	}
+	key, err := v.resolveKey(ctx, method)
+	if err != nil {
+		return fmt.Errorf("couldn't get key of %s: %w", method, err)
+	}
+
+	switch key := key.Key.(type) {
+	case ed25519.PublicKey:
+		if suite != "eddsa-jcs-2022" {
+			return fmt.Errorf("%s proof with an Ed25519 key", suite)
+		}
+		payload, err := proofPayload(options, unsigned, crypto.SHA256)
+		if err != nil {
+			return err
+		}
+		if !ed25519.Verify(key, payload, signature) {
+			return errors.New("invalid proof")
+		}
+	case *ecdsa.PublicKey:
+		if suite != "ecdsa-jcs-2019" {
+			return fmt.Errorf("%s proof with an ECDSA key", suite)
+		}
+		var hash crypto.Hash
+		switch key.Curve {
+		case elliptic.P256():
+			hash = crypto.SHA256
+		case elliptic.P384():
+			hash = crypto.SHA384
+		default:
+			return fmt.Errorf("unsupported curve %s", key.Curve.Params().Name)
+		}
+		payload, err := proofPayload(options, unsigned, hash)
+		if err != nil {
+			return err
+		}
+		size := hash.Size()
+		if len(signature) != 2*size {
+			return errors.New("invalid proof")
+		}
+		digest := hash.New()
+		digest.Write(payload)
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(key, digest.Sum(nil), r, s) {
+			return errors.New("invalid proof")
+		}
+	default:
+		return fmt.Errorf("unsupported key type %T of %s", key, method)
+	}
+	return nil
+}
+
+// contextHasPrefix reports whether the JSON-LD context of a document starts with the context of
+// its proof.
+func contextHasPrefix(docContext, proofContext any) bool {
+	contextList := func(c any) []any {
+		if list, ok := c.([]any); ok {
+			return list
+		}
+		return []any{c}
+	}
+	doc, proof := contextList(docContext), contextList(proofContext)
+	return len(proof) <= len(doc) && reflect.DeepEqual(doc[:len(proof)], proof)
+}
+
+// proofPayload returns what the signature of a proof is computed over, the hash of the canonical
+// proof options followed by the hash of the canonical document without its proof.
+func proofPayload(options, doc map[string]any, hash crypto.Hash) ([]byte, error) {
+	canonicalOptions, err := canonicalJSON(options)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't canonicalise proof options: %w", err)
+	}
+	canonicalDoc, err := canonicalJSON(doc)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't canonicalise document: %w", err)
+	}
+	optionsHash := hash.New()
+	optionsHash.Write(canonicalOptions)
+	docHash := hash.New()
+	docHash.Write(canonicalDoc)
+	return docHash.Sum(optionsHash.Sum(nil)), nil
+}
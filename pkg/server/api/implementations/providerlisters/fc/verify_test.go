@@ -0,0 +1,348 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fc_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/implementations/providerlisters/fc"
+	"github.com/alecthomas/assert/v2"
+	"github.com/go-jose/go-jose/v4"
+)
+
+const (
+	issuerDID      = "did:web:notary.example.com"
+	participantDID = "did:web:provider.example.com"
+)
+
+// signer signs documents the way participants do, compact JSON with sorted keys is already
+// canonical for these documents.
+type signer struct {
+	t    *testing.T
+	keys map[string]*ecdsa.PrivateKey
+}
+
+func newSigner(t *testing.T, methods ...string) *signer {
+	t.Helper()
+	s := &signer{t: t, keys: map[string]*ecdsa.PrivateKey{}}
+	for _, m := range methods {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		assert.NoError(t, err)
+		s.keys[m] = key
+	}
+	return s
+}
+
+func (s *signer) resolve(_ context.Context, method string) (jose.JSONWebKey, error) {
+	key, ok := s.keys[method]
+	if !ok {
+		return jose.JSONWebKey{}, fmt.Errorf("unknown verification method %s", method)
+	}
+	return jose.JSONWebKey{Key: &key.PublicKey}, nil
+}
+
+func (s *signer) sign(doc map[string]any, method string) map[string]any {
+	s.t.Helper()
+	proof := map[string]any{
+		"type":               "DataIntegrityProof",
+		"cryptosuite":        "ecdsa-jcs-2019",
+		"created":            "2025-01-01T00:00:00Z",
+		"proofPurpose":       "assertionMethod",
+		"verificationMethod": method,
+	}
+	options, err := json.Marshal(proof)
+	assert.NoError(s.t, err)
+	body, err := json.Marshal(doc)
+	assert.NoError(s.t, err)
+	optionsHash := sha256.Sum256(options)
+	bodyHash := sha256.Sum256(body)
+	digest := sha256.Sum256(append(optionsHash[:], bodyHash[:]...))
+
+	r, sv, err := ecdsa.Sign(rand.Reader, s.keys[method], digest[:])
+	assert.NoError(s.t, err)
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	sv.FillBytes(signature[32:])
+	proof["proofValue"] = base58btc(signature)
+
+	signed := map[string]any{"proof": proof}
+	for k, v := range doc {
+		signed[k] = v
+	}
+	return signed
+}
+
+// base58btc encodes a value as a base58btc multibase string.
+func base58btc(data []byte) string {
+	const alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+	n := new(big.Int).SetBytes(data)
+	radix, digit := big.NewInt(58), new(big.Int)
+	var encoded []byte
+	for n.Sign() > 0 {
+		n.DivMod(n, radix, digit)
+		encoded = append(encoded, alphabet[digit.Int64()])
+	}
+	for _, b := range data {
+		if b != 0 {
+			break
+		}
+		encoded = append(encoded, '1')
+	}
+	slices.Reverse(encoded)
+	return "z" + string(encoded)
+}
+
+// withProof replaces fields of the proof of a signed document.
+func withProof(doc map[string]any, fields map[string]any) map[string]any {
+	proof := maps.Clone(doc["proof"].(map[string]any))
+	maps.Copy(proof, fields)
+	changed := maps.Clone(doc)
+	changed["proof"] = proof
+	return changed
+}
+
+func credential(issuanceDate, expirationDate time.Time) map[string]any {
+	return map[string]any{
+		"type":           []any{"VerifiableCredential"},
+		"id":             "https://provider.example.com/credential",
+		"issuer":         issuerDID,
+		"issuanceDate":   issuanceDate.Format(time.RFC3339),
+		"expirationDate": expirationDate.Format(time.RFC3339),
+		"credentialSubject": map[string]any{
+			"id":           participantDID,
+			"gx:legalName": "Provider",
+		},
+	}
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Now()
+	valid := credential(now.Add(-time.Hour), now.Add(time.Hour))
+	s := newSigner(t, issuerDID+"#key-1", participantDID+"#key-1")
+	presentation := func(credentials ...map[string]any) map[string]any {
+		vcs := make([]any, len(credentials))
+		for i, c := range credentials {
+			vcs[i] = c
+		}
+		return map[string]any{
+			"type":                 []any{"VerifiablePresentation"},
+			"holder":               participantDID,
+			"verifiableCredential": vcs,
+		}
+	}
+	withoutHolder := presentation(s.sign(valid, issuerDID+"#key-1"))
+	delete(withoutHolder, "holder")
+	withoutIssuer := maps.Clone(valid)
+	delete(withoutIssuer, "issuer")
+
+	tampered := s.sign(valid, issuerDID+"#key-1")
+	tampered["credentialSubject"] = map[string]any{"id": participantDID, "gx:legalName": "Forged"}
+
+	tests := []struct {
+		name           string
+		vp             map[string]any
+		trustedIssuers []string
+		verifyProofs   bool
+		excluded       bool
+	}{
+		{
+			name: "Valid",
+			vp: s.sign(presentation(
+				s.sign(valid, issuerDID+"#key-1"),
+			), participantDID+"#key-1"),
+			trustedIssuers: []string{issuerDID},
+			verifyProofs:   true,
+		},
+		{
+			name:           "NoProofsWithoutVerification",
+			vp:             presentation(valid),
+			trustedIssuers: []string{issuerDID},
+		},
+		{
+			name:         "NoProof",
+			vp:           s.sign(presentation(valid), participantDID+"#key-1"),
+			verifyProofs: true,
+			excluded:     true,
+		},
+		{
+			name: "Tampered",
+			vp: s.sign(presentation(
+				tampered,
+			), participantDID+"#key-1"),
+			verifyProofs: true,
+			excluded:     true,
+		},
+		{
+			name: "SignedByOtherThanIssuer",
+			vp: s.sign(presentation(
+				s.sign(valid, participantDID+"#key-1"),
+			), participantDID+"#key-1"),
+			verifyProofs: true,
+			excluded:     true,
+		},
+		{
+			name: "SignedByOtherThanHolder",
+			vp: s.sign(presentation(
+				s.sign(valid, issuerDID+"#key-1"),
+			), issuerDID+"#key-1"),
+			verifyProofs: true,
+			excluded:     true,
+		},
+		{
+			name: "JsonWebSignature2020",
+			vp: s.sign(presentation(
+				withProof(s.sign(valid, issuerDID+"#key-1"), map[string]any{"type": "JsonWebSignature2020"}),
+			), participantDID+"#key-1"),
+			verifyProofs: true,
+			excluded:     true,
+		},
+		{
+			name: "RDFCCryptosuite",
+			vp: s.sign(presentation(
+				withProof(s.sign(valid, issuerDID+"#key-1"), map[string]any{"cryptosuite": "ecdsa-rdfc-2019"}),
+			), participantDID+"#key-1"),
+			verifyProofs: true,
+			excluded:     true,
+		},
+		{
+			name:         "NoHolder",
+			vp:           s.sign(withoutHolder, participantDID+"#key-1"),
+			verifyProofs: true,
+			excluded:     true,
+		},
+		{
+			name:     "NoHolderWithoutVerification",
+			vp:       withoutHolder,
+			excluded: true,
+		},
+		{
+			name:     "NoIssuer",
+			vp:       presentation(withoutIssuer),
+			excluded: true,
+		},
+		{
+			name:           "UntrustedIssuer",
+			vp:             presentation(valid),
+			trustedIssuers: []string{"did:web:other.example.com"},
+			excluded:       true,
+		},
+		{
+			name:     "Expired",
+			vp:       presentation(credential(now.Add(-2*time.Hour), now.Add(-time.Hour))),
+			excluded: true,
+		},
+		{
+			name:     "NotYetValid",
+			vp:       presentation(credential(now.Add(time.Hour), now.Add(2*time.Hour))),
+			excluded: true,
+		},
+		{
+			name:     "NoCredentials",
+			vp:       presentation(),
+			excluded: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.vp)
+			assert.NoError(t, err)
+			v := fc.NewVerifier(tt.trustedIssuers, tt.verifyProofs, s.resolve)
+
+			vp, err := v.Verify(context.Background(), data)
+			if tt.excluded {
+				assert.IsError(t, err, fc.ErrProviderExcluded)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "Provider", vp.VerifiableCredential[0].CredentialSubject.LegalName)
+		})
+	}
+}
+
+// testdataTransport serves the DID documents in testdata for did:web DIDs on example.com.
+type testdataTransport struct{}
+
+func (testdataTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host, ok := strings.CutSuffix(req.URL.Host, ".example.com")
+	if !ok || req.URL.Path != "/.well-known/did.json" {
+		return &http.Response{StatusCode: http.StatusNotFound, Body: http.NoBody, Request: req}, nil
+	}
+	data, err := os.ReadFile(filepath.Join("testdata", host+".did.json"))
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(data)), Request: req}, nil
+}
+
+// TestVerifyFixture verifies a presentation with eddsa-jcs-2022 proofs made with the Ed25519 test
+// key pair published in the W3C Data Integrity EdDSA Cryptosuites specification, resolving the
+// Multikey of the verification methods from their DID documents.
+func TestVerifyFixture(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "presentation.json"))
+	assert.NoError(t, err)
+	resolver := fc.NewDIDWebResolver(&http.Client{Transport: testdataTransport{}})
+	v := fc.NewVerifier([]string{issuerDID}, true, resolver.ResolveKey)
+
+	vp, err := v.Verify(context.Background(), data)
+	assert.NoError(t, err)
+	assert.Equal(t, "Provider", vp.VerifiableCredential[0].CredentialSubject.LegalName)
+
+	tampered := bytes.Replace(data, []byte(`"Provider"`), []byte(`"Forged"`), 1)
+	_, err = v.Verify(context.Background(), tampered)
+	assert.IsError(t, err, fc.ErrProviderExcluded)
+}
+
+func TestDIDWebURL(t *testing.T) {
+	tests := []struct {
+		did     string
+		want    string
+		wantErr bool
+	}{
+		{did: "did:web:example.com", want: "https://example.com/.well-known/did.json"},
+		{did: "did:web:example.com%3A3000", want: "https://example.com:3000/.well-known/did.json"},
+		{did: "did:web:example.com:user:alice", want: "https://example.com/user/alice/did.json"},
+		{did: "did:key:z6Mkf5rGMoatrSj1f4CyvuHBeXJELe9RPdzo2PKGNCKVtZxP", wantErr: true},
+		{did: "did:web:", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.did, func(t *testing.T) {
+			got, err := fc.DIDWebURL(tt.did)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
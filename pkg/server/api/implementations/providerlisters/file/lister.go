@@ -0,0 +1,238 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package file contains a provider lister that reads the providers from a YAML or JSON file, for
+// dataspaces without a federated catalogue, or providers not registered in it yet.
+package file
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"sync/atomic"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/logging"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/providerkeys"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/types"
+	"github.com/go-jose/go-jose/v4"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"gopkg.in/yaml.v3"
+)
+
+var tracer trace.Tracer
+
+func init() {
+	tracer = otel.Tracer(
+		"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/implementations/providerlisters/file",
+	)
+}
+
+// fileContent is the content of the provider file. JSON is read as YAML, which it is a subset of.
+type fileContent struct {
+	Providers []providerEntry `yaml:"providers"`
+}
+
+type providerEntry struct {
+	// ID defaults to the SHA-256 of the URL.
+	ID                 string `yaml:"id"`
+	Name               string `yaml:"name"`
+	Description        string `yaml:"description"`
+	LogoURI            string `yaml:"logo_uri"`
+	ContactInformation string `yaml:"contact_information"`
+	URL                string `yaml:"url"`
+	// PublicKeys are JWKs, as objects or as base64 encoded JSON, current key first.
+	PublicKeys []any `yaml:"public_keys"`
+}
+
+type provider struct {
+	types.Provider
+	keys []jose.JSONWebKey
+}
+
+// ProviderLister lists the providers in a file.
+type ProviderLister struct {
+	file   string
+	strict bool
+	// providers is swapped when the file is reloaded.
+	providers atomic.Pointer[[]provider]
+}
+
+// New creates a provider lister for the providers in the file. Invalid public keys are logged
+// and skipped, or fail loading if strict is set.
+func New(ctx context.Context, file string, strict bool) (*ProviderLister, error) {
+	if file == "" {
+		return nil, errors.New("provider file is required")
+	}
+	pl := &ProviderLister{file: file, strict: strict}
+	providers, err := pl.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pl.providers.Store(&providers)
+	return pl, nil
+}
+
+// Reload reads the file again and swaps in its providers. If the file can't be loaded the current
+// providers are kept.
+func (pl *ProviderLister) Reload(ctx context.Context) error {
+	providers, err := pl.load(ctx)
+	if err != nil {
+		return err
+	}
+	pl.providers.Store(&providers)
+	logging.Extract(ctx).Info("Providers reloaded", "file", pl.file, "providers", len(providers))
+	return nil
+}
+
+// ListProviders returns all providers in the file.
+func (pl *ProviderLister) ListProviders(ctx context.Context) ([]types.Provider, error) {
+	logger := logging.Extract(ctx)
+	logger.Info("Listing providers")
+	_, span := tracer.Start(ctx, "fileProviderLister.ListProviders")
+	defer span.End()
+	providers := *pl.providers.Load()
+	list := make([]types.Provider, len(providers))
+	for i, p := range providers {
+		list[i] = p.Provider
+	}
+	return list, nil
+}
+
+// GetProvider returns the provider with the given ID.
+func (pl *ProviderLister) GetProvider(ctx context.Context, providerID string) (types.Provider, error) {
+	logger := logging.Extract(ctx)
+	logger.Info("Getting single provider")
+	_, span := tracer.Start(ctx, "fileProviderLister.GetProvider")
+	defer span.End()
+	p, err := pl.provider(providerID)
+	return p.Provider, err
+}
+
+// GetProviderURL returns the provider URL for the given provider ID.
+func (pl *ProviderLister) GetProviderURL(ctx context.Context, providerID string) (string, error) {
+	logger := logging.Extract(ctx)
+	logger.Info("Getting provider URL")
+	_, span := tracer.Start(ctx, "fileProviderLister.GetProviderURL")
+	defer span.End()
+	p, err := pl.provider(providerID)
+	return p.ProviderUrl, err
+}
+
+// GetProviderKeys returns the public keys of the provider in the file.
+func (pl *ProviderLister) GetProviderKeys(ctx context.Context, providerID string) ([]jose.JSONWebKey, error) {
+	logger := logging.Extract(ctx)
+	logger.Info("Getting provider keys")
+	_, span := tracer.Start(ctx, "fileProviderLister.GetProviderKeys")
+	defer span.End()
+	p, err := pl.provider(providerID)
+	return p.keys, err
+}
+
+func (pl *ProviderLister) provider(providerID string) (provider, error) {
+	for _, p := range *pl.providers.Load() {
+		if p.ID == providerID {
+			return p, nil
+		}
+	}
+	return provider{}, fmt.Errorf("%w: provider not found", types.ErrNotFound)
+}
+
+func (pl *ProviderLister) load(ctx context.Context) ([]provider, error) {
+	logger := logging.Extract(ctx)
+	logger.Info("Loading providers", "file", pl.file)
+	data, err := os.ReadFile(pl.file)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read provider file: %w", err)
+	}
+	var content fileContent
+	if err := yaml.Unmarshal(data, &content); err != nil {
+		return nil, fmt.Errorf("couldn't parse provider file: %w", err)
+	}
+
+	providers := make([]provider, 0, len(content.Providers))
+	seen := map[string]bool{}
+	for i, e := range content.Providers {
+		p, err := pl.convertEntry(ctx, e)
+		if err != nil {
+			return nil, fmt.Errorf("provider %d: %w", i, err)
+		}
+		if seen[p.ID] {
+			return nil, fmt.Errorf("provider %d: duplicate id %q", i, p.ID)
+		}
+		seen[p.ID] = true
+		providers = append(providers, p)
+	}
+	return providers, nil
+}
+
+func (pl *ProviderLister) convertEntry(ctx context.Context, e providerEntry) (provider, error) {
+	logger := logging.Extract(ctx)
+	if e.Name == "" {
+		return provider{}, errors.New("name is required")
+	}
+	u, err := url.Parse(e.URL)
+	if err != nil || !u.IsAbs() || u.Host == "" {
+		return provider{}, fmt.Errorf("invalid url %q", e.URL)
+	}
+	id := e.ID
+	if id == "" {
+		id = fmt.Sprintf("%x", sha256.Sum256([]byte(e.URL)))
+	}
+
+	p := provider{Provider: types.Provider{
+		ID:                 id,
+		Name:               e.Name,
+		Description:        e.Description,
+		LogoURI:            e.LogoURI,
+		ContactInformation: e.ContactInformation,
+		ProviderUrl:        e.URL,
+	}}
+	for i, k := range e.PublicKeys {
+		key, err := parseKey(k)
+		if err != nil {
+			if pl.strict {
+				return provider{}, fmt.Errorf("key %d: %w", i, err)
+			}
+			logger.Warn("Skipping invalid provider key", "provider", e.Name, "key", i, "error", err)
+			continue
+		}
+		p.keys = append(p.keys, key)
+	}
+	if len(p.keys) > 0 {
+		p.PublicKey, err = providerkeys.Encode(p.keys[0])
+		if err != nil {
+			return provider{}, err
+		}
+		p.PublicKeySource = types.KeySourceFile
+	}
+	return p, nil
+}
+
+// parseKey parses a key given as YAML or JSON object, or as string.
+func parseKey(k any) (jose.JSONWebKey, error) {
+	if s, ok := k.(string); ok {
+		return providerkeys.Parse(s)
+	}
+	data, err := json.Marshal(k)
+	if err != nil {
+		return jose.JSONWebKey{}, fmt.Errorf("%w: %w", providerkeys.ErrInvalidKey, err)
+	}
+	return providerkeys.Parse(string(data))
+}
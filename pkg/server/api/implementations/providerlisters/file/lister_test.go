@@ -0,0 +1,118 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package file_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	plfile "github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/implementations/providerlisters/file"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/types"
+	"github.com/alecthomas/assert/v2"
+	"github.com/go-jose/go-jose/v4"
+)
+
+func publicKeyJSON(t *testing.T, kid string) string {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	data, err := json.Marshal(jose.JSONWebKey{
+		Key:       &key.PublicKey,
+		KeyID:     kid,
+		Use:       "enc",
+		Algorithm: string(jose.RSA_OAEP_256),
+	})
+	assert.NoError(t, err)
+	return string(data)
+}
+
+func TestProviderLister(t *testing.T) {
+	ctx := context.Background()
+	file := filepath.Join(t.TempDir(), "providers.yaml")
+	content := fmt.Sprintf(`providers:
+  - id: hospital-a
+    name: Hospital A
+    description: Pilot hospital
+    url: https://dsp.hospital-a.example.com
+    public_keys:
+      - %s
+  - name: Hospital B
+    url: https://dsp.hospital-b.example.com:8443/dsp
+`, publicKeyJSON(t, "a-1"))
+	assert.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+
+	pl, err := plfile.New(ctx, file, true)
+	assert.NoError(t, err)
+
+	providers, err := pl.ListProviders(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(providers))
+	assert.Equal(t, "hospital-a", providers[0].ID)
+	assert.Equal(t, types.KeySourceFile, providers[0].PublicKeySource)
+	assert.NotEqual(t, "", providers[0].PublicKey)
+	assert.Equal(t, "", providers[1].PublicKey)
+
+	keys, err := pl.GetProviderKeys(ctx, "hospital-a")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(keys))
+	assert.Equal(t, "a-1", keys[0].KeyID)
+
+	url, err := pl.GetProviderURL(ctx, providers[1].ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://dsp.hospital-b.example.com:8443/dsp", url)
+
+	_, err = pl.GetProvider(ctx, "unknown")
+	assert.IsError(t, err, types.ErrNotFound)
+
+	// A broken file keeps the current providers.
+	assert.NoError(t, os.WriteFile(file, []byte("providers:\n  - name: No URL\n"), 0o600))
+	assert.Error(t, pl.Reload(ctx))
+	providers, err = pl.ListProviders(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(providers))
+
+	assert.NoError(t, os.WriteFile(file, []byte(`{"providers": [{"name": "C", "url": "https://c.example.com"}]}`), 0o600))
+	assert.NoError(t, pl.Reload(ctx))
+	providers, err = pl.ListProviders(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(providers))
+	assert.Equal(t, "C", providers[0].Name)
+}
+
+func TestProviderListerInvalidKey(t *testing.T) {
+	ctx := context.Background()
+	file := filepath.Join(t.TempDir(), "providers.json")
+	content := `{"providers": [{"name": "A", "url": "https://a.example.com", "public_keys": [{"kty": "oct", "k": "c2VjcmV0"}]}]}`
+	assert.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+
+	_, err := plfile.New(ctx, file, true)
+	assert.Error(t, err)
+
+	pl, err := plfile.New(ctx, file, false)
+	assert.NoError(t, err)
+	providers, err := pl.ListProviders(ctx)
+	assert.NoError(t, err)
+	keys, err := pl.GetProviderKeys(ctx, providers[0].ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(keys))
+	assert.Equal(t, "", providers[0].PublicKeySource)
+}
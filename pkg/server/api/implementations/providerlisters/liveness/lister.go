@@ -0,0 +1,317 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package liveness contains a provider lister that periodically probes the DSP endpoints of the
+// providers of another lister, and adds whether they are reachable to the providers it lists.
+package liveness
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/leader"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/logging"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/types"
+	"github.com/go-jose/go-jose/v4"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	storageKey = "providers:liveness"
+	// versionPath is the DSP version metadata endpoint, every DSP connector serves it at the root
+	// of its host.
+	versionPath = "/.well-known/dspace-version"
+	// maxFailures is the number of failed probes in a row after which a provider is down.
+	maxFailures = 3
+	// maxParallelProbes is the number of providers probed at the same time.
+	maxParallelProbes = 10
+)
+
+var tracer trace.Tracer
+
+func init() {
+	tracer = otel.Tracer(
+		"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/implementations/providerlisters/liveness",
+	)
+}
+
+// ProviderLister lists the providers of the inner lister with their status. Providers that are
+// down can be hidden from the list, they can still be looked up by ID.
+type ProviderLister struct {
+	inner    types.ProviderLister
+	r        *redis.Client
+	client   *http.Client
+	hideDown bool
+	// elector decides whether this replica probes the providers, the others read the liveness the
+	// leader stored.
+	elector *leader.Elector
+}
+
+// state is the stored liveness of a provider.
+type state struct {
+	Status    types.ProviderStatus `json:"status"`
+	LastSeen  *time.Time           `json:"last_seen,omitempty"`
+	Failures  int                  `json:"failures"`
+	CheckedAt time.Time            `json:"checked_at"`
+}
+
+// New creates a liveness provider lister, the providers of the inner lister are probed with
+// ProbeProviders.
+func New(
+	inner types.ProviderLister,
+	redisClient *redis.Client,
+	client *http.Client,
+	hideDown bool,
+	elector *leader.Elector,
+) *ProviderLister {
+	return &ProviderLister{
+		inner:    inner,
+		r:        redisClient,
+		client:   client,
+		hideDown: hideDown,
+		elector:  elector,
+	}
+}
+
+// ListProviders returns the providers of the inner lister with their status, without the
+// providers that are down if those are hidden.
+func (pl *ProviderLister) ListProviders(ctx context.Context) ([]types.Provider, error) {
+	logger := logging.Extract(ctx)
+	logger.Info("Listing providers")
+	ctx, span := tracer.Start(ctx, "livenessProviderLister.ListProviders")
+	defer span.End()
+	providers, err := pl.inner.ListProviders(ctx)
+	if err != nil {
+		return nil, err
+	}
+	states, err := pl.loadStates(ctx)
+	if err != nil {
+		// The status is informational, so the providers are still listed without it.
+		logger.Error("Couldn't get provider liveness", "error", err)
+		return providers, nil
+	}
+	listed := make([]types.Provider, 0, len(providers))
+	for _, p := range providers {
+		p = annotate(p, states)
+		if pl.hideDown && p.Status == types.ProviderStatusDown {
+			continue
+		}
+		listed = append(listed, p)
+	}
+	return listed, nil
+}
+
+// GetProvider returns the provider with the given ID with its status.
+func (pl *ProviderLister) GetProvider(ctx context.Context, providerID string) (types.Provider, error) {
+	logger := logging.Extract(ctx)
+	logger.Info("Getting single provider")
+	ctx, span := tracer.Start(ctx, "livenessProviderLister.GetProvider")
+	defer span.End()
+	p, err := pl.inner.GetProvider(ctx, providerID)
+	if err != nil {
+		return types.Provider{}, err
+	}
+	states, err := pl.loadStates(ctx)
+	if err != nil {
+		logger.Error("Couldn't get provider liveness", "error", err)
+		return p, nil
+	}
+	return annotate(p, states), nil
+}
+
+// GetProviderURL returns the URL of the provider with the given ID.
+func (pl *ProviderLister) GetProviderURL(ctx context.Context, providerID string) (string, error) {
+	return pl.inner.GetProviderURL(ctx, providerID)
+}
+
+// GetProviderKeys returns the public keys of the provider with the given ID.
+func (pl *ProviderLister) GetProviderKeys(ctx context.Context, providerID string) ([]jose.JSONWebKey, error) {
+	return pl.inner.GetProviderKeys(ctx, providerID)
+}
+
+// SnapshotUpdatedAt returns when the snapshot of the inner lister was last updated, or the zero
+// time if it doesn't list providers from a snapshot.
+func (pl *ProviderLister) SnapshotUpdatedAt(ctx context.Context) (time.Time, error) {
+	s, ok := pl.inner.(types.ProviderSnapshotter)
+	if !ok {
+		return time.Time{}, nil
+	}
+	return s.SnapshotUpdatedAt(ctx)
+}
+
+// Probe checks whether the DSP endpoint at providerURL is reachable. Any response that isn't a
+// server error counts, as connectors may require authentication for the version metadata.
+func Probe(ctx context.Context, client *http.Client, providerURL string) error {
+	u, err := url.Parse(providerURL)
+	if err != nil {
+		return fmt.Errorf("couldn't parse provider URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("unsupported provider URL scheme %q", u.Scheme)
+	}
+	u.Path = versionPath
+	u.RawQuery = ""
+	u.Fragment = ""
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return fmt.Errorf("couldn't create request: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("provider returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// ProbeProviders probes all providers of the inner lister and stores their liveness, it is only
+// done by the leader. The liveness of providers that are no longer listed is removed.
+func (pl *ProviderLister) ProbeProviders(ctx context.Context) error {
+	logger := logging.Extract(ctx)
+	if !pl.elector.IsLeader() {
+		logger.Debug("Not the leader, skipping probes")
+		return nil
+	}
+	ctx, span := tracer.Start(ctx, "livenessProviderLister.ProbeProviders")
+	defer span.End()
+
+	providers, err := pl.inner.ListProviders(ctx)
+	if err != nil {
+		return fmt.Errorf("couldn't list providers to probe: %w", err)
+	}
+	states, err := pl.loadStates(ctx)
+	if err != nil {
+		return err
+	}
+
+	probed := make([]types.Provider, 0, len(providers))
+	for _, p := range providers {
+		if p.ProviderUrl != "" {
+			probed = append(probed, p)
+		}
+	}
+	results := make([]error, len(probed))
+	sem := make(chan struct{}, maxParallelProbes)
+	var wg sync.WaitGroup
+	for i, p := range probed {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = Probe(ctx, pl.client, p.ProviderUrl)
+		}()
+	}
+	wg.Wait()
+
+	now := time.Now().UTC()
+	values := []any{}
+	for i, p := range probed {
+		s := states[p.ID]
+		s.CheckedAt = now
+		if results[i] == nil {
+			if s.Status == types.ProviderStatusDown {
+				logger.Info("Provider is up again", "provider_id", p.ID)
+			}
+			s.Status = types.ProviderStatusUp
+			s.LastSeen = &now
+			s.Failures = 0
+		} else {
+			s.Failures++
+			logger.Warn("Provider probe failed", "provider_id", p.ID, "failures", s.Failures, "error", results[i])
+			switch {
+			case s.Failures >= maxFailures:
+				s.Status = types.ProviderStatusDown
+			case s.Status == "":
+				s.Status = types.ProviderStatusUnknown
+			}
+		}
+		data, err := json.Marshal(s)
+		if err != nil {
+			logger.Error("Couldn't marshal provider liveness", "provider_id", p.ID, "error", err)
+			continue
+		}
+		values = append(values, p.ID, data)
+	}
+
+	listed := make(map[string]bool, len(providers))
+	for _, p := range providers {
+		listed[p.ID] = true
+	}
+	removed := []string{}
+	for id := range states {
+		if !listed[id] {
+			removed = append(removed, id)
+		}
+	}
+
+	_, err = pl.r.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		if len(values) > 0 {
+			if err := pipe.HSet(ctx, storageKey, values...).Err(); err != nil {
+				return err
+			}
+		}
+		if len(removed) > 0 {
+			return pipe.HDel(ctx, storageKey, removed...).Err()
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't save provider liveness: %w", err)
+	}
+	return nil
+}
+
+// loadStates returns the stored liveness by provider ID.
+func (pl *ProviderLister) loadStates(ctx context.Context) (map[string]state, error) {
+	data, err := pl.r.HGetAll(ctx, storageKey).Result()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, fmt.Errorf("couldn't get provider liveness: %w", err)
+	}
+	states := make(map[string]state, len(data))
+	for id, d := range data {
+		var s state
+		if err := json.Unmarshal([]byte(d), &s); err != nil {
+			return nil, fmt.Errorf("couldn't unmarshal liveness of provider %s: %w", id, err)
+		}
+		states[id] = s
+	}
+	return states, nil
+}
+
+// annotate sets the status of the provider from its stored liveness.
+func annotate(p types.Provider, states map[string]state) types.Provider {
+	s, ok := states[p.ID]
+	if !ok || p.ProviderUrl == "" {
+		p.Status = types.ProviderStatusUnknown
+		return p
+	}
+	p.Status = s.Status
+	p.LastSeen = s.LastSeen
+	return p
+}
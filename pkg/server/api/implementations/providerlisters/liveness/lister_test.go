@@ -0,0 +1,61 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package liveness_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/implementations/providerlisters/liveness"
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestProbe(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		path    string
+		wantErr bool
+	}{
+		{name: "OK", status: http.StatusOK, path: "/api/dsp"},
+		{name: "Unauthorized", status: http.StatusUnauthorized, path: "/api/dsp"},
+		{name: "ServerError", status: http.StatusBadGateway, path: "/api/dsp", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requested string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requested = r.URL.Path
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			err := liveness.Probe(context.Background(), srv.Client(), srv.URL+tt.path+"?x=1")
+			assert.Equal(t, tt.wantErr, err != nil)
+			assert.Equal(t, "/.well-known/dspace-version", requested)
+		})
+	}
+}
+
+func TestProbeUnreachable(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+
+	assert.Error(t, liveness.Probe(context.Background(), http.DefaultClient, srv.URL))
+	assert.Error(t, liveness.Probe(context.Background(), http.DefaultClient, "ftp://example.com"))
+}
@@ -22,6 +22,7 @@ import (
 
 	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/logging"
 	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/types"
+	"github.com/go-jose/go-jose/v4"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/trace"
 )
@@ -79,6 +80,14 @@ func (pl *ProviderLister) GetProvider(ctx context.Context, providerID string) (t
 	return types.Provider{}, fmt.Errorf("%w: provider %s not found", types.ErrNotFound, providerID)
 }
 
+// GetProviderKeys returns no keys, the static provider doesn't have a real one.
+func (pl *ProviderLister) GetProviderKeys(ctx context.Context, providerID string) ([]jose.JSONWebKey, error) {
+	if _, err := pl.GetProvider(ctx, providerID); err != nil {
+		return nil, err
+	}
+	return []jose.JSONWebKey{}, nil
+}
+
 func (pl *ProviderLister) GetProviderURL(ctx context.Context, providerID string) (string, error) {
 	logger := logging.Extract(ctx)
 	logger.Info("Getting provider URL")
@@ -0,0 +1,82 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sharemanagers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/types"
+)
+
+// DirStorage keeps the content of shared files in a local directory, for a single replica.
+type DirStorage struct {
+	dir string
+}
+
+func NewDirStorage(dir string) *DirStorage {
+	return &DirStorage{dir: dir}
+}
+
+func (s *DirStorage) Put(_ context.Context, key string, content io.Reader, _ int64, _ string) error {
+	f, err := os.CreateTemp(s.dir, key+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := io.Copy(f, content); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	// Only complete files are visible under their key.
+	return os.Rename(f.Name(), s.path(key))
+}
+
+func (s *DirStorage) Get(_ context.Context, key string) (io.ReadCloser, int64, error) {
+	f, err := os.Open(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, 0, fmt.Errorf("%w: shared file %s not found", types.ErrNotFound, key)
+	}
+	if err != nil {
+		return nil, 0, fmt.Errorf("couldn't open shared file %s: %w", key, err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, 0, fmt.Errorf("couldn't open shared file %s: %w", key, err)
+	}
+	return f, info.Size(), nil
+}
+
+func (s *DirStorage) Remove(_ context.Context, key string) error {
+	err := os.Remove(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (s *DirStorage) path(key string) string {
+	return filepath.Join(s.dir, filepath.Base(key))
+}
@@ -0,0 +1,208 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package smredis contains a share manager that republishes files from the dataspace, with the
+// shares in redis and their content in an object storage.
+package smredis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/leader"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/logging"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/implementations/sharemanagers"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/types"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	storageKeyPrefix = "shares"
+	// expiryKey is a sorted set of the stored contents, scored by when their share expires.
+	expiryKey = "shares:expiry"
+
+	fieldTokenHash = "token_hash"
+	fieldName      = "name"
+	fieldMimeType  = "mime_type"
+	fieldSize      = "size"
+)
+
+var tracer trace.Tracer
+
+func init() {
+	tracer = otel.Tracer(
+		"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/implementations/sharemanagers/redis",
+	)
+}
+
+// ShareManager streams files from the dataspace into the storage and keeps the shares in redis
+// for a limited time, so that they can be downloaded with a bearer token by apps that are not
+// part of the dataspace. The content of expired shares is removed by RemoveExpired.
+type ShareManager struct {
+	r       *redis.Client
+	dc      types.DataspaceConnector
+	storage sharemanagers.Storage
+	baseURL string
+	ttl     time.Duration
+	maxSize int64
+	// elector decides whether this replica removes the content of expired shares.
+	elector *leader.Elector
+}
+
+// New creates a new share manager, shares will be published under baseURL for the given ttl, or
+// with a path-absolute URI if baseURL is empty. Files larger than maxSize bytes can't be shared.
+func New(
+	redisClient *redis.Client,
+	dc types.DataspaceConnector,
+	storage sharemanagers.Storage,
+	baseURL string,
+	ttl time.Duration,
+	maxSize int64,
+	elector *leader.Elector,
+) *ShareManager {
+	return &ShareManager{
+		r:       redisClient,
+		dc:      dc,
+		storage: storage,
+		baseURL: baseURL,
+		ttl:     ttl,
+		maxSize: maxSize,
+		elector: elector,
+	}
+}
+
+// SubmitShare streams the target file from its provider into the storage and publishes it.
+func (sm *ShareManager) SubmitShare(ctx context.Context, share types.ShareRequest) (types.ShareResponse, error) {
+	logger := logging.Extract(ctx)
+	logger.Info("Sharing file", "provider_id", share.Target.ProviderID, "file_id", share.Target.FileID)
+	ctx, span := tracer.Start(ctx, "RedisShareManager.SubmitShare")
+	defer span.End()
+
+	token, err := sharemanagers.GenerateToken()
+	if err != nil {
+		return types.ShareResponse{}, err
+	}
+	shareID := uuid.New()
+	key := storageKey(shareID)
+	// The content is registered before it is stored, so it is removed even if saving the share fails.
+	expiresAt := time.Now().Add(sm.ttl)
+	if err := sm.r.ZAdd(ctx, expiryKey, redis.Z{Score: float64(expiresAt.Unix()), Member: key}).Err(); err != nil {
+		return types.ShareResponse{}, fmt.Errorf("couldn't save share %s: %w", shareID, err)
+	}
+	info, size, err := sharemanagers.Fetch(ctx, sm.dc, sm.storage, key, share.Target, sm.maxSize)
+	if err != nil {
+		return types.ShareResponse{}, err
+	}
+
+	_, err = sm.r.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key,
+			fieldTokenHash, sharemanagers.HashToken(token),
+			fieldName, info.Name,
+			fieldMimeType, info.MimeType,
+			fieldSize, size,
+		)
+		pipe.ExpireAt(ctx, key, expiresAt)
+		return nil
+	})
+	if err != nil {
+		return types.ShareResponse{}, fmt.Errorf("couldn't save share %s: %w", shareID, err)
+	}
+
+	return types.ShareResponse{
+		TTL:         int64(sm.ttl.Seconds()),
+		DownloadURI: fmt.Sprintf("%s/api/shares/%s", sm.baseURL, shareID),
+		BearerToken: token,
+	}, nil
+}
+
+// GetSharedFile opens the shared file if the bearer token matches the one handed out for it.
+func (sm *ShareManager) GetSharedFile(
+	ctx context.Context, shareID uuid.UUID, bearerToken string,
+) (types.SharedFile, error) {
+	logger := logging.Extract(ctx)
+	logger.Info("Getting shared file", "share_id", shareID)
+	ctx, span := tracer.Start(ctx, "RedisShareManager.GetSharedFile")
+	defer span.End()
+
+	key := storageKey(shareID)
+	data, err := sm.r.HGetAll(ctx, key).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return types.SharedFile{}, fmt.Errorf("%w: share %s not found", types.ErrNotFound, shareID)
+		}
+		return types.SharedFile{}, fmt.Errorf("couldn't get share %s: %w", shareID, err)
+	}
+	if len(data) == 0 {
+		return types.SharedFile{}, fmt.Errorf("%w: share %s not found", types.ErrNotFound, shareID)
+	}
+	if !sharemanagers.CheckToken(data[fieldTokenHash], bearerToken) {
+		return types.SharedFile{}, fmt.Errorf("%w: wrong token for share %s", types.ErrInvalidCredentials, shareID)
+	}
+
+	content, size, err := sm.storage.Get(ctx, key)
+	if err != nil {
+		return types.SharedFile{}, err
+	}
+	if stored, err := strconv.ParseInt(data[fieldSize], 10, 64); err == nil {
+		size = stored
+	}
+	return types.SharedFile{
+		Name:     data[fieldName],
+		MimeType: data[fieldMimeType],
+		Size:     size,
+		Content:  content,
+	}, nil
+}
+
+// RemoveExpired removes the content of expired shares from the storage, it is only done by the
+// leader.
+func (sm *ShareManager) RemoveExpired(ctx context.Context) error {
+	logger := logging.Extract(ctx)
+	if !sm.elector.IsLeader() {
+		logger.Debug("Not the leader, skipping removal of expired shares")
+		return nil
+	}
+	keys, err := sm.r.ZRangeByScore(ctx, expiryKey, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(time.Now().Unix(), 10),
+	}).Result()
+	if err != nil {
+		return fmt.Errorf("couldn't list expired shares: %w", err)
+	}
+	var errs []error
+	for _, key := range keys {
+		if err := sm.storage.Remove(ctx, key); err != nil {
+			errs = append(errs, fmt.Errorf("couldn't remove expired shared file %s: %w", key, err))
+			continue
+		}
+		if err := sm.r.ZRem(ctx, expiryKey, key).Err(); err != nil {
+			errs = append(errs, fmt.Errorf("couldn't unregister expired shared file %s: %w", key, err))
+		}
+	}
+	if len(keys) > 0 {
+		logger.Info("Removed expired shared files", "count", len(keys)-len(errs))
+	}
+	return errors.Join(errs...)
+}
+
+func storageKey(shareID uuid.UUID) string {
+	return fmt.Sprintf("%s:%s", storageKeyPrefix, shareID)
+}
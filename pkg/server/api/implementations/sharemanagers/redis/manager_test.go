@@ -0,0 +1,170 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smredis_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	mtypes "github.com/HEALTH-X-dataLOFT/cma-backend/mocks/github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/types"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/leader"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/implementations/sharemanagers"
+	smredis "github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/implementations/sharemanagers/redis"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/types"
+	"github.com/alecthomas/assert/v2"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/mock"
+)
+
+var target = types.Target{ProviderID: "provider-a", FileID: uuid.MustParse("8e8a7bb5-5d4b-4a38-b5d4-f3ad1dc05e6c")}
+
+func setup(t *testing.T, content string, ttl time.Duration) (*miniredis.Miniredis, *sharemanagers.DirStorage, *smredis.ShareManager) {
+	t.Helper()
+	return setupElected(t, content, ttl, nil)
+}
+
+// setupElected creates a share manager that only removes expired shares while elector is the
+// leader, the electors campaign in the returned redis server.
+func setupElected(
+	t *testing.T, content string, ttl time.Duration, elector func(r *redis.Client) *leader.Elector,
+) (*miniredis.Miniredis, *sharemanagers.DirStorage, *smredis.ShareManager) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	r := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { r.Close() })
+	dc := mtypes.NewMockDataspaceConnector(t)
+	dc.EXPECT().GetProviderFileInfo(mock.Anything, "provider-a", target.FileID.String()).Return(
+		types.ProviderFile{Name: "heart_rate.json", MimeType: "application/json"}, nil,
+	)
+	dc.EXPECT().GetProviderFile(mock.Anything, "provider-a", target.FileID.String(), types.FileRange{}).RunAndReturn(
+		func(context.Context, string, string, types.FileRange) (io.ReadCloser, types.FileMetadata, error) {
+			return io.NopCloser(strings.NewReader(content)), types.FileMetadata{Size: -1}, nil
+		},
+	)
+	storage := sharemanagers.NewDirStorage(t.TempDir())
+	var e *leader.Elector
+	if elector != nil {
+		e = elector(r)
+	}
+	return mr, storage, smredis.New(r, dc, storage, "https://cma.example.org", ttl, 1024, e)
+}
+
+func shareID(t *testing.T, resp types.ShareResponse) uuid.UUID {
+	t.Helper()
+	id, err := uuid.Parse(strings.TrimPrefix(resp.DownloadURI, "https://cma.example.org/api/shares/"))
+	assert.NoError(t, err)
+	return id
+}
+
+func TestShare(t *testing.T) {
+	ctx := context.Background()
+	mr, _, sm := setup(t, `{"heart_rate":60}`, time.Hour)
+
+	resp, err := sm.SubmitShare(ctx, types.ShareRequest{Target: target})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3600), resp.TTL)
+	id := shareID(t, resp)
+
+	// Only a hash of the token, and no content, is kept in redis.
+	for _, key := range mr.Keys() {
+		if mr.Type(key) != "hash" {
+			continue
+		}
+		fields, err := mr.HKeys(key)
+		assert.NoError(t, err)
+		for _, field := range fields {
+			value := mr.HGet(key, field)
+			assert.NotContains(t, value, resp.BearerToken)
+			assert.NotContains(t, value, "heart_rate\"")
+		}
+	}
+
+	file, err := sm.GetSharedFile(ctx, id, resp.BearerToken)
+	assert.NoError(t, err)
+	defer file.Content.Close()
+	assert.Equal(t, "heart_rate.json", file.Name)
+	assert.Equal(t, "application/json", file.MimeType)
+	assert.Equal(t, int64(17), file.Size)
+	data, err := io.ReadAll(file.Content)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"heart_rate":60}`, string(data))
+}
+
+func TestShareWrongToken(t *testing.T) {
+	ctx := context.Background()
+	_, _, sm := setup(t, `{"heart_rate":60}`, time.Hour)
+
+	resp, err := sm.SubmitShare(ctx, types.ShareRequest{Target: target})
+	assert.NoError(t, err)
+	_, err = sm.GetSharedFile(ctx, shareID(t, resp), "wrong")
+	assert.IsError(t, err, types.ErrInvalidCredentials)
+	_, err = sm.GetSharedFile(ctx, uuid.New(), resp.BearerToken)
+	assert.IsError(t, err, types.ErrNotFound)
+}
+
+func TestShareTooLarge(t *testing.T) {
+	ctx := context.Background()
+	_, _, sm := setup(t, strings.Repeat("x", 1025), time.Hour)
+
+	_, err := sm.SubmitShare(ctx, types.ShareRequest{Target: target})
+	assert.IsError(t, err, types.ErrInvalid)
+}
+
+func TestShareExpired(t *testing.T) {
+	ctx := context.Background()
+	mr, storage, sm := setup(t, `{"heart_rate":60}`, time.Second)
+
+	resp, err := sm.SubmitShare(ctx, types.ShareRequest{Target: target})
+	assert.NoError(t, err)
+	id := shareID(t, resp)
+
+	mr.FastForward(time.Second)
+	_, err = sm.GetSharedFile(ctx, id, resp.BearerToken)
+	assert.IsError(t, err, types.ErrNotFound)
+
+	// The content of the expired share is kept until it is removed.
+	_, _, err = storage.Get(ctx, "shares:"+id.String())
+	assert.NoError(t, err)
+	time.Sleep(1100 * time.Millisecond)
+	assert.NoError(t, sm.RemoveExpired(ctx))
+	_, _, err = storage.Get(ctx, "shares:"+id.String())
+	assert.IsError(t, err, types.ErrNotFound)
+}
+
+func TestShareExpiredNotLeader(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	mr, storage, sm := setupElected(t, `{"heart_rate":60}`, time.Second, func(r *redis.Client) *leader.Elector {
+		leader.New(ctx, r, "replica-a", time.Minute)
+		return leader.New(ctx, r, "replica-b", time.Minute)
+	})
+
+	resp, err := sm.SubmitShare(ctx, types.ShareRequest{Target: target})
+	assert.NoError(t, err)
+	id := shareID(t, resp)
+	mr.FastForward(time.Second)
+	time.Sleep(1100 * time.Millisecond)
+
+	// Only the leader removes the content of expired shares.
+	assert.NoError(t, sm.RemoveExpired(ctx))
+	_, _, err = storage.Get(ctx, "shares:"+id.String())
+	assert.NoError(t, err)
+}
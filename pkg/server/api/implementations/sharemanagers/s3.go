@@ -0,0 +1,65 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sharemanagers
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/types"
+	"github.com/minio/minio-go/v7"
+)
+
+// S3Storage keeps the content of shared files in a bucket of an S3 compatible object storage, so
+// every replica can serve them.
+type S3Storage struct {
+	client *minio.Client
+	bucket string
+}
+
+func NewS3Storage(client *minio.Client, bucket string) *S3Storage {
+	return &S3Storage{
+		client: client,
+		bucket: bucket,
+	}
+}
+
+func (s *S3Storage) Put(ctx context.Context, key string, content io.Reader, size int64, mimeType string) error {
+	_, err := s.client.PutObject(ctx, s.bucket, key, content, size, minio.PutObjectOptions{ContentType: mimeType})
+	return err
+}
+
+func (s *S3Storage) Get(ctx context.Context, key string) (io.ReadCloser, int64, error) {
+	object, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, 0, fmt.Errorf("couldn't get shared file %s: %w", key, err)
+	}
+	// The object is only requested when it is first used.
+	info, err := object.Stat()
+	if err != nil {
+		object.Close()
+		if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+			return nil, 0, fmt.Errorf("%w: shared file %s not found", types.ErrNotFound, key)
+		}
+		return nil, 0, fmt.Errorf("couldn't get shared file %s: %w", key, err)
+	}
+	return object, info.Size, nil
+}
+
+func (s *S3Storage) Remove(ctx context.Context, key string) error {
+	return s.client.RemoveObject(ctx, s.bucket, key, minio.RemoveObjectOptions{})
+}
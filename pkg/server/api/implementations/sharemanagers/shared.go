@@ -0,0 +1,119 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sharemanagers contains what the share managers have in common: the storage the content
+// of shared files is streamed to, and the share tokens.
+package sharemanagers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/types"
+)
+
+const tokenBytes = 32
+
+// Storage keeps the content of shared files until the share expires.
+type Storage interface {
+	// Put stores the content under key, size is -1 if it isn't known in advance.
+	Put(ctx context.Context, key string, content io.Reader, size int64, mimeType string) error
+	// Get opens the content stored under key, and returns its size.
+	Get(ctx context.Context, key string) (io.ReadCloser, int64, error)
+	Remove(ctx context.Context, key string) error
+}
+
+// Fetch streams the target file from its provider into the storage under key, refusing files
+// larger than maxSize bytes. It returns the file info and the number of bytes stored.
+func Fetch(
+	ctx context.Context,
+	dc types.DataspaceConnector,
+	storage Storage,
+	key string,
+	target types.Target,
+	maxSize int64,
+) (types.ProviderFile, int64, error) {
+	errTooLarge := fmt.Errorf(
+		"%w: file is larger than the maximum of %d bytes that can be shared", types.ErrInvalid, maxSize,
+	)
+	fileID := target.FileID.String()
+	info, err := dc.GetProviderFileInfo(ctx, target.ProviderID, fileID)
+	if err != nil {
+		return types.ProviderFile{}, 0, err
+	}
+	reader, meta, err := dc.GetProviderFile(ctx, target.ProviderID, fileID, types.FileRange{})
+	if err != nil {
+		return types.ProviderFile{}, 0, err
+	}
+	defer reader.Close()
+	if meta.Size > maxSize {
+		return types.ProviderFile{}, 0, errTooLarge
+	}
+
+	lr := &limitedReader{r: reader, remaining: maxSize}
+	if err := storage.Put(ctx, key, lr, meta.Size, info.MimeType); err != nil {
+		// The provider may not have told the real size, the storage only sees the aborted read.
+		if lr.exceeded {
+			return types.ProviderFile{}, 0, errTooLarge
+		}
+		return types.ProviderFile{}, 0, fmt.Errorf("couldn't store file %s: %w", fileID, err)
+	}
+	return info, lr.read, nil
+}
+
+// limitedReader fails once more than the remaining bytes are read, unlike io.LimitReader, which
+// would silently truncate the file.
+type limitedReader struct {
+	r         io.Reader
+	remaining int64
+	read      int64
+	exceeded  bool
+}
+
+func (lr *limitedReader) Read(p []byte) (int, error) {
+	n, err := lr.r.Read(p)
+	lr.read += int64(n)
+	if lr.read > lr.remaining {
+		lr.exceeded = true
+		return n, fmt.Errorf("%w: file is too large to be shared", types.ErrInvalid)
+	}
+	return n, err
+}
+
+// GenerateToken returns a random bearer token for a share.
+func GenerateToken() (string, error) {
+	b := make([]byte, tokenBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("couldn't generate share token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashToken hashes the token, so that the stored shares don't leak usable tokens.
+func HashToken(token string) string {
+	h := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(h[:])
+}
+
+// CheckToken tells in constant time whether the token matches the stored hash.
+func CheckToken(hash string, token string) bool {
+	return subtle.ConstantTimeCompare([]byte(hash), []byte(HashToken(token))) == 1
+}
@@ -0,0 +1,117 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sharemanagers_test
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	mtypes "github.com/HEALTH-X-dataLOFT/cma-backend/mocks/github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/types"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/implementations/sharemanagers"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/types"
+	"github.com/alecthomas/assert/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestFetch(t *testing.T) {
+	target := types.Target{ProviderID: "provider-a", FileID: uuid.MustParse("8e8a7bb5-5d4b-4a38-b5d4-f3ad1dc05e6c")}
+	tests := []struct {
+		name     string
+		content  string
+		size     int64
+		maxSize  int64
+		wantErr  error
+		wantSize int64
+	}{
+		{
+			name:     "KnownSize",
+			content:  "content",
+			size:     7,
+			maxSize:  7,
+			wantSize: 7,
+		},
+		{
+			name:     "UnknownSize",
+			content:  "content",
+			size:     -1,
+			maxSize:  7,
+			wantSize: 7,
+		},
+		{
+			name:    "KnownSizeTooLarge",
+			content: "content",
+			size:    7,
+			maxSize: 6,
+			wantErr: types.ErrInvalid,
+		},
+		{
+			name:    "UnknownSizeTooLarge",
+			content: "content",
+			size:    -1,
+			maxSize: 6,
+			wantErr: types.ErrInvalid,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			dc := mtypes.NewMockDataspaceConnector(t)
+			dc.EXPECT().GetProviderFileInfo(mock.Anything, "provider-a", target.FileID.String()).Return(
+				types.ProviderFile{Name: "scan.dcm", MimeType: "application/dicom"}, nil,
+			)
+			dc.EXPECT().GetProviderFile(mock.Anything, "provider-a", target.FileID.String(), types.FileRange{}).Return(
+				io.NopCloser(strings.NewReader(tt.content)), types.FileMetadata{Size: tt.size}, nil,
+			)
+			storage := sharemanagers.NewDirStorage(t.TempDir())
+
+			info, size, err := sharemanagers.Fetch(ctx, dc, storage, "share", target, tt.maxSize)
+			if tt.wantErr != nil {
+				assert.IsError(t, err, tt.wantErr)
+				_, _, err := storage.Get(ctx, "share")
+				assert.IsError(t, err, types.ErrNotFound)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "scan.dcm", info.Name)
+			assert.Equal(t, tt.wantSize, size)
+
+			content, size, err := storage.Get(ctx, "share")
+			assert.NoError(t, err)
+			defer content.Close()
+			assert.Equal(t, tt.wantSize, size)
+			data, err := io.ReadAll(content)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.content, string(data))
+		})
+	}
+}
+
+func TestCheckToken(t *testing.T) {
+	token, err := sharemanagers.GenerateToken()
+	assert.NoError(t, err)
+	other, err := sharemanagers.GenerateToken()
+	assert.NoError(t, err)
+	assert.NotEqual(t, token, other)
+
+	hash := sharemanagers.HashToken(token)
+	assert.NotEqual(t, token, hash)
+	assert.True(t, sharemanagers.CheckToken(hash, token))
+	assert.False(t, sharemanagers.CheckToken(hash, other))
+	assert.False(t, sharemanagers.CheckToken(hash, ""))
+}
@@ -0,0 +1,164 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package static contains an in-memory share manager implementation, for a single replica.
+package static
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/logging"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/implementations/sharemanagers"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/types"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer trace.Tracer
+
+func init() {
+	tracer = otel.Tracer(
+		"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/implementations/sharemanagers/static",
+	)
+}
+
+type share struct {
+	tokenHash string
+	name      string
+	mimeType  string
+	size      int64
+	expiresAt time.Time
+}
+
+// ShareManager keeps the shares in memory and their content in the storage, they are lost on
+// restart. Expired shares are removed when the next file is shared.
+type ShareManager struct {
+	sync.Mutex
+	dc      types.DataspaceConnector
+	storage sharemanagers.Storage
+	baseURL string
+	ttl     time.Duration
+	maxSize int64
+	shares  map[uuid.UUID]share
+}
+
+// New creates a new share manager, shares will be published under baseURL for the given ttl, or
+// with a path-absolute URI if baseURL is empty. Files larger than maxSize bytes can't be shared.
+func New(
+	dc types.DataspaceConnector,
+	storage sharemanagers.Storage,
+	baseURL string,
+	ttl time.Duration,
+	maxSize int64,
+) *ShareManager {
+	return &ShareManager{
+		dc:      dc,
+		storage: storage,
+		baseURL: baseURL,
+		ttl:     ttl,
+		maxSize: maxSize,
+		shares:  make(map[uuid.UUID]share),
+	}
+}
+
+// SubmitShare streams the target file from its provider into the storage and publishes it.
+func (sm *ShareManager) SubmitShare(ctx context.Context, req types.ShareRequest) (types.ShareResponse, error) {
+	logger := logging.Extract(ctx)
+	logger.Info("Sharing file", "provider_id", req.Target.ProviderID, "file_id", req.Target.FileID)
+	ctx, span := tracer.Start(ctx, "StaticShareManager.SubmitShare")
+	defer span.End()
+
+	sm.removeExpired(ctx)
+
+	token, err := sharemanagers.GenerateToken()
+	if err != nil {
+		return types.ShareResponse{}, err
+	}
+	shareID := uuid.New()
+	info, size, err := sharemanagers.Fetch(ctx, sm.dc, sm.storage, shareID.String(), req.Target, sm.maxSize)
+	if err != nil {
+		return types.ShareResponse{}, err
+	}
+
+	sm.Lock()
+	sm.shares[shareID] = share{
+		tokenHash: sharemanagers.HashToken(token),
+		name:      info.Name,
+		mimeType:  info.MimeType,
+		size:      size,
+		expiresAt: time.Now().Add(sm.ttl),
+	}
+	sm.Unlock()
+
+	return types.ShareResponse{
+		TTL:         int64(sm.ttl.Seconds()),
+		DownloadURI: fmt.Sprintf("%s/api/shares/%s", sm.baseURL, shareID),
+		BearerToken: token,
+	}, nil
+}
+
+// GetSharedFile opens the shared file if the bearer token matches the one handed out for it.
+func (sm *ShareManager) GetSharedFile(
+	ctx context.Context, shareID uuid.UUID, bearerToken string,
+) (types.SharedFile, error) {
+	logger := logging.Extract(ctx)
+	logger.Info("Getting shared file", "share_id", shareID)
+	ctx, span := tracer.Start(ctx, "StaticShareManager.GetSharedFile")
+	defer span.End()
+
+	sm.Lock()
+	s, ok := sm.shares[shareID]
+	sm.Unlock()
+	if !ok || time.Now().After(s.expiresAt) {
+		return types.SharedFile{}, fmt.Errorf("%w: share %s not found", types.ErrNotFound, shareID)
+	}
+	if !sharemanagers.CheckToken(s.tokenHash, bearerToken) {
+		return types.SharedFile{}, fmt.Errorf("%w: wrong token for share %s", types.ErrInvalidCredentials, shareID)
+	}
+
+	content, _, err := sm.storage.Get(ctx, shareID.String())
+	if err != nil {
+		return types.SharedFile{}, err
+	}
+	return types.SharedFile{
+		Name:     s.name,
+		MimeType: s.mimeType,
+		Size:     s.size,
+		Content:  content,
+	}, nil
+}
+
+func (sm *ShareManager) removeExpired(ctx context.Context) {
+	logger := logging.Extract(ctx)
+	now := time.Now()
+	var expired []uuid.UUID
+	sm.Lock()
+	for id, s := range sm.shares {
+		if now.After(s.expiresAt) {
+			expired = append(expired, id)
+			delete(sm.shares, id)
+		}
+	}
+	sm.Unlock()
+	for _, id := range expired {
+		if err := sm.storage.Remove(ctx, id.String()); err != nil {
+			logger.Error("Couldn't remove expired shared file", "share_id", id, "error", err)
+		}
+	}
+}
@@ -17,11 +17,13 @@
 package dsp
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	"time"
 
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/events"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/leader"
 	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/logging"
 	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/implementations/studymanagers"
 	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/types"
@@ -35,10 +37,7 @@ import (
 
 var tracer trace.Tracer
 
-const (
-	pollInterval = 1
-	storageKey   = "studies:dsp-studies"
-)
+const storageKey = "studies:dsp-studies"
 
 func init() {
 	tracer = otel.Tracer(
@@ -51,38 +50,43 @@ type StudyManager struct {
 	dsp dspclient.ClientServiceClient
 	uri string
 	r   *redis.Client
+	dc  types.DataspaceConnector
+	pl  types.ProviderLister
+	bus events.Bus
+	// elector decides whether this replica polls the study catalog, the others read the studies
+	// the leader stored.
+	elector *leader.Elector
 }
 
+// New creates a study manager, the studies are polled with UpdateStudies.
 func New(
-	ctx context.Context,
 	client dspclient.ClientServiceClient,
 	studyCatalogBaseUri string,
 	redisClient *redis.Client,
+	dc types.DataspaceConnector,
+	pl types.ProviderLister,
+	bus events.Bus,
+	elector *leader.Elector,
 ) *StudyManager {
-	t := time.NewTicker(pollInterval * time.Minute)
-	sm := &StudyManager{
-		dsp: client,
-		uri: studyCatalogBaseUri,
-		r:   redisClient,
-	}
-	go sm.monitorStudies(ctx, t)
-	return sm
+	return &StudyManager{
+		dsp:     client,
+		uri:     studyCatalogBaseUri,
+		r:       redisClient,
+		dc:      dc,
+		pl:      pl,
+		bus:     bus,
+		elector: elector,
+	}
 }
 
 // ListStudies returns all studies.
 func (sm *StudyManager) ListStudies(ctx context.Context) ([]types.Study, error) {
 	logger := logging.Extract(ctx)
 	logger.Info("Listing studies")
-	_, span := tracer.Start(ctx, "DspConnector.ListStudies")
+	ctx, span := tracer.Start(ctx, "DspConnector.ListStudies")
 	defer span.End()
 
-	data, err := sm.r.Get(ctx, storageKey).Result()
-	if err != nil {
-		return nil, fmt.Errorf("couldn't get studies from redis: %w", err)
-	}
-
-	var returnedStudies []studymanagers.Study
-	err = json.Unmarshal([]byte(data), &returnedStudies)
+	returnedStudies, err := sm.loadStudies(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -133,32 +137,62 @@ func (sm *StudyManager) GetStudy(ctx context.Context, studyId uuid.UUID) (types.
 func (sm *StudyManager) ListStudyFiles(ctx context.Context, studyID uuid.UUID) ([]types.ProviderFile, error) {
 	logger := logging.Extract(ctx)
 	logger.Info("Listing study files")
-	_, span := tracer.Start(ctx, "SimpleConnector.ListStudyFiles")
+	ctx, span := tracer.Start(ctx, "SimpleConnector.ListStudyFiles")
 	defer span.End()
 
-	// NOTE: This is currently not used as the app for now is going to get the files via the provider API.
-	return []types.ProviderFile{}, nil
-}
+	studies, err := sm.loadStudies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var study *studymanagers.Study
+	for i := range studies {
+		if studies[i].Id != nil && *studies[i].Id == studyID.String() {
+			study = &studies[i]
+			break
+		}
+	}
+	if study == nil {
+		return nil, fmt.Errorf("%w: Study %s not found", types.ErrNotFound, studyID)
+	}
 
-func (sm *StudyManager) monitorStudies(ctx context.Context, t *time.Ticker) {
-	logger := logging.Extract(ctx).With("monitor_type", "dsp study lister")
-	logger.Info("Starting monitor")
-	sm.updateStudies(ctx)
-	for {
-		select {
-		case <-ctx.Done():
-			logger.Info("Context done, stopping monitor")
-			t.Stop()
-			logger.Info("Timer stopped")
-			return
-		case <-t.C:
-			sm.updateStudies(logging.Inject(ctx, logger))
+	providers, err := sm.pl.ListProviders(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var files []types.ProviderFile
+	for _, p := range providers {
+		providerFiles, err := sm.dc.ListProviderFiles(ctx, p.ID)
+		if err != nil {
+			// One unreachable provider shouldn't hide the files of all the others.
+			logger.Error("Couldn't list files of provider", "provider", p.Name, "error", err)
+			continue
 		}
+		files = append(files, providerFiles...)
 	}
+	return studymanagers.MatchFiles(*study, files), nil
 }
 
-func (sm *StudyManager) updateStudies(ctx context.Context) {
+// loadStudies returns the studies as stored by the monitor.
+func (sm *StudyManager) loadStudies(ctx context.Context) ([]studymanagers.Study, error) {
+	data, err := sm.r.Get(ctx, storageKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get studies from redis: %w", err)
+	}
+
+	var studies []studymanagers.Study
+	if err := json.Unmarshal([]byte(data), &studies); err != nil {
+		return nil, err
+	}
+	return studies, nil
+}
+
+// UpdateStudies polls the study catalog and stores its studies, it is only done by the leader.
+func (sm *StudyManager) UpdateStudies(ctx context.Context) error {
 	logger := logging.Extract(ctx)
+	if !sm.elector.IsLeader() {
+		logger.Debug("Not the leader, skipping update")
+		return nil
+	}
 	logger.Info("Listing studies")
 	_, span := tracer.Start(ctx, "DspConnector.ListStudies")
 	defer span.End()
@@ -166,10 +200,10 @@ func (sm *StudyManager) updateStudies(ctx context.Context) {
 		ProviderUri: sm.uri,
 	})
 	if err != nil {
-		logger.Error("failed to retrieve catalogue", "error", err)
+		return fmt.Errorf("failed to retrieve catalogue: %w", err)
 	}
 	if len(catalogue.Datasets) != 1 {
-		logger.Error("catalogue does not contain single dataset", "num_items", len(catalogue.Datasets))
+		return fmt.Errorf("catalogue does not contain single dataset but %d", len(catalogue.Datasets))
 	}
 	dlInfo, err := sm.dsp.GetProviderDatasetDownloadInformation(
 		ctx,
@@ -179,7 +213,7 @@ func (sm *StudyManager) updateStudies(ctx context.Context) {
 		},
 	)
 	if err != nil {
-		logger.Error("Seems file for download could not be found", "error", err)
+		return fmt.Errorf("seems file for download could not be found: %w", err)
 	}
 	defer sm.dsp.SignalTransferComplete(ctx, &dspclient.SignalTransferCompleteRequest{ //nolint:errcheck
 		TransferId: dlInfo.TransferId,
@@ -188,10 +222,70 @@ func (sm *StudyManager) updateStudies(ctx context.Context) {
 	logger.Info("Got download information", "auth_type", dlInfo.PublishInfo.AuthenticationType)
 	body, err := transfer.RetrieveDSPFile(ctx, dlInfo.PublishInfo)
 	if err != nil {
-		logger.Error("failed to download study information from remote", "error", err)
+		return fmt.Errorf("failed to download study information from remote: %w", err)
 	}
 
+	// Without the stored studies every study would seem added.
+	previous, prevErr := sm.loadStudies(ctx)
 	if err := sm.r.Set(ctx, storageKey, body, 0).Err(); err != nil {
-		panic(err)
+		return fmt.Errorf("couldn't store studies: %w", err)
+	}
+	if prevErr != nil {
+		logger.Info("No previous studies to compare to", "error", prevErr)
+		return nil
+	}
+	var studies []studymanagers.Study
+	if err := json.Unmarshal(body, &studies); err != nil {
+		return fmt.Errorf("couldn't unmarshal studies: %w", err)
+	}
+	sm.publishChanges(ctx, previous, studies)
+	return nil
+}
+
+// studyEvent is the data of the study added and updated events.
+type studyEvent struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+// publishChanges publishes an event for every study that was added or changed compared to the
+// previously stored studies.
+func (sm *StudyManager) publishChanges(ctx context.Context, previous, studies []studymanagers.Study) {
+	logger := logging.Extract(ctx)
+	old := make(map[string][]byte, len(previous))
+	for _, s := range previous {
+		if s.Id == nil {
+			continue
+		}
+		data, err := json.Marshal(s)
+		if err != nil {
+			logger.Error("Couldn't marshal study", "error", err)
+			continue
+		}
+		old[*s.Id] = data
+	}
+	for _, s := range studies {
+		if s.Id == nil {
+			continue
+		}
+		typ := events.TypeStudyAdded
+		if prev, ok := old[*s.Id]; ok {
+			data, err := json.Marshal(s)
+			if err != nil {
+				logger.Error("Couldn't marshal study", "error", err)
+				continue
+			}
+			if bytes.Equal(prev, data) {
+				continue
+			}
+			typ = events.TypeStudyUpdated
+		}
+		event, err := events.New(typ, "", studyEvent{ID: *s.Id, Title: s.Title})
+		if err == nil {
+			err = sm.bus.Publish(ctx, event)
+		}
+		if err != nil {
+			logger.Error("Couldn't publish study event", "error", err)
+		}
 	}
 }
@@ -15,7 +15,15 @@
 
 package studymanagers
 
-import "github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/types"
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/types"
+)
 
 func GetOrganizations(study Study) []Organization {
 	var organizations []Organization
@@ -71,3 +79,152 @@ func ExtractResearchData(study Study) []types.ResearchData {
 	}
 	return research
 }
+
+// MatchFiles returns the files that fulfil the data requirements of the study, with the reasons
+// they match. A file fulfils a requirement when every code filter of the requirement has a code
+// that appears in the file's ID, name or description, and the file was created within every
+// period of its date filters. Requirements without any code filters don't match any files, as they
+// would match everything.
+func MatchFiles(study Study, files []types.ProviderFile) []types.ProviderFile {
+	matched := make([]types.ProviderFile, 0)
+	for _, f := range files {
+		var matches []types.StudyFileMatch
+		for _, output := range collectedOutputs(study) {
+			if reason, ok := matchRequirement(output.Requirement, f); ok {
+				matches = append(matches, types.StudyFileMatch{
+					ResearchData: output.Title,
+					Reason:       reason,
+				})
+			}
+		}
+		if len(matches) > 0 {
+			f.Matches = matches
+			matched = append(matched, f)
+		}
+	}
+	return matched
+}
+
+// collectedOutputs returns the outputs of all collect-information actions of the study.
+func collectedOutputs(study Study) []OutputElement {
+	var outputs []OutputElement
+	for _, sci := range study.Contained {
+		maybePlanDefinition, err := sci.AsPlanDefinition()
+		if err != nil || maybePlanDefinition.ResourceType != "PlanDefinition" {
+			continue
+		}
+		for _, action := range maybePlanDefinition.Action {
+			for _, code := range action.Code.Coding {
+				if code.Code == "collect-information" {
+					outputs = append(outputs, action.Output...)
+					break
+				}
+			}
+		}
+	}
+	return outputs
+}
+
+func matchRequirement(req Requirement, f types.ProviderFile) (string, bool) {
+	if len(req.CodeFilter) == 0 {
+		return "", false
+	}
+	var reasons []string
+	for _, cf := range req.CodeFilter {
+		code, ok := matchCodeFilter(cf, f)
+		if !ok {
+			return "", false
+		}
+		reasons = append(reasons, fmt.Sprintf("matches code %s", code))
+	}
+	for _, df := range req.DateFilter {
+		if !inPeriod(f.CreatedAt, df.ValuePeriod) {
+			return "", false
+		}
+		reasons = append(reasons, fmt.Sprintf("created within %s", formatPeriod(df.ValuePeriod)))
+	}
+	return strings.Join(reasons, ", "), true
+}
+
+// matchCodeFilter returns the first code of the filter found in the file's metadata. Codes have to
+// appear exactly, display texts case-insensitively, and both only as whole words, so "8867-4"
+// doesn't match "18867-40" and "Heart rate" doesn't match "heart_rates.json".
+func matchCodeFilter(cf CodeFilter, f types.ProviderFile) (string, bool) {
+	text := strings.Join([]string{f.ID, f.Name, f.Description}, " ")
+	normalized := normalize(text)
+	for _, c := range cf.Code {
+		if c.Code != "" && containsWord(text, c.Code) {
+			return describeCode(c), true
+		}
+		if c.Display != nil && *c.Display != "" && containsWord(normalized, normalize(*c.Display)) {
+			return describeCode(c), true
+		}
+	}
+	return "", false
+}
+
+// containsWord tells if the term appears in the text, neither preceded nor followed by a letter or
+// digit.
+func containsWord(text string, term string) bool {
+	for offset := 0; offset < len(text); {
+		i := strings.Index(text[offset:], term)
+		if i < 0 {
+			return false
+		}
+		start := offset + i
+		end := start + len(term)
+		before, _ := utf8.DecodeLastRuneInString(text[:start])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if !isAlphanumeric(before) && !isAlphanumeric(after) {
+			return true
+		}
+		offset = start + 1
+	}
+	return false
+}
+
+func isAlphanumeric(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// normalize lowercases the text and treats common separators in file names as spaces, so that
+// "heart_rate.json" contains "Heart rate".
+func normalize(s string) string {
+	return separatorReplacer.Replace(strings.ToLower(s))
+}
+
+var separatorReplacer = strings.NewReplacer("_", " ", "-", " ", ".", " ")
+
+func describeCode(c CodingElement) string {
+	if c.Display != nil && *c.Display != "" {
+		return fmt.Sprintf("%s (%s)", c.Code, *c.Display)
+	}
+	return c.Code
+}
+
+// inPeriod checks if the unix timestamp lies within the period, a zero start or end leaves the
+// period open on that side. Files without a creation date never match.
+func inPeriod(createdAt int64, period TimePeriod) bool {
+	if createdAt == 0 {
+		return false
+	}
+	t := time.Unix(createdAt, 0)
+	if !period.Start.IsZero() && t.Before(period.Start) {
+		return false
+	}
+	if !period.End.IsZero() && t.After(period.End) {
+		return false
+	}
+	return true
+}
+
+func formatPeriod(period TimePeriod) string {
+	start, end := "", ""
+	if !period.Start.IsZero() {
+		start = period.Start.Format(time.DateOnly)
+	}
+	if !period.End.IsZero() {
+		end = period.End.Format(time.DateOnly)
+	}
+	return fmt.Sprintf("%s..%s", start, end)
+}
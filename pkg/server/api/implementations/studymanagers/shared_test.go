@@ -0,0 +1,132 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package studymanagers_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/implementations/studymanagers"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/types"
+	"github.com/alecthomas/assert/v2"
+)
+
+const studyJSON = `{
+	"title": "Heart study",
+	"contained": [{
+		"resourceType": "PlanDefinition",
+		"status": "active",
+		"action": [{
+			"code": {"coding": [{"system": "http://example.org", "code": "collect-information"}]},
+			"output": [{
+				"title": "Heart rate 2024",
+				"requirement": {
+					"type": "Observation",
+					"codeFilter": [{"path": "code", "code": [{"system": "http://loinc.org", "code": "8867-4", "display": "Heart rate"}]}],
+					"dateFilter": [{"path": "effective", "valuePeriod": {"start": "2024-01-01T00:00:00Z", "end": "2024-12-31T23:59:59Z"}}]
+				}
+			}, {
+				"title": "Anything",
+				"requirement": {"type": "Observation", "codeFilter": [], "dateFilter": []}
+			}]
+		}]
+	}]
+}`
+
+func TestMatchFiles(t *testing.T) {
+	var study studymanagers.Study
+	assert.NoError(t, json.Unmarshal([]byte(studyJSON), &study))
+
+	in2024 := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC).Unix()
+	in2023 := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC).Unix()
+	files := []types.ProviderFile{
+		{ID: "a", Name: "heart_rate.json", CreatedAt: in2024},
+		{ID: "b", Name: "8867-4-export.json", CreatedAt: in2024},
+		{ID: "c", Name: "heart_rate.json", CreatedAt: in2023},
+		{ID: "d", Name: "steps.json", CreatedAt: in2024},
+		{ID: "e", Name: "heart_rate.json"},
+	}
+
+	matched := studymanagers.MatchFiles(study, files)
+
+	assert.Equal(t, 2, len(matched))
+	assert.Equal(t, "a", matched[0].ID)
+	assert.Equal(t, "b", matched[1].ID)
+	assert.Equal(t, []types.StudyFileMatch{{
+		ResearchData: "Heart rate 2024",
+		Reason:       "matches code 8867-4 (Heart rate), created within 2024-01-01..2024-12-31",
+	}}, matched[0].Matches)
+}
+
+func TestMatchFilesCodeFilter(t *testing.T) {
+	var study studymanagers.Study
+	assert.NoError(t, json.Unmarshal([]byte(studyJSON), &study))
+	in2024 := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC).Unix()
+
+	tests := []struct {
+		name    string
+		file    types.ProviderFile
+		matched bool
+	}{
+		{
+			name:    "CodeInName",
+			file:    types.ProviderFile{ID: "a", Name: "export_8867-4.json"},
+			matched: true,
+		},
+		{
+			name:    "CodeInDescription",
+			file:    types.ProviderFile{ID: "a", Name: "export.json", Description: "LOINC 8867-4, measured at rest"},
+			matched: true,
+		},
+		{
+			name:    "DisplayInDescription",
+			file:    types.ProviderFile{ID: "a", Name: "export.json", Description: "Resting HEART RATE"},
+			matched: true,
+		},
+		{
+			name: "CodeWithLongerPrefix",
+			file: types.ProviderFile{ID: "a", Name: "18867-4.json"},
+		},
+		{
+			name: "CodeWithLongerSuffix",
+			file: types.ProviderFile{ID: "a", Name: "8867-40.json"},
+		},
+		{
+			name: "CodeWithOtherSeparator",
+			file: types.ProviderFile{ID: "a", Name: "8867.4.json"},
+		},
+		{
+			name: "DisplayWithinWord",
+			file: types.ProviderFile{ID: "a", Name: "heart_rates.json"},
+		},
+		{
+			name: "DisplayPartly",
+			file: types.ProviderFile{ID: "a", Name: "heart.json", Description: "rate of steps"},
+		},
+		{
+			name: "DisplayAcrossFields",
+			file: types.ProviderFile{ID: "a", Name: "my_heart", Description: "rates"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.file.CreatedAt = in2024
+			matched := studymanagers.MatchFiles(study, []types.ProviderFile{tt.file})
+			assert.Equal(t, tt.matched, len(matched) == 1)
+		})
+	}
+}
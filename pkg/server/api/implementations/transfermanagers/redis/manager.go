@@ -0,0 +1,732 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tmredis contains a transfer manager that runs transfer jobs in the background, and keeps
+// their state in redis.
+package tmredis
+
+import (
+	"context"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/events"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/identity"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/logging"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/middleware/authforwarder"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/types"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/transfer"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/waitgroup"
+	dspclient "github.com/go-dataspace/run-dsrpc/gen/go/dsp/v1alpha1"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	storageKeyPrefix = "transfers"
+	// progressInterval is how often the progress of a download is saved.
+	progressInterval = time.Second
+	// negotiationMargin is how much longer than the ready timeout the negotiated transfer of a job
+	// is kept, so it is still there to be completed when the job expires.
+	negotiationMargin = time.Minute
+)
+
+var tracer trace.Tracer
+
+// errCancelled aborts a download whose job was cancelled, possibly on another replica.
+var errCancelled = errors.New("transfer was cancelled")
+
+func init() {
+	tracer = otel.Tracer(
+		"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/implementations/transfermanagers/redis",
+	)
+}
+
+// negotiation is the DSP transfer negotiated for a job. It contains the credentials to download
+// the file from the provider, so it is only stored encrypted, apart from the job.
+type negotiation struct {
+	TransferID  string                    `json:"transfer_id"`
+	Credentials types.DownloadCredentials `json:"credentials"`
+}
+
+func (n negotiation) publishInfo() *dspclient.PublishInfo {
+	return &dspclient.PublishInfo{
+		Url:                n.Credentials.URL,
+		AuthenticationType: dspclient.AuthenticationType(n.Credentials.AuthenticationType),
+		Username:           n.Credentials.Username,
+		Password:           n.Credentials.Password,
+	}
+}
+
+// TransferManager runs every transfer job in its own goroutine, and keeps the state of the jobs in
+// redis, so any replica can serve them. A job negotiates a DSP transfer of the file with its
+// provider through run-dsp, the negotiated transfer is stored encrypted with secrets, for
+// readyTimeout. The content of a download job is streamed from the provider when it is opened,
+// the credentials of a credentials job are handed out with the job. The DSP transfer is signalled
+// complete once the content was read, the job was cancelled, or readyTimeout passed. Jobs are
+// stored in redis for jobTTL after they were last updated, every update is also published to the
+// user as a transfer progress event.
+type TransferManager struct {
+	sync.Mutex
+	// ctx is the context of the server, jobs are cancelled when it is cancelled.
+	ctx          context.Context
+	r            *redis.Client
+	dsp          dspclient.ClientServiceClient
+	pl           types.ProviderLister
+	dc           types.DataspaceConnector
+	bus          events.Bus
+	secrets      cipher.AEAD
+	jobTTL       time.Duration
+	readyTimeout time.Duration
+	// jobs cancels the jobs running on this replica.
+	jobs map[uuid.UUID]context.CancelFunc
+}
+
+func New(
+	ctx context.Context,
+	redisClient *redis.Client,
+	client dspclient.ClientServiceClient,
+	pl types.ProviderLister,
+	dc types.DataspaceConnector,
+	bus events.Bus,
+	secrets cipher.AEAD,
+	jobTTL time.Duration,
+	readyTimeout time.Duration,
+) *TransferManager {
+	return &TransferManager{
+		ctx:          ctx,
+		r:            redisClient,
+		dsp:          client,
+		pl:           pl,
+		dc:           dc,
+		bus:          bus,
+		secrets:      secrets,
+		jobTTL:       jobTTL,
+		readyTimeout: readyTimeout,
+		jobs:         make(map[uuid.UUID]context.CancelFunc),
+	}
+}
+
+// StartTransfer stores a new pending job and starts running it.
+func (tm *TransferManager) StartTransfer(
+	ctx context.Context, transfer types.TransferRequest,
+) (types.TransferJob, error) {
+	logger := logging.Extract(ctx)
+	ctx, span := tracer.Start(ctx, "RedisTransferManager.StartTransfer")
+	defer span.End()
+
+	subject, err := identity.Subject(ctx)
+	if err != nil {
+		return types.TransferJob{}, err
+	}
+	now := time.Now().UTC()
+	tj := types.TransferJob{
+		ID:        uuid.New(),
+		Kind:      transfer.Kind,
+		Target:    transfer.Target,
+		State:     types.TransferStatePending,
+		Size:      -1,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := tm.save(ctx, subject, tj); err != nil {
+		return types.TransferJob{}, err
+	}
+	logger = logger.With("transfer_id", tj.ID, "kind", tj.Kind)
+	logger.Info("Starting transfer")
+
+	// The job outlives the request, but still has to be done on behalf of the user.
+	jobCtx := authforwarder.InjectAuthorization(
+		logging.Inject(tm.ctx, logger), authforwarder.ExtractAuthorization(ctx),
+	)
+	jobCtx, cancel := context.WithCancel(jobCtx)
+	tm.Lock()
+	tm.jobs[tj.ID] = cancel
+	tm.Unlock()
+
+	wg := waitgroup.Extract(tm.ctx)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer tm.forget(tj.ID)
+		tm.run(jobCtx, subject, tj)
+	}()
+	return tj, nil
+}
+
+// GetTransfer returns the current state of the job, a completed credentials job with the
+// credentials negotiated for it, until they expire.
+func (tm *TransferManager) GetTransfer(ctx context.Context, transferID uuid.UUID) (types.TransferJob, error) {
+	logger := logging.Extract(ctx)
+	logger.Info("Getting transfer", "transfer_id", transferID)
+	ctx, span := tracer.Start(ctx, "RedisTransferManager.GetTransfer")
+	defer span.End()
+
+	subject, err := identity.Subject(ctx)
+	if err != nil {
+		return types.TransferJob{}, err
+	}
+	tj, err := tm.load(ctx, tm.r, subject, transferID)
+	if err != nil {
+		return types.TransferJob{}, err
+	}
+	tm.expire(&tj)
+	if tj.Kind == types.TransferKindCredentials && tj.State == types.TransferStateCompleted {
+		n, ok, err := tm.loadNegotiation(ctx, subject, transferID)
+		if err != nil {
+			return types.TransferJob{}, err
+		}
+		if ok {
+			tj.Credentials = &n.Credentials
+		}
+	}
+	return tj, nil
+}
+
+// CancelTransfer cancels the job, and completes its DSP transfer. A download that is being
+// streamed, on any replica, is aborted when its progress is saved next, and completes the DSP
+// transfer itself.
+func (tm *TransferManager) CancelTransfer(ctx context.Context, transferID uuid.UUID) error {
+	logger := logging.Extract(ctx)
+	logger.Info("Cancelling transfer", "transfer_id", transferID)
+	ctx, span := tracer.Start(ctx, "RedisTransferManager.CancelTransfer")
+	defer span.End()
+
+	subject, err := identity.Subject(ctx)
+	if err != nil {
+		return err
+	}
+
+	tm.Lock()
+	cancel, ok := tm.jobs[transferID]
+	tm.Unlock()
+	if ok {
+		cancel()
+	}
+	return tm.release(ctx, subject, transferID, func(tj *types.TransferJob) bool {
+		if tj.State.Final() {
+			return false
+		}
+		tj.State = types.TransferStateCancelled
+		return true
+	})
+}
+
+// OpenTransferContent streams the content of a ready download from the provider, it can be opened
+// once on any replica. The progress of the download is saved while the returned reader is read,
+// and the DSP transfer is completed when it is closed.
+func (tm *TransferManager) OpenTransferContent(
+	ctx context.Context, transferID uuid.UUID,
+) (io.ReadCloser, types.TransferJob, error) {
+	logger := logging.Extract(ctx)
+	logger.Info("Opening transfer content", "transfer_id", transferID)
+	ctx, span := tracer.Start(ctx, "RedisTransferManager.OpenTransferContent")
+	defer span.End()
+
+	subject, err := identity.Subject(ctx)
+	if err != nil {
+		return nil, types.TransferJob{}, err
+	}
+	tj, n, err := tm.claim(ctx, subject, transferID)
+	if err != nil {
+		return nil, types.TransferJob{}, err
+	}
+	resp, err := transfer.StreamDSPFile(ctx, n.publishInfo(), nil)
+	if err != nil {
+		tm.signalTransferComplete(ctx, n.TransferID)
+		err = fmt.Errorf("%w: couldn't get file from provider: %w", types.ErrBadGateway, err)
+		tm.fail(ctx, subject, &tj, err)
+		return nil, types.TransferJob{}, err
+	}
+	if resp.ContentLength >= 0 {
+		tj.Size = resp.ContentLength
+	}
+	return &progressReader{
+		ReadCloser: resp.Body,
+		ctx:        context.WithoutCancel(ctx),
+		tm:         tm,
+		subject:    subject,
+		job:        tj,
+		transferID: n.TransferID,
+	}, tj, nil
+}
+
+// run negotiates the transfer of the file of the job on behalf of the user. Once the job is ready,
+// it waits until the job expires, or is cancelled, to complete the transfer if nobody else did.
+func (tm *TransferManager) run(ctx context.Context, subject string, tj types.TransferJob) {
+	logger := logging.Extract(ctx)
+	tj.State = types.TransferStateNegotiating
+	if !tm.update(ctx, subject, &tj) {
+		return
+	}
+
+	info, err := tm.dc.GetProviderFileInfo(ctx, tj.Target.ProviderID, tj.Target.FileID.String())
+	if err != nil {
+		tm.fail(ctx, subject, &tj, err)
+		return
+	}
+	tj.Name = info.Name
+	tj.MimeType = info.MimeType
+	if info.Size > 0 {
+		tj.Size = info.Size
+	}
+	n, err := tm.negotiate(ctx, tj.Target)
+	if err != nil {
+		tm.fail(ctx, subject, &tj, err)
+		return
+	}
+	if err := tm.saveNegotiation(ctx, subject, tj.ID, n); err != nil {
+		tm.signalTransferComplete(ctx, n.TransferID)
+		tm.fail(ctx, subject, &tj, err)
+		return
+	}
+	switch tj.Kind {
+	case types.TransferKindCredentials:
+		tj.State = types.TransferStateCompleted
+		logger.Info("Transfer completed")
+	case types.TransferKindDownload:
+		tj.State = types.TransferStateReady
+		logger.Info("Transfer ready")
+	}
+	if !tm.update(ctx, subject, &tj) {
+		// The job was cancelled while it was negotiated.
+		tm.releaseLogged(ctx, subject, tj.ID, func(*types.TransferJob) bool { return false })
+		return
+	}
+
+	timer := time.NewTimer(tm.readyTimeout)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		// The backend shuts down, or the job was cancelled.
+		tm.releaseLogged(ctx, subject, tj.ID, func(tj *types.TransferJob) bool {
+			if tj.State != types.TransferStateReady {
+				return false
+			}
+			tj.State = types.TransferStateCancelled
+			return true
+		})
+	case <-timer.C:
+		tm.releaseLogged(ctx, subject, tj.ID, func(tj *types.TransferJob) bool {
+			if tj.State != types.TransferStateReady {
+				return false
+			}
+			notRetrieved(tj)
+			return true
+		})
+	}
+}
+
+// negotiate negotiates a DSP transfer of the file with its provider through run-dsp.
+func (tm *TransferManager) negotiate(ctx context.Context, target types.Target) (negotiation, error) {
+	provider, err := tm.pl.GetProvider(ctx, target.ProviderID)
+	if err != nil {
+		return negotiation{}, fmt.Errorf("couldn't get provider: %w", err)
+	}
+	dlInfo, err := tm.dsp.GetProviderDatasetDownloadInformation(
+		authforwarder.InjectProvider(ctx, provider.ID),
+		&dspclient.GetProviderDatasetDownloadInformationRequest{
+			ProviderUrl: provider.ProviderUrl,
+			DatasetId:   target.FileID.String(),
+		},
+	)
+	if err != nil {
+		return negotiation{}, fmt.Errorf("couldn't negotiate transfer with provider: %w", err)
+	}
+	if dlInfo.PublishInfo == nil {
+		tm.signalTransferComplete(ctx, dlInfo.TransferId)
+		return negotiation{}, errors.New("provider published no file")
+	}
+	logging.Extract(ctx).Info("Negotiated transfer", "dsp_transfer_id", dlInfo.TransferId)
+	return negotiation{
+		TransferID: dlInfo.TransferId,
+		Credentials: types.DownloadCredentials{
+			AuthenticationType: int64(dlInfo.PublishInfo.AuthenticationType),
+			URL:                dlInfo.PublishInfo.Url,
+			Username:           dlInfo.PublishInfo.Username,
+			Password:           dlInfo.PublishInfo.Password,
+		},
+	}, nil
+}
+
+// signalTransferComplete tells run-dsp that we're done with the transfer. This also has to happen
+// when the job was cancelled, so it doesn't use the cancellation of the context.
+func (tm *TransferManager) signalTransferComplete(ctx context.Context, transferID string) {
+	_, err := tm.dsp.SignalTransferComplete(context.WithoutCancel(ctx), &dspclient.SignalTransferCompleteRequest{
+		TransferId: transferID,
+	})
+	if err != nil {
+		logging.Extract(ctx).Error("Couldn't signal transfer complete", "dsp_transfer_id", transferID, "error", err)
+	}
+}
+
+// claim marks a ready download as transferring, so its content is only opened once even if
+// several replicas are asked at the same time. The negotiated transfer is taken out of redis with
+// it, whoever reads the content completes the transfer.
+func (tm *TransferManager) claim(
+	ctx context.Context, subject string, transferID uuid.UUID,
+) (types.TransferJob, negotiation, error) {
+	key := storageKey(subject, transferID)
+	var (
+		tj     types.TransferJob
+		sealed *redis.StringCmd
+	)
+	err := tm.r.Watch(ctx, func(tx *redis.Tx) error {
+		var err error
+		tj, err = tm.load(ctx, tx, subject, transferID)
+		if err != nil {
+			return err
+		}
+		if tj.Kind != types.TransferKindDownload {
+			return fmt.Errorf("%w: transfer %s has no content", types.ErrConflict, transferID)
+		}
+		if tj.State != types.TransferStateReady {
+			return fmt.Errorf(
+				"%w: content of transfer %s is not available, it is %s", types.ErrConflict, transferID, tj.State,
+			)
+		}
+		if !tm.expire(&tj) {
+			tj.State = types.TransferStateTransferring
+		}
+		tj.UpdatedAt = time.Now().UTC()
+		data, err := json.Marshal(tj)
+		if err != nil {
+			return fmt.Errorf("couldn't marshal transfer %s: %w", tj.ID, err)
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, key, data, tm.jobTTL)
+			sealed = pipe.GetDel(ctx, negotiationKey(subject, transferID))
+			return nil
+		})
+		if errors.Is(err, redis.Nil) {
+			return nil
+		}
+		return err
+	}, key)
+	if err != nil {
+		return types.TransferJob{}, negotiation{}, err
+	}
+	tm.publish(ctx, subject, tj)
+	n, ok, err := tm.openNegotiation(subject, transferID, sealed)
+	if tj.State == types.TransferStateFailed {
+		if ok {
+			tm.signalTransferComplete(ctx, n.TransferID)
+		}
+		return types.TransferJob{}, negotiation{}, fmt.Errorf("%w: transfer %s %s", types.ErrConflict, transferID, tj.Error)
+	}
+	if err == nil && !ok {
+		err = fmt.Errorf("%w: transfer %s has no negotiated transfer anymore", types.ErrConflict, transferID)
+	}
+	if err != nil {
+		tm.fail(ctx, subject, &tj, err)
+		return types.TransferJob{}, negotiation{}, err
+	}
+	return tj, n, nil
+}
+
+// release changes the job in a transaction, and saves it if change returns true. Unless the
+// content of the job is being read, which completes the DSP transfer when it is done, the
+// negotiated transfer is taken out of redis in the same transaction and completed.
+func (tm *TransferManager) release(
+	ctx context.Context, subject string, transferID uuid.UUID, change func(tj *types.TransferJob) bool,
+) error {
+	ctx = context.WithoutCancel(ctx)
+	key := storageKey(subject, transferID)
+	var (
+		tj      types.TransferJob
+		changed bool
+		sealed  *redis.StringCmd
+	)
+	err := tm.r.Watch(ctx, func(tx *redis.Tx) error {
+		var err error
+		tj, err = tm.load(ctx, tx, subject, transferID)
+		if err != nil {
+			return err
+		}
+		reading := tj.State == types.TransferStateTransferring
+		changed = change(&tj)
+		if changed {
+			tj.UpdatedAt = time.Now().UTC()
+		}
+		data, err := json.Marshal(tj)
+		if err != nil {
+			return fmt.Errorf("couldn't marshal transfer %s: %w", tj.ID, err)
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			if changed {
+				pipe.Set(ctx, key, data, tm.jobTTL)
+			}
+			if !reading {
+				sealed = pipe.GetDel(ctx, negotiationKey(subject, transferID))
+			}
+			return nil
+		})
+		if errors.Is(err, redis.Nil) {
+			return nil
+		}
+		return err
+	}, key)
+	if err != nil {
+		return err
+	}
+	if changed {
+		tm.publish(ctx, subject, tj)
+	}
+	if sealed == nil {
+		return nil
+	}
+	n, ok, err := tm.openNegotiation(subject, transferID, sealed)
+	if err != nil {
+		return err
+	}
+	if ok {
+		tm.signalTransferComplete(ctx, n.TransferID)
+	}
+	return nil
+}
+
+func (tm *TransferManager) releaseLogged(
+	ctx context.Context, subject string, transferID uuid.UUID, change func(tj *types.TransferJob) bool,
+) {
+	if err := tm.release(ctx, subject, transferID, change); err != nil {
+		logging.Extract(ctx).Error("Couldn't complete transfer", "error", err)
+	}
+}
+
+// expire fails a ready download whose content wasn't retrieved within the ready timeout, it
+// returns if it did.
+func (tm *TransferManager) expire(tj *types.TransferJob) bool {
+	if tj.State != types.TransferStateReady || time.Since(tj.UpdatedAt) <= tm.readyTimeout {
+		return false
+	}
+	notRetrieved(tj)
+	return true
+}
+
+func notRetrieved(tj *types.TransferJob) {
+	tj.State = types.TransferStateFailed
+	tj.Error = "content was not retrieved in time"
+}
+
+func (tm *TransferManager) forget(transferID uuid.UUID) {
+	tm.Lock()
+	defer tm.Unlock()
+	if cancel, ok := tm.jobs[transferID]; ok {
+		cancel()
+		delete(tm.jobs, transferID)
+	}
+}
+
+func (tm *TransferManager) fail(ctx context.Context, subject string, tj *types.TransferJob, err error) {
+	logger := logging.Extract(ctx)
+	if ctx.Err() != nil {
+		logger.Info("Transfer cancelled")
+		tj.State = types.TransferStateCancelled
+	} else {
+		logger.Error("Transfer failed", "error", err)
+		tj.State = types.TransferStateFailed
+		tj.Error = err.Error()
+	}
+	tm.update(ctx, subject, tj)
+}
+
+// update saves the job, unless it was cancelled in the meantime, it returns if it did. The job is
+// saved even if its context was cancelled, so shutting down records the cancellation.
+func (tm *TransferManager) update(ctx context.Context, subject string, tj *types.TransferJob) bool {
+	logger := logging.Extract(ctx)
+	ctx = context.WithoutCancel(ctx)
+	if tj.State != types.TransferStateCancelled {
+		stored, err := tm.load(ctx, tm.r, subject, tj.ID)
+		if err == nil && stored.State == types.TransferStateCancelled {
+			return false
+		}
+	}
+	if err := tm.save(ctx, subject, *tj); err != nil {
+		logger.Error("Couldn't save transfer", "error", err)
+	}
+	return true
+}
+
+func (tm *TransferManager) save(ctx context.Context, subject string, tj types.TransferJob) error {
+	tj.UpdatedAt = time.Now().UTC()
+	// The credentials are only handed out with the job itself, they are never stored with it or
+	// sent through the bus.
+	tj.Credentials = nil
+	data, err := json.Marshal(tj)
+	if err != nil {
+		return fmt.Errorf("couldn't marshal transfer %s: %w", tj.ID, err)
+	}
+	if err := tm.r.Set(ctx, storageKey(subject, tj.ID), data, tm.jobTTL).Err(); err != nil {
+		return fmt.Errorf("couldn't save transfer %s: %w", tj.ID, err)
+	}
+	tm.publish(ctx, subject, tj)
+	return nil
+}
+
+// publish sends the job to the user as a transfer progress event.
+func (tm *TransferManager) publish(ctx context.Context, subject string, tj types.TransferJob) {
+	event, err := events.New(events.TypeTransferProgress, subject, tj)
+	if err == nil {
+		err = tm.bus.Publish(ctx, event)
+	}
+	if err != nil {
+		logging.Extract(ctx).Error("Couldn't publish transfer progress", "error", err)
+	}
+}
+
+func (tm *TransferManager) load(
+	ctx context.Context, r redis.Cmdable, subject string, transferID uuid.UUID,
+) (types.TransferJob, error) {
+	data, err := r.Get(ctx, storageKey(subject, transferID)).Result()
+	if errors.Is(err, redis.Nil) {
+		return types.TransferJob{}, fmt.Errorf("%w: transfer %s not found", types.ErrNotFound, transferID)
+	}
+	if err != nil {
+		return types.TransferJob{}, fmt.Errorf("couldn't get transfer %s: %w", transferID, err)
+	}
+	var tj types.TransferJob
+	if err := json.Unmarshal([]byte(data), &tj); err != nil {
+		return types.TransferJob{}, fmt.Errorf("couldn't unmarshal transfer: %w", err)
+	}
+	return tj, nil
+}
+
+// saveNegotiation stores the negotiated transfer of the job encrypted, bound to the job. It is
+// kept a little longer than the ready timeout, so it can still be completed when the job expires.
+func (tm *TransferManager) saveNegotiation(
+	ctx context.Context, subject string, transferID uuid.UUID, n negotiation,
+) error {
+	data, err := json.Marshal(n)
+	if err != nil {
+		return fmt.Errorf("couldn't marshal negotiated transfer of %s: %w", transferID, err)
+	}
+	key := negotiationKey(subject, transferID)
+	nonce := make([]byte, tm.secrets.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("couldn't create nonce: %w", err)
+	}
+	sealed := tm.secrets.Seal(nonce, nonce, data, []byte(key))
+	if err := tm.r.Set(ctx, key, sealed, tm.readyTimeout+negotiationMargin).Err(); err != nil {
+		return fmt.Errorf("couldn't save negotiated transfer of %s: %w", transferID, err)
+	}
+	return nil
+}
+
+// loadNegotiation returns the negotiated transfer of the job, if it wasn't completed yet.
+func (tm *TransferManager) loadNegotiation(
+	ctx context.Context, subject string, transferID uuid.UUID,
+) (negotiation, bool, error) {
+	return tm.openNegotiation(subject, transferID, tm.r.Get(ctx, negotiationKey(subject, transferID)))
+}
+
+// openNegotiation decrypts the negotiated transfer of the job, it returns false if there is none.
+func (tm *TransferManager) openNegotiation(
+	subject string, transferID uuid.UUID, sealed *redis.StringCmd,
+) (negotiation, bool, error) {
+	data, err := sealed.Bytes()
+	if errors.Is(err, redis.Nil) {
+		return negotiation{}, false, nil
+	}
+	if err != nil {
+		return negotiation{}, false, fmt.Errorf("couldn't get negotiated transfer of %s: %w", transferID, err)
+	}
+	size := tm.secrets.NonceSize()
+	if len(data) < size {
+		return negotiation{}, false, fmt.Errorf("negotiated transfer of %s is truncated", transferID)
+	}
+	data, err = tm.secrets.Open(nil, data[:size], data[size:], []byte(negotiationKey(subject, transferID)))
+	if err != nil {
+		return negotiation{}, false, fmt.Errorf("couldn't decrypt negotiated transfer of %s: %w", transferID, err)
+	}
+	var n negotiation
+	if err := json.Unmarshal(data, &n); err != nil {
+		return negotiation{}, false, fmt.Errorf("couldn't unmarshal negotiated transfer of %s: %w", transferID, err)
+	}
+	return n, true, nil
+}
+
+// progressReader saves the progress of a download while it is read, and its final state when it
+// is closed, which also completes the DSP transfer. Reading fails once the job was cancelled.
+type progressReader struct {
+	io.ReadCloser
+	ctx        context.Context
+	tm         *TransferManager
+	subject    string
+	job        types.TransferJob
+	transferID string
+	lastSaved  time.Time
+	eof        bool
+	readErr    error
+	once       sync.Once
+}
+
+func (pr *progressReader) Read(p []byte) (int, error) {
+	if errors.Is(pr.readErr, errCancelled) {
+		return 0, pr.readErr
+	}
+	n, err := pr.ReadCloser.Read(p)
+	pr.job.BytesTransferred += int64(n)
+	switch {
+	case err == io.EOF:
+		pr.eof = true
+	case err != nil:
+		pr.readErr = err
+	}
+	if time.Since(pr.lastSaved) >= progressInterval {
+		pr.lastSaved = time.Now()
+		if !pr.tm.update(pr.ctx, pr.subject, &pr.job) {
+			pr.readErr = errCancelled
+			return n, errCancelled
+		}
+	}
+	return n, err
+}
+
+func (pr *progressReader) Close() error {
+	err := pr.ReadCloser.Close()
+	pr.once.Do(func() {
+		defer pr.tm.signalTransferComplete(pr.ctx, pr.transferID)
+		switch {
+		case errors.Is(pr.readErr, errCancelled):
+			return
+		case pr.eof:
+			pr.job.State = types.TransferStateCompleted
+		case pr.readErr != nil:
+			pr.job.State = types.TransferStateFailed
+			pr.job.Error = pr.readErr.Error()
+		default:
+			pr.job.State = types.TransferStateFailed
+			pr.job.Error = "download was aborted"
+		}
+		pr.tm.update(pr.ctx, pr.subject, &pr.job)
+	})
+	return err
+}
+
+func storageKey(subject string, transferID uuid.UUID) string {
+	return fmt.Sprintf("%s:%s:%s", storageKeyPrefix, subject, transferID)
+}
+
+func negotiationKey(subject string, transferID uuid.UUID) string {
+	return storageKey(subject, transferID) + ":negotiation"
+}
@@ -0,0 +1,395 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tmredis_test
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	mtypes "github.com/HEALTH-X-dataLOFT/cma-backend/mocks/github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/types"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/events"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/identity"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/middleware/authforwarder"
+	tmredis "github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/implementations/transfermanagers/redis"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/types"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/waitgroup"
+	"github.com/alecthomas/assert/v2"
+	"github.com/alicebob/miniredis/v2"
+	dspclient "github.com/go-dataspace/run-dsrpc/gen/go/dsp/v1alpha1"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc"
+)
+
+var (
+	fileID     = uuid.MustParse("8e8a7bb5-5d4b-4a38-b5d4-f3ad1dc05e6c")
+	target     = types.Target{ProviderID: "provider-a", FileID: fileID}
+	provider   = types.Provider{ID: "provider-a", ProviderUrl: "https://provider-a.example.com/dsp"}
+	transferID = "transfer-" + fileID.String()
+)
+
+// fakeDSP negotiates transfers of the files published by a test server, like run-dsp would.
+type fakeDSP struct {
+	dspclient.ClientServiceClient
+	sync.Mutex
+	publishURL string
+	// negotiateErr fails the negotiation of transfers.
+	negotiateErr error
+	providers    []string
+	negotiated   int
+	completed    []string
+}
+
+func (d *fakeDSP) GetProviderDatasetDownloadInformation(
+	ctx context.Context, in *dspclient.GetProviderDatasetDownloadInformationRequest, _ ...grpc.CallOption,
+) (*dspclient.GetProviderDatasetDownloadInformationResponse, error) {
+	d.Lock()
+	defer d.Unlock()
+	d.providers = append(d.providers, authforwarder.ExtractProvider(ctx))
+	if d.negotiateErr != nil {
+		return nil, d.negotiateErr
+	}
+	d.negotiated++
+	return &dspclient.GetProviderDatasetDownloadInformationResponse{
+		TransferId: "transfer-" + in.DatasetId,
+		PublishInfo: &dspclient.PublishInfo{
+			Url:                d.publishURL + "/" + in.DatasetId,
+			AuthenticationType: dspclient.AuthenticationType_AUTHENTICATION_TYPE_BEARER,
+			Password:           "publish-token",
+		},
+	}, nil
+}
+
+func (d *fakeDSP) SignalTransferComplete(
+	_ context.Context, in *dspclient.SignalTransferCompleteRequest, _ ...grpc.CallOption,
+) (*dspclient.SignalTransferCompleteResponse, error) {
+	d.Lock()
+	defer d.Unlock()
+	d.completed = append(d.completed, in.TransferId)
+	return &dspclient.SignalTransferCompleteResponse{}, nil
+}
+
+func (d *fakeDSP) negotiations() int {
+	d.Lock()
+	defer d.Unlock()
+	return d.negotiated
+}
+
+func (d *fakeDSP) completedTransfers() []string {
+	d.Lock()
+	defer d.Unlock()
+	return append([]string(nil), d.completed...)
+}
+
+type env struct {
+	ctx context.Context
+	mr  *miniredis.Miniredis
+	dsp *fakeDSP
+	dc  *mtypes.MockDataspaceConnector
+	bus *events.LocalBus
+	// newManager creates a transfer manager sharing the redis server, like another replica.
+	newManager func(readyTimeout time.Duration) *tmredis.TransferManager
+}
+
+// setup creates the environment of the tests, published serves the files of the provider.
+func setup(t *testing.T, published http.HandlerFunc) env {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	pl := mtypes.NewMockProviderLister(t)
+	pl.On("GetProvider", mock.Anything, provider.ID).Return(provider, nil).Maybe()
+	dc := mtypes.NewMockDataspaceConnector(t)
+	publishedSrv := httptest.NewServer(published)
+	t.Cleanup(publishedSrv.Close)
+	dsp := &fakeDSP{publishURL: publishedSrv.URL}
+	bus := events.NewLocal()
+	block, err := aes.NewCipher(make([]byte, 32))
+	assert.NoError(t, err)
+	secrets, err := cipher.NewGCM(block)
+	assert.NoError(t, err)
+
+	wg := &sync.WaitGroup{}
+	serverCtx, cancel := context.WithCancel(waitgroup.Inject(context.Background(), wg))
+	t.Cleanup(func() {
+		cancel()
+		wg.Wait()
+	})
+	ctx := identity.Inject(context.Background(), identity.Identity{Subject: "user-a"})
+	return env{
+		ctx: ctx,
+		mr:  mr,
+		dsp: dsp,
+		dc:  dc,
+		bus: bus,
+		newManager: func(readyTimeout time.Duration) *tmredis.TransferManager {
+			r := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+			t.Cleanup(func() { r.Close() })
+			return tmredis.New(serverCtx, r, dsp, pl, dc, bus, secrets, time.Hour, readyTimeout)
+		},
+	}
+}
+
+// serve publishes content, if the request has the token negotiated for it.
+func serve(content string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer publish-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = io.WriteString(w, content)
+	}
+}
+
+func waitState(
+	t *testing.T, ctx context.Context, tm *tmredis.TransferManager, id uuid.UUID, want types.TransferState,
+) types.TransferJob {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		tj, err := tm.GetTransfer(ctx, id)
+		assert.NoError(t, err)
+		if tj.State == want {
+			return tj
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("transfer is %s, want %s", tj.State, want)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func eventually(t *testing.T, cond func() bool, msg string) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal(msg)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func (e env) expectFileInfo(size int64) {
+	e.dc.EXPECT().GetProviderFileInfo(mock.Anything, "provider-a", fileID.String()).Return(
+		types.ProviderFile{ID: fileID.String(), Name: "scan.dcm", MimeType: "application/dicom", Size: size}, nil,
+	)
+}
+
+func TestDownloadOnOtherReplica(t *testing.T) {
+	e := setup(t, serve("content"))
+	e.expectFileInfo(7)
+
+	a := e.newManager(time.Minute)
+	b := e.newManager(time.Minute)
+	tj, err := a.StartTransfer(e.ctx, types.TransferRequest{Kind: types.TransferKindDownload, Target: target})
+	assert.NoError(t, err)
+	waitState(t, e.ctx, b, tj.ID, types.TransferStateReady)
+	assert.Equal(t, []string{"provider-a"}, e.dsp.providers)
+
+	content, tj, err := b.OpenTransferContent(e.ctx, tj.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "scan.dcm", tj.Name)
+	assert.Equal(t, int64(7), tj.Size)
+
+	// The content can only be opened once, on any replica.
+	_, _, err = a.OpenTransferContent(e.ctx, tj.ID)
+	assert.IsError(t, err, types.ErrConflict)
+
+	data, err := io.ReadAll(content)
+	assert.NoError(t, err)
+	assert.Equal(t, "content", string(data))
+	assert.Equal(t, 0, len(e.dsp.completedTransfers()))
+	assert.NoError(t, content.Close())
+
+	tj = waitState(t, e.ctx, a, tj.ID, types.TransferStateCompleted)
+	assert.Equal(t, int64(7), tj.BytesTransferred)
+	assert.Equal(t, 1, e.dsp.negotiations())
+	assert.Equal(t, []string{transferID}, e.dsp.completedTransfers())
+}
+
+func TestDownloadCancelledOnOtherReplica(t *testing.T) {
+	e := setup(t, serve(strings.Repeat("x", 1024)))
+	e.expectFileInfo(-1)
+
+	a := e.newManager(time.Minute)
+	b := e.newManager(time.Minute)
+	tj, err := a.StartTransfer(e.ctx, types.TransferRequest{Kind: types.TransferKindDownload, Target: target})
+	assert.NoError(t, err)
+	waitState(t, e.ctx, b, tj.ID, types.TransferStateReady)
+	content, _, err := b.OpenTransferContent(e.ctx, tj.ID)
+	assert.NoError(t, err)
+
+	assert.NoError(t, a.CancelTransfer(e.ctx, tj.ID))
+	_, err = content.Read(make([]byte, 16))
+	assert.Error(t, err)
+	assert.NoError(t, content.Close())
+	waitState(t, e.ctx, a, tj.ID, types.TransferStateCancelled)
+	assert.Equal(t, []string{transferID}, e.dsp.completedTransfers())
+}
+
+func TestDownloadCancelledWhenReady(t *testing.T) {
+	e := setup(t, serve("content"))
+	e.expectFileInfo(7)
+
+	a := e.newManager(time.Minute)
+	b := e.newManager(time.Minute)
+	tj, err := a.StartTransfer(e.ctx, types.TransferRequest{Kind: types.TransferKindDownload, Target: target})
+	assert.NoError(t, err)
+	waitState(t, e.ctx, b, tj.ID, types.TransferStateReady)
+
+	assert.NoError(t, b.CancelTransfer(e.ctx, tj.ID))
+	waitState(t, e.ctx, a, tj.ID, types.TransferStateCancelled)
+	eventually(t, func() bool { return len(e.dsp.completedTransfers()) > 0 }, "transfer was not completed")
+	_, _, err = a.OpenTransferContent(e.ctx, tj.ID)
+	assert.IsError(t, err, types.ErrConflict)
+	assert.Equal(t, []string{transferID}, e.dsp.completedTransfers())
+}
+
+func TestDownloadNotRetrievedInTime(t *testing.T) {
+	e := setup(t, serve("content"))
+	e.expectFileInfo(7)
+
+	tm := e.newManager(0)
+	tj, err := tm.StartTransfer(e.ctx, types.TransferRequest{Kind: types.TransferKindDownload, Target: target})
+	assert.NoError(t, err)
+	tj = waitState(t, e.ctx, tm, tj.ID, types.TransferStateFailed)
+	assert.Equal(t, "content was not retrieved in time", tj.Error)
+	eventually(t, func() bool { return len(e.dsp.completedTransfers()) > 0 }, "transfer was not completed")
+
+	_, _, err = tm.OpenTransferContent(e.ctx, tj.ID)
+	assert.IsError(t, err, types.ErrConflict)
+	assert.Equal(t, []string{transferID}, e.dsp.completedTransfers())
+}
+
+func TestDownloadFromProviderFailed(t *testing.T) {
+	e := setup(t, func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusNotFound) })
+	e.expectFileInfo(7)
+
+	tm := e.newManager(time.Minute)
+	tj, err := tm.StartTransfer(e.ctx, types.TransferRequest{Kind: types.TransferKindDownload, Target: target})
+	assert.NoError(t, err)
+	waitState(t, e.ctx, tm, tj.ID, types.TransferStateReady)
+
+	_, _, err = tm.OpenTransferContent(e.ctx, tj.ID)
+	assert.IsError(t, err, types.ErrBadGateway)
+	waitState(t, e.ctx, tm, tj.ID, types.TransferStateFailed)
+	assert.Equal(t, []string{transferID}, e.dsp.completedTransfers())
+}
+
+func TestDownloadFailed(t *testing.T) {
+	tests := []struct {
+		name         string
+		infoErr      error
+		negotiateErr error
+		wantErr      string
+	}{
+		{name: "FileInfo", infoErr: errors.New("provider unavailable"), wantErr: "provider unavailable"},
+		{
+			name:         "Negotiation",
+			negotiateErr: errors.New("provider refused"),
+			wantErr:      "couldn't negotiate transfer with provider: provider refused",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := setup(t, serve("content"))
+			e.dsp.negotiateErr = tt.negotiateErr
+			e.dc.EXPECT().GetProviderFileInfo(mock.Anything, "provider-a", fileID.String()).Return(
+				types.ProviderFile{ID: fileID.String(), Name: "scan.dcm", Size: 7}, tt.infoErr,
+			)
+
+			tm := e.newManager(time.Minute)
+			tj, err := tm.StartTransfer(
+				e.ctx, types.TransferRequest{Kind: types.TransferKindDownload, Target: target},
+			)
+			assert.NoError(t, err)
+			tj = waitState(t, e.ctx, tm, tj.ID, types.TransferStateFailed)
+			assert.Equal(t, tt.wantErr, tj.Error)
+			assert.Equal(t, 0, e.dsp.negotiations())
+		})
+	}
+}
+
+func TestCredentialsNotStored(t *testing.T) {
+	e := setup(t, serve("content"))
+	e.expectFileInfo(7)
+
+	subCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sub := e.bus.Subscribe(subCtx, "user-a")
+
+	tm := e.newManager(time.Minute)
+	tj, err := tm.StartTransfer(e.ctx, types.TransferRequest{Kind: types.TransferKindCredentials, Target: target})
+	assert.NoError(t, err)
+	tj = waitState(t, e.ctx, tm, tj.ID, types.TransferStateCompleted)
+	want := &types.DownloadCredentials{
+		AuthenticationType: int64(dspclient.AuthenticationType_AUTHENTICATION_TYPE_BEARER),
+		URL:                e.dsp.publishURL + "/" + fileID.String(),
+		Password:           "publish-token",
+	}
+	assert.Equal(t, want, tj.Credentials)
+
+	// Every poll returns the credentials of the same transfer.
+	tj, err = e.newManager(time.Minute).GetTransfer(e.ctx, tj.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, want, tj.Credentials)
+	assert.Equal(t, 1, e.dsp.negotiations())
+
+	for _, key := range e.mr.Keys() {
+		data, err := e.mr.Get(key)
+		assert.NoError(t, err)
+		assert.NotContains(t, data, "publish-token")
+	}
+	cancel()
+	for event := range sub {
+		assert.NotContains(t, string(event.Data), "publish-token")
+	}
+}
+
+func TestCredentialsExpire(t *testing.T) {
+	e := setup(t, serve("content"))
+	e.expectFileInfo(7)
+
+	tm := e.newManager(0)
+	tj, err := tm.StartTransfer(e.ctx, types.TransferRequest{Kind: types.TransferKindCredentials, Target: target})
+	assert.NoError(t, err)
+	waitState(t, e.ctx, tm, tj.ID, types.TransferStateCompleted)
+	eventually(t, func() bool { return len(e.dsp.completedTransfers()) > 0 }, "transfer was not completed")
+
+	tj, err = tm.GetTransfer(e.ctx, tj.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, types.TransferStateCompleted, tj.State)
+	assert.Zero(t, tj.Credentials)
+	assert.Equal(t, []string{transferID}, e.dsp.completedTransfers())
+}
+
+func TestTransferWithoutUser(t *testing.T) {
+	e := setup(t, serve("content"))
+	tm := e.newManager(time.Minute)
+	_, err := tm.StartTransfer(
+		context.Background(), types.TransferRequest{Kind: types.TransferKindDownload, Target: target},
+	)
+	assert.IsError(t, err, types.ErrInvalidCredentials)
+}
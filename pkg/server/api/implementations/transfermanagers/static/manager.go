@@ -0,0 +1,329 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package static contains an in-memory transfer manager implementation, made for basic testing.
+// It runs the transfer jobs right away, instead of in the background.
+package static
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/events"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/identity"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/logging"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/types"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer trace.Tracer
+
+func init() {
+	tracer = otel.Tracer(
+		"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/implementations/transfermanagers/static",
+	)
+}
+
+type job struct {
+	tj types.TransferJob
+	// credentials are fetched once, when the job is run, and only handed out with the job.
+	credentials *types.DownloadCredentials
+}
+
+// TransferManager keeps the jobs in memory, they are lost on restart. Every update of a job is
+// published to the user as a transfer progress event, without the download credentials.
+type TransferManager struct {
+	sync.Mutex
+	dc           types.DataspaceConnector
+	bus          events.Bus
+	readyTimeout time.Duration
+	jobs         map[string]map[uuid.UUID]*job
+}
+
+func New(dc types.DataspaceConnector, bus events.Bus, readyTimeout time.Duration) *TransferManager {
+	return &TransferManager{
+		dc:           dc,
+		bus:          bus,
+		readyTimeout: readyTimeout,
+		jobs:         make(map[string]map[uuid.UUID]*job),
+	}
+}
+
+// StartTransfer checks the file of the job, and fetches the credentials of a credentials job,
+// before it returns.
+func (tm *TransferManager) StartTransfer(
+	ctx context.Context, transfer types.TransferRequest,
+) (types.TransferJob, error) {
+	logger := logging.Extract(ctx)
+	ctx, span := tracer.Start(ctx, "StaticTransferManager.StartTransfer")
+	defer span.End()
+
+	subject, err := identity.Subject(ctx)
+	if err != nil {
+		return types.TransferJob{}, err
+	}
+	now := time.Now().UTC()
+	j := &job{tj: types.TransferJob{
+		ID:        uuid.New(),
+		Kind:      transfer.Kind,
+		Target:    transfer.Target,
+		State:     types.TransferStateNegotiating,
+		Size:      -1,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}}
+	logger = logger.With("transfer_id", j.tj.ID, "kind", j.tj.Kind)
+	logger.Info("Starting transfer")
+	tm.run(ctx, j)
+
+	tm.Lock()
+	defer tm.Unlock()
+	if tm.jobs[subject] == nil {
+		tm.jobs[subject] = make(map[uuid.UUID]*job)
+	}
+	tm.jobs[subject][j.tj.ID] = j
+	tm.publish(ctx, subject, j.tj)
+	return j.with(), nil
+}
+
+// GetTransfer returns the current state of the job, a completed credentials job with its
+// credentials.
+func (tm *TransferManager) GetTransfer(ctx context.Context, transferID uuid.UUID) (types.TransferJob, error) {
+	logger := logging.Extract(ctx)
+	logger.Info("Getting transfer", "transfer_id", transferID)
+	_, span := tracer.Start(ctx, "StaticTransferManager.GetTransfer")
+	defer span.End()
+
+	subject, err := identity.Subject(ctx)
+	if err != nil {
+		return types.TransferJob{}, err
+	}
+	tm.Lock()
+	defer tm.Unlock()
+	j, err := tm.get(ctx, subject, transferID)
+	if err != nil {
+		return types.TransferJob{}, err
+	}
+	return j.with(), nil
+}
+
+// CancelTransfer cancels the job, a download that is being read is aborted.
+func (tm *TransferManager) CancelTransfer(ctx context.Context, transferID uuid.UUID) error {
+	logger := logging.Extract(ctx)
+	logger.Info("Cancelling transfer", "transfer_id", transferID)
+	_, span := tracer.Start(ctx, "StaticTransferManager.CancelTransfer")
+	defer span.End()
+
+	subject, err := identity.Subject(ctx)
+	if err != nil {
+		return err
+	}
+	tm.Lock()
+	defer tm.Unlock()
+	j, err := tm.get(ctx, subject, transferID)
+	if err != nil {
+		return err
+	}
+	if !j.tj.State.Final() {
+		tm.set(ctx, subject, j, types.TransferStateCancelled, "")
+	}
+	return nil
+}
+
+// OpenTransferContent streams the content of a ready download from the provider, once.
+func (tm *TransferManager) OpenTransferContent(
+	ctx context.Context, transferID uuid.UUID,
+) (io.ReadCloser, types.TransferJob, error) {
+	logger := logging.Extract(ctx)
+	logger.Info("Opening transfer content", "transfer_id", transferID)
+	ctx, span := tracer.Start(ctx, "StaticTransferManager.OpenTransferContent")
+	defer span.End()
+
+	subject, err := identity.Subject(ctx)
+	if err != nil {
+		return nil, types.TransferJob{}, err
+	}
+	tm.Lock()
+	j, err := tm.get(ctx, subject, transferID)
+	if err == nil && j.tj.Kind != types.TransferKindDownload {
+		err = fmt.Errorf("%w: transfer %s has no content", types.ErrConflict, transferID)
+	}
+	if err == nil && j.tj.State != types.TransferStateReady {
+		err = fmt.Errorf(
+			"%w: content of transfer %s is not available, it is %s", types.ErrConflict, transferID, j.tj.State,
+		)
+	}
+	if err != nil {
+		tm.Unlock()
+		return nil, types.TransferJob{}, err
+	}
+	tm.set(ctx, subject, j, types.TransferStateTransferring, "")
+	target := j.tj.Target
+	tm.Unlock()
+
+	content, meta, err := tm.dc.GetProviderFile(ctx, target.ProviderID, target.FileID.String(), types.FileRange{})
+	tm.Lock()
+	defer tm.Unlock()
+	if err != nil {
+		if j.tj.State == types.TransferStateTransferring {
+			tm.set(ctx, subject, j, types.TransferStateFailed, err.Error())
+		}
+		return nil, types.TransferJob{}, err
+	}
+	if meta.Size >= 0 {
+		j.tj.Size = meta.Size
+	}
+	return &contentReader{
+		ReadCloser: content,
+		ctx:        context.WithoutCancel(ctx),
+		tm:         tm,
+		subject:    subject,
+		job:        j,
+	}, j.tj, nil
+}
+
+// run checks the file of the job, and fetches the credentials of a credentials job.
+func (tm *TransferManager) run(ctx context.Context, j *job) {
+	logger := logging.Extract(ctx)
+	fail := func(err error) {
+		logger.Error("Transfer failed", "error", err)
+		j.tj.State = types.TransferStateFailed
+		j.tj.Error = err.Error()
+	}
+
+	info, err := tm.dc.GetProviderFileInfo(ctx, j.tj.Target.ProviderID, j.tj.Target.FileID.String())
+	if err != nil {
+		fail(err)
+		return
+	}
+	j.tj.Name = info.Name
+	j.tj.MimeType = info.MimeType
+	if info.Size > 0 {
+		j.tj.Size = info.Size
+	}
+	switch j.tj.Kind {
+	case types.TransferKindCredentials:
+		creds, err := tm.dc.GetDownloadCredentials(ctx, j.tj.Target.ProviderID, j.tj.Target.FileID.String())
+		if err != nil {
+			fail(err)
+			return
+		}
+		j.credentials = &creds
+		j.tj.State = types.TransferStateCompleted
+		logger.Info("Transfer completed")
+	case types.TransferKindDownload:
+		j.tj.State = types.TransferStateReady
+		logger.Info("Transfer ready")
+	}
+	j.tj.UpdatedAt = time.Now().UTC()
+}
+
+// get returns the job of the user, a ready download that wasn't retrieved in time is failed. The
+// caller has to hold the lock.
+func (tm *TransferManager) get(ctx context.Context, subject string, transferID uuid.UUID) (*job, error) {
+	j, ok := tm.jobs[subject][transferID]
+	if !ok {
+		return nil, fmt.Errorf("%w: transfer %s not found", types.ErrNotFound, transferID)
+	}
+	if j.tj.State == types.TransferStateReady && time.Since(j.tj.UpdatedAt) > tm.readyTimeout {
+		tm.set(ctx, subject, j, types.TransferStateFailed, "content was not retrieved in time")
+	}
+	return j, nil
+}
+
+// set changes the state of the job and publishes it. The caller has to hold the lock.
+func (tm *TransferManager) set(
+	ctx context.Context, subject string, j *job, state types.TransferState, errMsg string,
+) {
+	j.tj.State = state
+	j.tj.Error = errMsg
+	j.tj.UpdatedAt = time.Now().UTC()
+	tm.publish(ctx, subject, j.tj)
+}
+
+// publish sends the job to the user as a transfer progress event.
+func (tm *TransferManager) publish(ctx context.Context, subject string, tj types.TransferJob) {
+	event, err := events.New(events.TypeTransferProgress, subject, tj)
+	if err == nil {
+		err = tm.bus.Publish(ctx, event)
+	}
+	if err != nil {
+		logging.Extract(ctx).Error("Couldn't publish transfer progress", "error", err)
+	}
+}
+
+// with returns the job with its credentials.
+func (j *job) with() types.TransferJob {
+	tj := j.tj
+	tj.Credentials = j.credentials
+	return tj
+}
+
+// contentReader counts the bytes read of a download, and saves its final state when it is closed.
+// Reading fails once the job was cancelled.
+type contentReader struct {
+	io.ReadCloser
+	ctx     context.Context
+	tm      *TransferManager
+	subject string
+	job     *job
+	eof     bool
+	readErr error
+	once    sync.Once
+}
+
+func (cr *contentReader) Read(p []byte) (int, error) {
+	cr.tm.Lock()
+	cancelled := cr.job.tj.State == types.TransferStateCancelled
+	cr.tm.Unlock()
+	if cancelled {
+		return 0, fmt.Errorf("transfer %s was cancelled", cr.job.tj.ID)
+	}
+	n, err := cr.ReadCloser.Read(p)
+	cr.tm.Lock()
+	cr.job.tj.BytesTransferred += int64(n)
+	cr.tm.Unlock()
+	switch {
+	case err == io.EOF:
+		cr.eof = true
+	case err != nil:
+		cr.readErr = err
+	}
+	return n, err
+}
+
+func (cr *contentReader) Close() error {
+	err := cr.ReadCloser.Close()
+	cr.once.Do(func() {
+		cr.tm.Lock()
+		defer cr.tm.Unlock()
+		switch {
+		case cr.job.tj.State != types.TransferStateTransferring:
+			return
+		case cr.eof:
+			cr.tm.set(cr.ctx, cr.subject, cr.job, types.TransferStateCompleted, "")
+		case cr.readErr != nil:
+			cr.tm.set(cr.ctx, cr.subject, cr.job, types.TransferStateFailed, cr.readErr.Error())
+		default:
+			cr.tm.set(cr.ctx, cr.subject, cr.job, types.TransferStateFailed, "download was aborted")
+		}
+	})
+	return err
+}
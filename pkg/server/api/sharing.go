@@ -0,0 +1,91 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/logging"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/middleware/authforwarder"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/types"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// postShare publishes a file for sharing with non-dataspace apps.
+func (r *Routes) postShare(c *gin.Context) {
+	var share types.ShareRequest
+	logger := logging.Extract(c)
+	if err := c.ShouldBindJSON(&share); err != nil {
+		logger.Error("Could not parse request", "error", err)
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Status: "Could not parse request",
+			Error:  "The request could not be parsed",
+		})
+		return
+	}
+	if checkError(c, share.Validate()) {
+		return
+	}
+	resp, err := r.sm.SubmitShare(c.Request.Context(), share)
+	if checkError(c, err) {
+		return
+	}
+	// Without a configured public base URL, the download URI is relative to this request.
+	if strings.HasPrefix(resp.DownloadURI, "/") {
+		resp.DownloadURI = requestBaseURL(c) + resp.DownloadURI
+	}
+	c.JSON(http.StatusCreated, resp)
+}
+
+// requestBaseURL returns the base URL the request was sent to, as far as a reverse proxy tells.
+// The forwarded headers are removed by middleware.ForwardedHeaders unless they come from a trusted
+// proxy.
+func requestBaseURL(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if proto := c.GetHeader("X-Forwarded-Proto"); proto == "http" || proto == "https" {
+		scheme = proto
+	}
+	host := c.Request.Host
+	if forwarded := c.GetHeader("X-Forwarded-Host"); forwarded != "" {
+		host = forwarded
+	}
+	return fmt.Sprintf("%s://%s", scheme, host)
+}
+
+// getSharedFile returns a shared file, authenticated by the bearer token handed out for the share.
+func (r *Routes) getSharedFile(c *gin.Context) {
+	i := c.Param("share_id")
+	shareID := parseID(c, i, "share")
+	if shareID == (uuid.UUID{}) {
+		return
+	}
+	token := authforwarder.BearerToken(c.GetHeader("Authorization"))
+	file, err := r.sm.GetSharedFile(c.Request.Context(), shareID, token)
+	if checkError(c, err) {
+		return
+	}
+	defer file.Content.Close()
+	c.DataFromReader(
+		http.StatusOK, file.Size, file.MimeType, file.Content,
+		map[string]string{"Content-Disposition": fmt.Sprintf("attachment; filename=\"%s\"", file.Name)},
+	)
+}
@@ -0,0 +1,95 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/logging"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/types"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// postTransfer starts a transfer job in the background.
+func (r *Routes) postTransfer(c *gin.Context) {
+	var transfer types.TransferRequest
+	logger := logging.Extract(c)
+	if err := c.ShouldBindJSON(&transfer); err != nil {
+		logger.Error("Could not parse request", "error", err)
+		c.JSON(http.StatusBadRequest, types.ErrorResponse{
+			Status: "Could not parse request",
+			Error:  "The request could not be parsed",
+		})
+		return
+	}
+	if checkError(c, transfer.Validate()) {
+		return
+	}
+	job, err := r.tm.StartTransfer(c.Request.Context(), transfer)
+	if checkError(c, err) {
+		return
+	}
+	c.JSON(http.StatusAccepted, job)
+}
+
+// getTransfer returns the state of the transfer job with the given ID.
+func (r *Routes) getTransfer(c *gin.Context) {
+	transferID := parseID(c, c.Param("transfer_id"), "transfer")
+	if transferID == (uuid.UUID{}) {
+		return
+	}
+	job, err := r.tm.GetTransfer(c.Request.Context(), transferID)
+	if checkError(c, err) {
+		return
+	}
+	c.JSON(http.StatusOK, job)
+}
+
+// deleteTransfer cancels the transfer job with the given ID.
+func (r *Routes) deleteTransfer(c *gin.Context) {
+	transferID := parseID(c, c.Param("transfer_id"), "transfer")
+	if transferID == (uuid.UUID{}) {
+		return
+	}
+	if checkError(c, r.tm.CancelTransfer(c.Request.Context(), transferID)) {
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// getTransferContent streams the file of a ready download job.
+func (r *Routes) getTransferContent(c *gin.Context) {
+	transferID := parseID(c, c.Param("transfer_id"), "transfer")
+	if transferID == (uuid.UUID{}) {
+		return
+	}
+	content, job, err := r.tm.OpenTransferContent(c.Request.Context(), transferID)
+	if checkError(c, err) {
+		return
+	}
+	defer content.Close()
+
+	mimeType := job.MimeType
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+	c.DataFromReader(
+		http.StatusOK, job.Size, mimeType, content,
+		map[string]string{"Content-Disposition": fmt.Sprintf("attachment; filename=\"%s\"", job.Name)},
+	)
+}
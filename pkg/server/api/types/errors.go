@@ -18,8 +18,10 @@ package types
 import "errors"
 
 var (
-	ErrNotFound           = errors.New("not found")
-	ErrInvalid            = errors.New("invalid")
-	ErrInvalidCredentials = errors.New("invalid credentials")
-	ErrBadGateway         = errors.New("bad gateway")
+	ErrNotFound            = errors.New("not found")
+	ErrInvalid             = errors.New("invalid")
+	ErrInvalidCredentials  = errors.New("invalid credentials")
+	ErrBadGateway          = errors.New("bad gateway")
+	ErrRangeNotSatisfiable = errors.New("range not satisfiable")
+	ErrConflict            = errors.New("conflict")
 )
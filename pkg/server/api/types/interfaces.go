@@ -17,7 +17,10 @@ package types
 
 import (
 	"context"
+	"io"
+	"time"
 
+	"github.com/go-jose/go-jose/v4"
 	"github.com/google/uuid"
 )
 
@@ -26,6 +29,16 @@ type ProviderLister interface {
 	ListProviders(ctx context.Context) ([]Provider, error)
 	GetProvider(ctx context.Context, providerID string) (Provider, error)
 	GetProviderURL(ctx context.Context, providerID string) (string, error)
+	// GetProviderKeys returns the public keys of the provider, current key first.
+	GetProviderKeys(ctx context.Context, providerID string) ([]jose.JSONWebKey, error)
+}
+
+// ProviderSnapshotter is implemented by provider listers that list providers from a snapshot of
+// their source, which is updated periodically.
+type ProviderSnapshotter interface {
+	// SnapshotUpdatedAt returns when the snapshot was last updated, or the zero time if it never
+	// was.
+	SnapshotUpdatedAt(ctx context.Context) (time.Time, error)
 }
 
 // StudyLister is an interface for looking up studies.
@@ -39,8 +52,11 @@ type StudyLister interface {
 type DataspaceConnector interface {
 	ListProviderFiles(ctx context.Context, providerID string) ([]ProviderFile, error)
 	GetProviderFileInfo(ctx context.Context, providerID string, fileID string) (ProviderFile, error)
-	// TODO: This should return a reader, not bytes, as it might be a large file.
-	GetProviderFile(ctx context.Context, providerID string, fileID string) ([]byte, error)
+	// GetProviderFile streams the file, or the requested range of it. The caller has to close the
+	// returned reader.
+	GetProviderFile(
+		ctx context.Context, providerID string, fileID string, fileRange FileRange,
+	) (io.ReadCloser, FileMetadata, error)
 	GetDownloadCredentials(ctx context.Context, providerID string, fileID string) (DownloadCredentials, error)
 }
 
@@ -54,6 +70,37 @@ type AccessManager interface {
 // ShareManager is an interfacd for managing sharing between non-dataspace entities and data.
 type ShareManager interface {
 	SubmitShare(ctx context.Context, share ShareRequest) (ShareResponse, error)
+	GetSharedFile(ctx context.Context, shareID uuid.UUID, bearerToken string) (SharedFile, error)
+}
+
+// ConsentManager is an interface for managing the consents of a user to participate in studies.
+type ConsentManager interface {
+	ListConsents(ctx context.Context) ([]Consent, error)
+	ListStudyConsents(ctx context.Context, studyID uuid.UUID) ([]Consent, error)
+	SubmitConsent(ctx context.Context, studyID uuid.UUID, consent ConsentRequest) (Consent, error)
+	WithdrawConsent(ctx context.Context, studyID uuid.UUID, consentID uuid.UUID) error
+}
+
+// ContributionOrchestrator is an interface for transferring the files a user consented to share
+// from their providers to the study.
+type ContributionOrchestrator interface {
+	// StartContribution starts transferring the files in the background, the returned contribution
+	// contains the initial state of all files.
+	StartContribution(ctx context.Context, consent Consent, files []ProviderFile) (Contribution, error)
+	GetContribution(ctx context.Context, consentID uuid.UUID) (Contribution, error)
+	// CancelContribution stops transferring the files that haven't been transferred yet.
+	CancelContribution(ctx context.Context, consentID uuid.UUID) error
+}
+
+// TransferManager is an interface for running transfers in the background, so that slow DSP
+// negotiations don't block requests.
+type TransferManager interface {
+	StartTransfer(ctx context.Context, transfer TransferRequest) (TransferJob, error)
+	GetTransfer(ctx context.Context, transferID uuid.UUID) (TransferJob, error)
+	CancelTransfer(ctx context.Context, transferID uuid.UUID) error
+	// OpenTransferContent returns the content of a ready download, it can only be opened once. The
+	// caller has to close the returned reader.
+	OpenTransferContent(ctx context.Context, transferID uuid.UUID) (io.ReadCloser, TransferJob, error)
 }
 
 // Validator is the interface all validator implementations must implement.
@@ -19,8 +19,10 @@ package types
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/minio/minio-go/v7"
@@ -38,6 +40,19 @@ const (
 	AccessTypePseudonymized
 )
 
+func (at AccessType) String() string {
+	switch at {
+	case AccessTypeFull:
+		return "full"
+	case AccessTypeAnonymized:
+		return "anonymized"
+	case AccessTypePseudonymized:
+		return "pseudonymized"
+	default:
+		return fmt.Sprintf("AccessType(%d)", int(at))
+	}
+}
+
 // Provider represents a provider of a resource.
 type Provider struct {
 	ID                   string `json:"id"`
@@ -49,8 +64,37 @@ type Provider struct {
 	MetadataKey          string // Only used as S3 object reference
 	ProviderUrl          string `json:"provider_url"`
 	PublicKey            string `json:"public_key"`
+	// PublicKeySource is where the public key came from, it is empty if there is no key.
+	PublicKeySource KeySource `json:"public_key_source,omitempty"`
+	// Status is whether the provider's endpoint is reachable, it is empty if it isn't probed.
+	Status ProviderStatus `json:"status,omitempty"`
+	// LastSeen is when the provider's endpoint was last reachable.
+	LastSeen *time.Time `json:"last_seen,omitempty"`
 }
 
+// ProviderStatus is whether the endpoint of a provider is reachable.
+type ProviderStatus string
+
+const (
+	// ProviderStatusUnknown is the status of a provider that wasn't probed yet.
+	ProviderStatusUnknown ProviderStatus = "unknown"
+	// ProviderStatusUp is the status of a provider whose endpoint is reachable.
+	ProviderStatusUp ProviderStatus = "up"
+	// ProviderStatusDown is the status of a provider whose endpoint failed several probes in a row.
+	ProviderStatusDown ProviderStatus = "down"
+)
+
+// KeySource is where the public key of a provider came from.
+type KeySource string
+
+const (
+	// KeySourceCatalogue is a key published by the provider in the federated catalogue.
+	KeySourceCatalogue KeySource = "catalogue"
+	// KeySourceFile is a key from the provider public key file, which overrides the catalogue, or
+	// from the provider file.
+	KeySourceFile KeySource = "file"
+)
+
 // Target represents the target of a policy permission request..
 type Target struct {
 	ProviderID string    `json:"provider_id"`
@@ -71,11 +115,96 @@ type ShareRequest struct {
 }
 
 // Validate checks the validity of the ShareRequest.
-// TODO: actually validate the ShareRequest.
 func (sr ShareRequest) Validate() error {
+	if sr.Target.ProviderID == "" {
+		return fmt.Errorf("%w: share target has no provider", ErrInvalid)
+	}
+	if sr.Target.FileID == uuid.Nil {
+		return fmt.Errorf("%w: share target has no file", ErrInvalid)
+	}
+	return nil
+}
+
+// SharedFile is a file published for sharing with non-dataspace apps, its content has to be
+// closed by the caller.
+type SharedFile struct {
+	Name     string
+	MimeType string
+	Size     int64
+	Content  io.ReadCloser
+}
+
+// TransferKind is what a transfer job retrieves.
+type TransferKind string
+
+const (
+	// TransferKindDownload retrieves the file itself, to be streamed from the transfer content.
+	TransferKindDownload TransferKind = "download"
+	// TransferKindCredentials retrieves the credentials to download the file from the provider.
+	TransferKindCredentials TransferKind = "credentials"
+)
+
+// TransferState is the state of a transfer job.
+type TransferState string
+
+const (
+	// TransferStatePending means the job hasn't started yet.
+	TransferStatePending TransferState = "pending"
+	// TransferStateNegotiating means the transfer is being negotiated with the provider.
+	TransferStateNegotiating TransferState = "negotiating"
+	// TransferStateReady means the content of a download can be retrieved.
+	TransferStateReady TransferState = "ready"
+	// TransferStateTransferring means the content of a download is being retrieved.
+	TransferStateTransferring TransferState = "transferring"
+	// TransferStateCompleted means the job is done.
+	TransferStateCompleted TransferState = "completed"
+	// TransferStateFailed means the job couldn't be done, see its error.
+	TransferStateFailed TransferState = "failed"
+	// TransferStateCancelled means the job was cancelled by the user, or the backend shut down.
+	TransferStateCancelled TransferState = "cancelled"
+)
+
+// Final tells if the job won't change anymore.
+func (ts TransferState) Final() bool {
+	return ts == TransferStateCompleted || ts == TransferStateFailed || ts == TransferStateCancelled
+}
+
+// TransferRequest represents a request to start a transfer job.
+type TransferRequest struct {
+	Target Target       `json:"target"`
+	Kind   TransferKind `json:"kind"`
+}
+
+// Validate checks the validity of the TransferRequest.
+func (tr TransferRequest) Validate() error {
+	if tr.Target.ProviderID == "" {
+		return fmt.Errorf("%w: transfer target has no provider", ErrInvalid)
+	}
+	if tr.Target.FileID == uuid.Nil {
+		return fmt.Errorf("%w: transfer target has no file", ErrInvalid)
+	}
+	if tr.Kind != TransferKindDownload && tr.Kind != TransferKindCredentials {
+		return fmt.Errorf("%w: unknown transfer kind %q", ErrInvalid, tr.Kind)
+	}
 	return nil
 }
 
+// TransferJob is a transfer running in the background, and its progress.
+type TransferJob struct {
+	ID               uuid.UUID            `json:"id"`
+	Kind             TransferKind         `json:"kind"`
+	Target           Target               `json:"target"`
+	State            TransferState        `json:"state"`
+	Name             string               `json:"name,omitempty"`
+	MimeType         string               `json:"mime_type,omitempty"`
+	Size             int64                `json:"size"`
+	BytesTransferred int64                `json:"bytes_transferred"`
+	Credentials      *DownloadCredentials `json:"credentials,omitempty"`
+	Error            string               `json:"error,omitempty"`
+	CreatedAt        time.Time            `json:"created_at"`
+	UpdatedAt        time.Time            `json:"updated_at"`
+}
+
 // Policy represents a policy, describing the permission givven to a provider for accessing a
 // resource.
 type Policy struct {
@@ -101,6 +230,34 @@ type ProviderFile struct {
 	Size        int64    `json:"size"`
 	Provider    Provider `json:"provider"`
 	Key         string
+	// Matches lists why the file is relevant to a study, only set when listing study files.
+	Matches []StudyFileMatch `json:"matches,omitempty"`
+}
+
+// StudyFileMatch is a research data requirement of a study that a file fulfils.
+type StudyFileMatch struct {
+	ResearchData string `json:"research_data"`
+	Reason       string `json:"reason"`
+}
+
+// FileRange is the part of a file requested by the client, as given in the HTTP Range and
+// If-Range headers. Empty values request the full file.
+type FileRange struct {
+	Range   string
+	IfRange string
+}
+
+// FileMetadata describes a file that is being streamed from its provider.
+type FileMetadata struct {
+	// Size is the size of the streamed content in bytes, or -1 if the provider didn't tell.
+	Size int64
+	// ContentRange is only set if part of the file is streamed, in the HTTP Content-Range format.
+	ContentRange string
+	// AcceptRanges tells if the file can be requested in parts.
+	AcceptRanges bool
+	// ETag and LastModified identify the version of the file, to validate resumed downloads.
+	ETag         string
+	LastModified string
 }
 
 type DownloadCredentials struct {
@@ -162,6 +319,143 @@ type StudySharedFile struct {
 	Description string    `json:"description"`
 }
 
+// ConsentRequest represents the consent of a user to share research data with a study.
+type ConsentRequest struct {
+	ResearchData []ConsentRequestData `json:"research_data"`
+}
+
+// ConsentRequestData is a research data item of a study the user agrees to share. The access type
+// has no default, as the zero value would be full access.
+type ConsentRequestData struct {
+	Name       string      `json:"name"`
+	AccessType *AccessType `json:"access_type"`
+}
+
+// ConsentedData is a research data item of a study the user agreed to share, and at which access
+// type.
+type ConsentedData struct {
+	Name       string     `json:"name"`
+	AccessType AccessType `json:"access_type"`
+}
+
+// Validate checks the validity of the ConsentRequest.
+func (cr ConsentRequest) Validate() error {
+	if len(cr.ResearchData) == 0 {
+		return fmt.Errorf("%w: consent contains no research data", ErrInvalid)
+	}
+	seen := make(map[string]bool, len(cr.ResearchData))
+	for _, rd := range cr.ResearchData {
+		if rd.Name == "" {
+			return fmt.Errorf("%w: consented research data has no name", ErrInvalid)
+		}
+		if seen[rd.Name] {
+			return fmt.Errorf("%w: research data %q consented more than once", ErrInvalid, rd.Name)
+		}
+		seen[rd.Name] = true
+		if rd.AccessType == nil {
+			return fmt.Errorf("%w: no access type for %q", ErrInvalid, rd.Name)
+		}
+		if *rd.AccessType < AccessTypeFull || *rd.AccessType > AccessTypePseudonymized {
+			return fmt.Errorf("%w: unknown access type %d for %q", ErrInvalid, *rd.AccessType, rd.Name)
+		}
+	}
+	return nil
+}
+
+// Consented returns the research data of a validated ConsentRequest.
+func (cr ConsentRequest) Consented() []ConsentedData {
+	consented := make([]ConsentedData, 0, len(cr.ResearchData))
+	for _, rd := range cr.ResearchData {
+		consented = append(consented, ConsentedData{Name: rd.Name, AccessType: *rd.AccessType})
+	}
+	return consented
+}
+
+// ValidateForStudy checks that all consented research data is requested by the study.
+func (cr ConsentRequest) ValidateForStudy(study Study) error {
+	requested := make(map[string]bool, len(study.ResearchData))
+	for _, rd := range study.ResearchData {
+		requested[rd.Name] = true
+	}
+	for _, rd := range cr.ResearchData {
+		if !requested[rd.Name] {
+			return fmt.Errorf("%w: research data %q is not requested by the study", ErrInvalid, rd.Name)
+		}
+	}
+	return nil
+}
+
+// Consent is the record of a user agreeing to share research data with a study. A withdrawn
+// consent is kept, so the participation history of the user stays available.
+type Consent struct {
+	ID           uuid.UUID       `json:"id"`
+	StudyID      uuid.UUID       `json:"study_id"`
+	ResearchData []ConsentedData `json:"research_data"`
+	GivenAt      time.Time       `json:"given_at"`
+	WithdrawnAt  *time.Time      `json:"withdrawn_at,omitempty"`
+}
+
+// Active tells if the consent hasn't been withdrawn.
+func (c Consent) Active() bool {
+	return c.WithdrawnAt == nil
+}
+
+// Covers returns the research data the consent covers the file for. A file is covered when one
+// of its study matches is research data the user consented to share.
+func (c Consent) Covers(f ProviderFile) (ConsentedData, bool) {
+	for _, m := range f.Matches {
+		for _, rd := range c.ResearchData {
+			if rd.Name == m.ResearchData {
+				return rd, true
+			}
+		}
+	}
+	return ConsentedData{}, false
+}
+
+// ContributionState is the state of the transfer of a file to a study.
+type ContributionState string
+
+const (
+	// ContributionStatePending means the file hasn't been transferred yet.
+	ContributionStatePending ContributionState = "pending"
+	// ContributionStateTransferring means the file is being transferred, or waiting to be retried.
+	ContributionStateTransferring ContributionState = "transferring"
+	// ContributionStateCompleted means the file has been handed to the study.
+	ContributionStateCompleted ContributionState = "completed"
+	// ContributionStateFailed means the file couldn't be transferred, even after retrying.
+	ContributionStateFailed ContributionState = "failed"
+	// ContributionStateCancelled means the transfer was stopped as the consent was withdrawn.
+	ContributionStateCancelled ContributionState = "cancelled"
+)
+
+// Final tells if the state of the file won't change anymore.
+func (cs ContributionState) Final() bool {
+	return cs == ContributionStateCompleted || cs == ContributionStateFailed || cs == ContributionStateCancelled
+}
+
+// Contribution is the transfer of the files covered by a consent to the study.
+type Contribution struct {
+	ConsentID uuid.UUID          `json:"consent_id"`
+	StudyID   uuid.UUID          `json:"study_id"`
+	CreatedAt time.Time          `json:"created_at"`
+	Files     []FileContribution `json:"files"`
+}
+
+// FileContribution is the transfer state of a single file of a contribution.
+type FileContribution struct {
+	ProviderID   string            `json:"provider_id"`
+	FileID       string            `json:"file_id"`
+	Name         string            `json:"name"`
+	ResearchData string            `json:"research_data"`
+	AccessType   AccessType        `json:"access_type"`
+	State        ContributionState `json:"state"`
+	TransferID   string            `json:"transfer_id,omitempty"`
+	Attempts     int               `json:"attempts"`
+	Error        string            `json:"error,omitempty"`
+	UpdatedAt    time.Time         `json:"updated_at"`
+}
+
 type Organization struct {
 	ID   uuid.UUID `json:"id"`
 	Name string    `json:"name"`
@@ -18,15 +18,17 @@ package server
 
 import (
 	"context"
+	"crypto/aes"
+	"crypto/cipher"
 	"crypto/tls"
-	"crypto/x509"
-	"encoding/json"
+	"encoding/base64"
+	"errors"
 	"fmt"
-	"io"
 	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
@@ -34,19 +36,41 @@ import (
 	dspclient "github.com/go-dataspace/run-dsrpc/gen/go/dsp/v1alpha1"
 
 	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/cli"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/events"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/leader"
 	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/logging"
 	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/middleware"
 	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/middleware/authforwarder"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/middleware/authverifier"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/monitor"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/providerkeys"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/reload"
 	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api"
+	amredis "github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/implementations/accessmanagers/redis"
+	amstatic "github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/implementations/accessmanagers/static"
+	cmredis "github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/implementations/consentmanagers/redis"
+	cmstatic "github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/implementations/consentmanagers/static"
 	dspconnector "github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/implementations/dsconnectors/dsp"
+	orchredis "github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/implementations/orchestrators/redis"
+	orchstatic "github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/implementations/orchestrators/static"
+	plcomposite "github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/implementations/providerlisters/composite"
 	fc "github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/implementations/providerlisters/fc"
+	plfile "github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/implementations/providerlisters/file"
+	plliveness "github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/implementations/providerlisters/liveness"
 	plstatic "github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/implementations/providerlisters/static"
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/implementations/sharemanagers"
+	smredis "github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/implementations/sharemanagers/redis"
+	smstatic "github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/implementations/sharemanagers/static"
 	sldsp "github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/implementations/studymanagers/dsp"
 	slstatic "github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/implementations/studymanagers/static"
+	tmredis "github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/implementations/transfermanagers/redis"
+	tmstatic "github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/implementations/transfermanagers/static"
 	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/server/api/types"
 	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/waitgroup"
 	"github.com/gin-gonic/gin"
 	grpclog "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/logging"
+	"github.com/minio/minio-go/v7"
+	miniocreds "github.com/minio/minio-go/v7/pkg/credentials"
 	"github.com/penglongli/gin-metrics/ginmetrics"
 	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
@@ -57,7 +81,10 @@ import (
 	"google.golang.org/grpc/credentials/insecure"
 )
 
-const appName = "cma-backend"
+const (
+	appName           = "cma-backend"
+	didResolveTimeout = 10 * time.Second
+)
 
 // Command contains all the options for running the server.
 type Command struct {
@@ -67,12 +94,57 @@ type Command struct {
 	TracingEnabled  bool   `help:"Enable tracing" default:"false" env:"TRACING_ENABLED"`
 	TracingEndpoint string `help:"Tracing endpoint as <host>:<port>"  env:"TRACING_ENDPOINT"`
 
-	ProviderLister        string `help:"Provider lister to use" enum:"static,fc" default:"static" env:"PROVIDER_LISTER"` //nolint:lll
-	ProviderCatalogURL    string `help:"Link to the federated catalog" default:"" env:"PROVIDER_CATALOG_URL"`
-	ProviderPublicKeyFile string `help:"JSON file with map of provider_url -> base64 JWK public key" default:"" env:"PROVIDER_PUBLIC_KEY_FILE"` //nolint:lll
-
-	StudyManager        string `help:"Study manager to use." enum:"static,dsp" default:"static" env:"STUDY_MANAGER"`
-	StudyCatalogBaseUri string `help:"Study catalog base URI." default:"https://study.dev-dataloft-ionos.de/api" env:"STUDY_CATALOG_BASE_URI"` //nolint:lll
+	AuthJWKS     string `help:"URL or file of the JWKS to verify bearer tokens with, verification is disabled if empty" default:"" env:"AUTH_JWKS"` //nolint:lll
+	AuthIssuer   string `help:"Issuer bearer tokens must have, not checked if empty" default:"" env:"AUTH_ISSUER"`                                  //nolint:lll
+	AuthAudience string `help:"Audience bearer tokens must have, not checked if empty" default:"" env:"AUTH_AUDIENCE"`                              //nolint:lll
+	AuthWrapJWE  bool   `help:"Wrap plain bearer tokens as JWE for the provider they are forwarded to" default:"false" env:"AUTH_WRAP_JWE"`         //nolint:lll
+
+	ProviderLister               string   `help:"Provider lister to use" enum:"static,fc,file,composite" default:"static" env:"PROVIDER_LISTER"`                          //nolint:lll
+	ProviderSources              []string `help:"Provider listers the composite provider lister merges, in order of precedence" default:"fc,file" env:"PROVIDER_SOURCES"` //nolint:lll
+	ProviderFile                 string   `help:"YAML or JSON file with the providers of the file provider lister" default:"" env:"PROVIDER_FILE"`                        //nolint:lll
+	ProviderCatalogURL           string   `help:"Link to the federated catalog" default:"" env:"PROVIDER_CATALOG_URL"`
+	ProviderCatalogPollInterval  int      `help:"Interval in minutes to poll the federated catalog" default:"1" env:"PROVIDER_CATALOG_POLL_INTERVAL"`                                                   //nolint:lll
+	ProviderCatalogQueryFile     string   `help:"File with the text/template of the Cypher query for the provider access points in the federated catalog" default:"" env:"PROVIDER_CATALOG_QUERY_FILE"` //nolint:lll
+	ProviderAccessPoints         []string `help:"Names of the access points providers are accessed at, in order of preference" default:"ids" env:"PROVIDER_ACCESS_POINTS"`                              //nolint:lll
+	ProviderCatalogTimeout       int      `help:"Timeout in seconds of requests to the federated catalog" default:"30" env:"PROVIDER_CATALOG_TIMEOUT"`                                                  //nolint:lll
+	ProviderCatalogCACert        string   `help:"Custom CA certificate for the federated catalog's TLS certificate" env:"PROVIDER_CATALOG_CA"`                                                          //nolint:lll
+	ProviderCatalogClientCert    string   `help:"Client certificate to authenticate with the federated catalog" env:"PROVIDER_CATALOG_CLIENT_CERT"`                                                     //nolint:lll
+	ProviderCatalogClientCertKey string   `help:"Key to the federated catalog client certificate" env:"PROVIDER_CATALOG_CLIENT_CERT_KEY"`                                                               //nolint:lll
+	ProviderCatalogTokenURL      string   `help:"OAuth2 token URL to get a federated catalog access token from with client credentials" default:"" env:"PROVIDER_CATALOG_TOKEN_URL"`                    //nolint:lll
+	ProviderCatalogClientID      string   `help:"OAuth2 client ID for the federated catalog" default:"" env:"PROVIDER_CATALOG_CLIENT_ID"`                                                               //nolint:lll
+	ProviderCatalogClientSecret  string   `help:"OAuth2 client secret for the federated catalog" default:"" env:"PROVIDER_CATALOG_CLIENT_SECRET"`                                                       //nolint:lll
+	ProviderCatalogScopes        []string `help:"OAuth2 scopes to request for the federated catalog" env:"PROVIDER_CATALOG_SCOPES"`                                                                     //nolint:lll
+	ProviderPublicKeyFile        string   `help:"JSON file with map of provider_url -> base64 JWK public key, or a list of them" default:"" env:"PROVIDER_PUBLIC_KEY_FILE"`                             //nolint:lll
+	ProviderPublicKeyStrict      bool     `help:"Refuse to start if a provider public key is invalid, instead of skipping it" default:"false" env:"PROVIDER_PUBLIC_KEY_STRICT"`                         //nolint:lll
+	ProviderRulesFile            string   `help:"JSON file with the rules which providers of the catalog are listed" default:"" env:"PROVIDER_RULES_FILE"`                                              //nolint:lll
+	ProviderTrustedIssuers       []string `help:"DIDs of the issuers participant credentials are accepted from, any issuer if empty" env:"PROVIDER_TRUSTED_ISSUERS"`                                    //nolint:lll
+	ProviderVerifyProofs         bool     `help:"Verify the JCS data integrity proofs of participant presentations and credentials" default:"true" env:"PROVIDER_VERIFY_PROOFS" negatable:""`           //nolint:lll
+	ProviderProbeInterval        int      `help:"Interval in minutes to probe whether the DSP endpoints of providers are reachable, 0 disables probing" default:"5" env:"PROVIDER_PROBE_INTERVAL"`      //nolint:lll
+	ProviderProbeTimeout         int      `help:"Timeout in seconds of a provider probe" default:"10" env:"PROVIDER_PROBE_TIMEOUT"`                                                                     //nolint:lll
+	ProviderHideDown             bool     `help:"Hide providers whose DSP endpoint is down from the provider list" default:"false" env:"PROVIDER_HIDE_DOWN"`                                            //nolint:lll
+
+	StudyManager             string `help:"Study manager to use." enum:"static,dsp" default:"static" env:"STUDY_MANAGER"`
+	StudyCatalogBaseUri      string `help:"Study catalog base URI." default:"https://study.dev-dataloft-ionos.de/api" env:"STUDY_CATALOG_BASE_URI"` //nolint:lll
+	StudyCatalogPollInterval int    `help:"Interval in minutes to poll the study catalog" default:"1" env:"STUDY_CATALOG_POLL_INTERVAL"`            //nolint:lll
+
+	AccessManager string `help:"Access manager to use." enum:"static,redis" default:"redis" env:"ACCESS_MANAGER"`
+
+	ShareTTL           int      `help:"Time in minutes a shared file stays available" default:"60" env:"SHARE_TTL"`
+	ShareSweepInterval int      `help:"Interval in minutes to remove the content of expired shares from the object storage" default:"5" env:"SHARE_SWEEP_INTERVAL"` //nolint:lll
+	ShareMaxSize       int64    `help:"Maximum size in bytes of a file that can be shared" default:"104857600" env:"SHARE_MAX_SIZE"`                                //nolint:lll
+	ShareS3Endpoint    string   `help:"Endpoint of the S3 compatible object storage shared files are kept in" default:"" env:"SHARE_S3_ENDPOINT"`                   //nolint:lll
+	ShareS3Bucket      string   `help:"Bucket shared files are kept in" default:"cma-shares" env:"SHARE_S3_BUCKET"`
+	ShareS3AccessKey   string   `help:"Access key of the object storage" default:"" env:"SHARE_S3_ACCESS_KEY"`
+	ShareS3SecretKey   string   `help:"Secret key of the object storage" default:"" env:"SHARE_S3_SECRET_KEY"`
+	ShareS3TLS         bool     `help:"Connect to the object storage with TLS" default:"true" env:"SHARE_S3_TLS" negatable:""`                                               //nolint:lll
+	PublicBaseURL      string   `help:"Public base URL of the backend, used in share download URIs, taken from the share request if empty" default:"" env:"PUBLIC_BASE_URL"` //nolint:lll
+	TrustedProxies     []string `help:"IP addresses or CIDR ranges of the reverse proxies whose X-Forwarded headers are trusted, none if empty" env:"TRUSTED_PROXIES"`       //nolint:lll
+
+	ContributionURL         string `help:"Base URL of the endpoint files are contributed to studies at, required unless in static mode" default:"" env:"CONTRIBUTION_URL"` //nolint:lll
+	ContributionMaxAttempts int    `help:"Maximum attempts to contribute a file" default:"5" env:"CONTRIBUTION_MAX_ATTEMPTS"`                                              //nolint:lll
+	ContributionRetryDelay  int    `help:"Seconds to wait before retrying a failed contribution, doubled every attempt" default:"10" env:"CONTRIBUTION_RETRY_DELAY"`       //nolint:lll
+
+	EventBus string `help:"Event bus to use, redis delivers events across replicas" enum:"local,redis" default:"redis" env:"EVENT_BUS"` //nolint:lll
 
 	RedisHost                  string `help:"Redis host" default:"localhost" env:"REDIS_HOST"`
 	RedisPort                  int    `help:"Redis port" default:"6379" env:"REDIS_PORT"`
@@ -88,7 +160,25 @@ type Command struct {
 	RunDspClientCert    string `help:"Client certificate to use to authenticate with rundsp" env:"RUNDSP_CLIENT_CERT"`
 	RunDspClientCertKey string `help:"Key to the client certificate" env:"RUNDSP_CLIENT_CERT_KEY"`
 
-	static bool `kong:"-"`
+	TransferIdleTimeout  int    `help:"Time in minutes an unfinished transfer is kept open for resuming" default:"10" env:"TRANSFER_IDLE_TIMEOUT"`                                            //nolint:lll
+	TransferJobTTL       int    `help:"Time in minutes the state of a transfer job is kept after its last update" default:"1440" env:"TRANSFER_JOB_TTL"`                                      //nolint:lll
+	TransferReadyTimeout int    `help:"Time in minutes a ready download waits for its content to be retrieved" default:"10" env:"TRANSFER_READY_TIMEOUT"`                                     //nolint:lll
+	TransferSecretKey    string `help:"Base64 encoded 32 byte key the negotiated transfers are encrypted with in redis, required unless in static mode" default:"" env:"TRANSFER_SECRET_KEY"` //nolint:lll
+
+	ConfigWatch bool `help:"Reload file based config when the files change, it is always reloaded on SIGHUP" default:"true" env:"CONFIG_WATCH" negatable:""` //nolint:lll
+
+	LeaderElection bool   `help:"Elect one replica with a lock in redis to poll the catalogues, else every replica polls" default:"true" env:"LEADER_ELECTION" negatable:""` //nolint:lll
+	LeaderID       string `help:"ID of this replica in the leader election, the hostname if empty" default:"" env:"LEADER_ID"`                                               //nolint:lll
+	LeaderLockTTL  int    `help:"Time in seconds the leader lock is held without renewal, before another replica takes over" default:"30" env:"LEADER_LOCK_TTL"`             //nolint:lll
+
+	MonitorJitter     int `help:"Percentage the interval of the monitors is randomly changed by" default:"10" env:"MONITOR_JITTER"`                                  //nolint:lll
+	MonitorMaxBackoff int `help:"Longest time in minutes a monitor waits after failing, the wait doubles with every failure" default:"30" env:"MONITOR_MAX_BACKOFF"` //nolint:lll
+
+	static   bool             `kong:"-"`
+	reloader *reload.Reloader `kong:"-"`
+	dspTLS   *dspTLS          `kong:"-"`
+	elector  *leader.Elector  `kong:"-"`
+	monitors *monitor.Runner  `kong:"-"`
 }
 
 // Run runs the server.
@@ -126,13 +216,42 @@ func (c *Command) Run(p cli.Params) error {
 		c.static = true
 	}
 
+	c.reloader = reload.New(c.ConfigWatch)
+	c.monitors = monitor.New()
+
 	var err error
 	redisClient, err := c.getRedisClient(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to connect to redis: %w", err)
 	}
 
-	r := getRouter(logger)
+	if c.LeaderElection && !c.static {
+		if c.LeaderLockTTL <= 0 {
+			return fmt.Errorf("invalid leader lock TTL %d", c.LeaderLockTTL)
+		}
+		id := c.LeaderID
+		if id == "" {
+			if id, err = os.Hostname(); err != nil {
+				return fmt.Errorf("failed to get hostname for leader election: %w", err)
+			}
+		}
+		logger.Info("Electing a leader to run the monitors", "leader_id", id, "lock_ttl", c.LeaderLockTTL)
+		c.elector = leader.New(ctx, redisClient, id, time.Duration(c.LeaderLockTTL)*time.Second)
+	}
+
+	var verifier *authverifier.Verifier
+	if c.AuthJWKS != "" {
+		logger.Info("Verifying bearer tokens", "jwks", c.AuthJWKS, "issuer", c.AuthIssuer, "audience", c.AuthAudience)
+		verifier, err = authverifier.New(ctx, c.AuthJWKS, c.AuthIssuer, c.AuthAudience)
+		if err != nil {
+			return fmt.Errorf("failed to set up token verification: %w", err)
+		}
+	}
+
+	r, err := getRouter(logger, verifier, c.TrustedProxies)
+	if err != nil {
+		return err
+	}
 
 	apiRoutes, err := c.getApiRoutes(ctx, redisClient)
 	if err != nil {
@@ -141,6 +260,17 @@ func (c *Command) Run(p cli.Params) error {
 
 	apiRoutes.AddRoutes(r.Group("/api"))
 
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		c.reloader.Run(ctx)
+	}()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		c.monitors.Run(ctx)
+	}()
+
 	promSrv := runPrometheus(ctx, r, c.ListenAddr, c.PrometheusPort)
 	appSrv := runBackend(ctx, r, c.ListenAddr, c.Port)
 
@@ -160,22 +290,60 @@ func (c *Command) Run(p cli.Params) error {
 }
 
 func (c *Command) getApiRoutes(ctx context.Context, redisClient *redis.Client) (*api.Routes, error) {
-	pl, err := c.selectProviderLister(ctx, redisClient)
+	eb, err := c.selectEventBus(ctx, redisClient)
 	if err != nil {
 		return nil, err
 	}
 
-	client, err := c.getDspClient(ctx)
+	pl, err := c.selectProviderLister(ctx, redisClient, eb)
 	if err != nil {
 		return nil, err
 	}
-	dc := dspconnector.New(client, pl)
+	if c.ProviderProbeInterval > 0 {
+		logging.Extract(ctx).Info("Probing providers", "interval", c.ProviderProbeInterval, "hide_down", c.ProviderHideDown)
+		lpl := plliveness.New(
+			pl,
+			redisClient,
+			&http.Client{Timeout: time.Duration(c.ProviderProbeTimeout) * time.Second},
+			c.ProviderHideDown,
+			c.elector,
+		)
+		err := c.monitors.Start(ctx, "provider liveness", c.monitorConfig(c.ProviderProbeInterval), lpl.ProbeProviders)
+		if err != nil {
+			return nil, err
+		}
+		pl = lpl
+	}
 
-	sl, err := c.selectStudyManager(ctx, redisClient)
+	client, err := c.getDspClient(ctx, pl)
+	if err != nil {
+		return nil, err
+	}
+	dc := dspconnector.New(client, pl, time.Duration(c.TransferIdleTimeout)*time.Minute)
+
+	sl, err := c.selectStudyManager(ctx, redisClient, dc, pl, eb)
+	if err != nil {
+		return nil, err
+	}
+
+	am, err := c.selectAccessManager(ctx, redisClient)
+	if err != nil {
+		return nil, err
+	}
+	sm, err := c.selectShareManager(ctx, redisClient, dc)
+	if err != nil {
+		return nil, err
+	}
+	cm := c.selectConsentManager(ctx, redisClient)
+	co, err := c.selectOrchestrator(ctx, redisClient, client, pl)
+	if err != nil {
+		return nil, err
+	}
+	tm, err := c.selectTransferManager(ctx, redisClient, client, pl, dc, eb)
 	if err != nil {
 		return nil, err
 	}
-	apiRoutes := api.New(pl, dc, sl)
+	apiRoutes := api.New(pl, dc, sl, am, sm, cm, co, tm, eb)
 	return apiRoutes, nil
 }
 
@@ -195,38 +363,156 @@ func runBackend(ctx context.Context, handler http.Handler, addr string, port int
 	return srv
 }
 
-func getRouter(logger *slog.Logger) *gin.Engine {
+func getRouter(logger *slog.Logger, verifier *authverifier.Verifier, trustedProxies []string) (*gin.Engine, error) {
+	proxies, err := middleware.ParseTrustedProxies(trustedProxies)
+	if err != nil {
+		return nil, err
+	}
 	m := ginmetrics.GetMonitor()
 	r := gin.New()
+	if err := r.SetTrustedProxies(trustedProxies); err != nil {
+		return nil, fmt.Errorf("invalid trusted proxies: %w", err)
+	}
 	r.Use(gin.Recovery())
 	r.Use(otelgin.Middleware("cma-backend-main"))
 	m.Use(r)
 	r.Use(sloggin.New(logger))
 	r.Use(middleware.LogContext(logger))
+	r.Use(middleware.ForwardedHeaders(proxies))
 	r.Use(authforwarder.HTTPMiddleware())
-	return r
+	if verifier != nil {
+		// Shared files are downloaded with the share token as bearer token.
+		r.Use(authverifier.HTTPMiddleware(verifier, "/api/shares/:share_id"))
+	}
+	return r, nil
 }
 
-func (c *Command) selectProviderLister(ctx context.Context, redisClient *redis.Client) (types.ProviderLister, error) {
+func (c *Command) selectProviderLister(
+	ctx context.Context,
+	redisClient *redis.Client,
+	eb events.Bus,
+) (types.ProviderLister, error) {
 	logger := logging.Extract(ctx)
-	providerKeys, err := loadProviderPublicKeys(ctx, c.ProviderPublicKeyFile)
-	if err != nil {
-		return nil, err
+	if c.ProviderLister != "composite" {
+		return c.newProviderLister(ctx, c.ProviderLister, redisClient, eb)
+	}
+
+	logger.Info("Using composite provider lister", "sources", c.ProviderSources)
+	sources := make([]plcomposite.Source, 0, len(c.ProviderSources))
+	seen := map[string]bool{"composite": true}
+	for _, name := range c.ProviderSources {
+		if seen[name] {
+			return nil, fmt.Errorf("invalid provider source %s", name)
+		}
+		seen[name] = true
+		pl, err := c.newProviderLister(ctx, name, redisClient, eb)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, plcomposite.Source{Name: name, Lister: pl})
+	}
+	if len(sources) == 0 {
+		return nil, errors.New("the composite provider lister needs at least one source")
 	}
+	return plcomposite.New(sources...), nil
+}
 
-	switch c.ProviderLister {
+func (c *Command) newProviderLister(
+	ctx context.Context,
+	name string,
+	redisClient *redis.Client,
+	eb events.Bus,
+) (types.ProviderLister, error) {
+	logger := logging.Extract(ctx)
+	switch name {
 	case "static":
 		logger.Info("Using static provider lister")
 		return plstatic.New(), nil
+	case "file":
+		logger.Info("Using file provider lister", "file", c.ProviderFile)
+		pl, err := plfile.New(ctx, c.ProviderFile, c.ProviderPublicKeyStrict)
+		if err != nil {
+			return nil, err
+		}
+		c.reloader.Register("provider file", pl.Reload, c.ProviderFile)
+		return pl, nil
 	case "fc":
 		logger.Info("Using federated catalog provider lister")
-		return fc.New(
-			ctx,
+		rules, err := fc.LoadRules(ctx, c.ProviderRulesFile)
+		if err != nil {
+			return nil, err
+		}
+		query, err := fc.LoadQuery(c.ProviderCatalogQueryFile, c.ProviderAccessPoints)
+		if err != nil {
+			return nil, err
+		}
+		client, err := fc.NewHTTPClient(fc.ClientConfig{
+			Timeout:      time.Duration(c.ProviderCatalogTimeout) * time.Second,
+			CAFile:       c.ProviderCatalogCACert,
+			CertFile:     c.ProviderCatalogClientCert,
+			KeyFile:      c.ProviderCatalogClientCertKey,
+			TokenURL:     c.ProviderCatalogTokenURL,
+			ClientID:     c.ProviderCatalogClientID,
+			ClientSecret: c.ProviderCatalogClientSecret,
+			Scopes:       c.ProviderCatalogScopes,
+		})
+		if err != nil {
+			return nil, err
+		}
+		providerKeys, err := providerkeys.Load(ctx, c.ProviderPublicKeyFile, c.ProviderPublicKeyStrict)
+		if err != nil {
+			return nil, err
+		}
+		pl, err := fc.New(
 			redisClient,
+			eb,
 			c.ProviderCatalogURL,
-			providerKeys)
+			client,
+			query,
+			providerKeys,
+			fc.NewVerifier(
+				c.ProviderTrustedIssuers,
+				c.ProviderVerifyProofs,
+				fc.NewDIDWebResolver(&http.Client{Timeout: didResolveTimeout}).ResolveKey,
+			),
+			rules,
+			c.elector)
+		if err != nil {
+			return nil, err
+		}
+		c.reloader.Register("provider public keys", func(ctx context.Context) error {
+			keys, err := providerkeys.Load(ctx, c.ProviderPublicKeyFile, c.ProviderPublicKeyStrict)
+			if err != nil {
+				return err
+			}
+			pl.SetPublicKeys(ctx, keys)
+			return nil
+		}, c.ProviderPublicKeyFile)
+		c.reloader.Register("provider rules", func(ctx context.Context) error {
+			rules, err := fc.LoadRules(ctx, c.ProviderRulesFile)
+			if err != nil {
+				return err
+			}
+			pl.SetRules(ctx, rules)
+			return nil
+		}, c.ProviderRulesFile)
+		err = c.monitors.Start(ctx, "federated catalog provider lister",
+			c.monitorConfig(c.ProviderCatalogPollInterval), pl.UpdateParticipants)
+		if err != nil {
+			return nil, err
+		}
+		return pl, nil
 	default:
-		return nil, fmt.Errorf("unknown provider lister %s", c.ProviderLister)
+		return nil, fmt.Errorf("unknown provider lister %s", name)
+	}
+}
+
+// monitorConfig returns the config of a monitor that runs every interval minutes.
+func (c *Command) monitorConfig(interval int) monitor.Config {
+	return monitor.Config{
+		Interval:   time.Duration(interval) * time.Minute,
+		Jitter:     float64(c.MonitorJitter) / 100,
+		MaxBackoff: time.Duration(c.MonitorMaxBackoff) * time.Minute,
 	}
 }
 
@@ -265,6 +551,9 @@ func (c *Command) getRedisClient(ctx context.Context) (*redis.Client, error) {
 func (c *Command) selectStudyManager(
 	ctx context.Context,
 	rc *redis.Client,
+	dc types.DataspaceConnector,
+	pl types.ProviderLister,
+	eb events.Bus,
 ) (types.StudyLister, error) {
 	logger := logging.Extract(ctx)
 	switch c.StudyManager {
@@ -272,23 +561,200 @@ func (c *Command) selectStudyManager(
 		logger.Info("Using static study manager")
 		return slstatic.New(), nil
 	case "dsp":
-		client, err := c.getDspClient(ctx)
+		client, err := c.getDspClient(ctx, pl)
+		if err != nil {
+			return nil, err
+		}
+		sm := sldsp.New(client, c.StudyCatalogBaseUri, rc, dc, pl, eb, c.elector)
+		err = c.monitors.Start(ctx, "dsp study lister", c.monitorConfig(c.StudyCatalogPollInterval), sm.UpdateStudies)
 		if err != nil {
 			return nil, err
 		}
-		return sldsp.New(ctx, client,
-			c.StudyCatalogBaseUri, rc), nil
+		return sm, nil
 	default:
 		return nil, fmt.Errorf("unknown study manager %s", c.StudyManager)
 	}
 }
 
-func (c *Command) getDspClient(ctx context.Context) (dspclient.ClientServiceClient, error) {
+func (c *Command) selectEventBus(ctx context.Context, rc *redis.Client) (events.Bus, error) {
+	logger := logging.Extract(ctx)
+	if c.static {
+		logger.Info("Using local event bus in static mode")
+		return events.NewLocal(), nil
+	}
+	switch c.EventBus {
+	case "local":
+		logger.Info("Using local event bus")
+		return events.NewLocal(), nil
+	case "redis":
+		logger.Info("Using redis event bus")
+		return events.NewRedis(ctx, rc), nil
+	default:
+		return nil, fmt.Errorf("unknown event bus %s", c.EventBus)
+	}
+}
+
+func (c *Command) selectAccessManager(
+	ctx context.Context,
+	rc *redis.Client,
+) (types.AccessManager, error) {
+	logger := logging.Extract(ctx)
+	if c.static {
+		logger.Info("Using static access manager in static mode")
+		return amstatic.New(), nil
+	}
+	switch c.AccessManager {
+	case "static":
+		logger.Info("Using static access manager")
+		return amstatic.New(), nil
+	case "redis":
+		logger.Info("Using redis access manager")
+		return amredis.New(rc), nil
+	default:
+		return nil, fmt.Errorf("unknown access manager %s", c.AccessManager)
+	}
+}
+
+func (c *Command) selectConsentManager(ctx context.Context, rc *redis.Client) types.ConsentManager {
 	logger := logging.Extract(ctx)
-	tlsCredentials, err := c.loadTLSCredentials()
+	if c.static {
+		logger.Info("Using static consent manager in static mode")
+		return cmstatic.New()
+	}
+	logger.Info("Using redis consent manager")
+	return cmredis.New(rc)
+}
+
+func (c *Command) selectOrchestrator(
+	ctx context.Context,
+	rc *redis.Client,
+	client dspclient.ClientServiceClient,
+	pl types.ProviderLister,
+) (types.ContributionOrchestrator, error) {
+	logger := logging.Extract(ctx)
+	if c.static {
+		logger.Info("Using static contribution orchestrator in static mode")
+		return orchstatic.New(), nil
+	}
+	if c.ContributionURL == "" {
+		return nil, errors.New("no endpoint to contribute files to studies at, set --contribution-url")
+	}
+	logger.Info("Using redis contribution orchestrator", "contribution_url", c.ContributionURL)
+	return orchredis.New(
+		ctx,
+		rc,
+		client,
+		pl,
+		strings.TrimSuffix(c.ContributionURL, "/"),
+		c.ContributionMaxAttempts,
+		time.Duration(c.ContributionRetryDelay)*time.Second,
+	), nil
+}
+
+func (c *Command) selectTransferManager(
+	ctx context.Context,
+	rc *redis.Client,
+	client dspclient.ClientServiceClient,
+	pl types.ProviderLister,
+	dc types.DataspaceConnector,
+	eb events.Bus,
+) (types.TransferManager, error) {
+	logger := logging.Extract(ctx)
+	readyTimeout := time.Duration(c.TransferReadyTimeout) * time.Minute
+	if c.static {
+		logger.Info("Using static transfer manager in static mode")
+		return tmstatic.New(dc, eb, readyTimeout), nil
+	}
+	key, err := base64.StdEncoding.DecodeString(c.TransferSecretKey)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't decode transfer secret key: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, errors.New("transfer secret key has to be 32 bytes long, set --transfer-secret-key")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't create transfer cipher: %w", err)
+	}
+	secrets, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't create transfer cipher: %w", err)
+	}
+	logger.Info("Using redis transfer manager")
+	return tmredis.New(
+		ctx,
+		rc,
+		client,
+		pl,
+		dc,
+		eb,
+		secrets,
+		time.Duration(c.TransferJobTTL)*time.Minute,
+		readyTimeout,
+	), nil
+}
+
+func (c *Command) selectShareManager(
+	ctx context.Context,
+	rc *redis.Client,
+	dc types.DataspaceConnector,
+) (types.ShareManager, error) {
+	logger := logging.Extract(ctx)
+	baseURL := strings.TrimSuffix(c.PublicBaseURL, "/")
+	ttl := time.Duration(c.ShareTTL) * time.Minute
+	if c.static {
+		dir, err := os.MkdirTemp("", "cma-shares-")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create directory for shared files: %w", err)
+		}
+		wg := waitgroup.Extract(ctx)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-ctx.Done()
+			os.RemoveAll(dir)
+		}()
+		logger.Info("Using static share manager in static mode", "dir", dir)
+		return smstatic.New(dc, sharemanagers.NewDirStorage(dir), baseURL, ttl, c.ShareMaxSize), nil
+	}
+
+	if c.ShareS3Endpoint == "" {
+		return nil, errors.New("no object storage for shared files, set --share-s3-endpoint")
+	}
+	client, err := minio.New(c.ShareS3Endpoint, &minio.Options{
+		Creds:  miniocreds.NewStaticV4(c.ShareS3AccessKey, c.ShareS3SecretKey, ""),
+		Secure: c.ShareS3TLS,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to set up object storage for shared files: %w", err)
+	}
+	logger.Info("Using redis share manager", "s3_endpoint", c.ShareS3Endpoint, "bucket", c.ShareS3Bucket)
+	storage := sharemanagers.NewS3Storage(client, c.ShareS3Bucket)
+	sm := smredis.New(rc, dc, storage, baseURL, ttl, c.ShareMaxSize, c.elector)
+	err = c.monitors.Start(ctx, "expired shares", c.monitorConfig(c.ShareSweepInterval), sm.RemoveExpired)
 	if err != nil {
 		return nil, err
 	}
+	return sm, nil
+}
+
+func (c *Command) getDspClient(
+	ctx context.Context,
+	pl types.ProviderLister,
+) (dspclient.ClientServiceClient, error) {
+	logger := logging.Extract(ctx)
+	tlsCredentials, err := c.loadTLSCredentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	authInterceptor := grpc.UnaryClientInterceptor(authforwarder.UnaryClientInterceptor)
+	authStreamInterceptor := grpc.StreamClientInterceptor(authforwarder.StreamClientInterceptor)
+	if c.AuthWrapJWE {
+		logger.Info("Wrapping bearer tokens as JWE for providers")
+		authInterceptor = authforwarder.JWEUnaryClientInterceptor(pl.GetProviderKeys)
+		authStreamInterceptor = authforwarder.JWEStreamClientInterceptor(pl.GetProviderKeys)
+	}
 
 	logOpts := []grpclog.Option{
 		grpclog.WithLogOnEvents(grpclog.StartCall, grpclog.FinishCall),
@@ -298,11 +764,11 @@ func (c *Command) getDspClient(ctx context.Context) (dspclient.ClientServiceClie
 		grpc.WithTransportCredentials(tlsCredentials),
 		grpc.WithChainUnaryInterceptor(
 			grpclog.UnaryClientInterceptor(interceptorLogger(logger), logOpts...),
-			authforwarder.UnaryClientInterceptor,
+			authInterceptor,
 		),
 		grpc.WithChainStreamInterceptor(
 			grpclog.StreamClientInterceptor(interceptorLogger(logger), logOpts...),
-			authforwarder.StreamClientInterceptor,
+			authStreamInterceptor,
 		),
 	)
 	if err != nil {
@@ -317,60 +783,25 @@ func (c *Command) getDspClient(ctx context.Context) (dspclient.ClientServiceClie
 	return client, nil
 }
 
-func (c *Command) loadTLSCredentials() (credentials.TransportCredentials, error) {
+func (c *Command) loadTLSCredentials(ctx context.Context) (credentials.TransportCredentials, error) {
 	if c.RunDspInsecure {
 		return insecure.NewCredentials(), nil
 	}
 
-	config := &tls.Config{
-		MinVersion: tls.VersionTLS12,
-	}
-
-	if c.RunDspCACert != "" {
-		pemServerCA, err := os.ReadFile(c.RunDspCACert)
-		if err != nil {
-			return nil, fmt.Errorf("couldn't read CA file: %w", err)
-		}
-
-		certPool := x509.NewCertPool()
-		if !certPool.AppendCertsFromPEM(pemServerCA) {
-			return nil, fmt.Errorf("failed to add server CA certificate")
+	// The certificates are shared by all connections to run-dsp, and reloaded together.
+	if c.dspTLS == nil {
+		t := &dspTLS{
+			caFile:   c.RunDspCACert,
+			certFile: c.RunDspClientCert,
+			keyFile:  c.RunDspClientCertKey,
 		}
-		config.RootCAs = certPool
-	}
-
-	if c.RunDspClientCert != "" {
-		clientCert, err := tls.LoadX509KeyPair(c.RunDspClientCert, c.RunDspClientCertKey)
-		if err != nil {
-			return nil, err
-		}
-		config.Certificates = []tls.Certificate{clientCert}
-	}
-
-	return credentials.NewTLS(config), nil
-}
-
-func loadProviderPublicKeys(ctx context.Context, publicKeyFile string) (map[string]string, error) {
-	logger := logging.Extract(ctx)
-	providerKeys := map[string]string{}
-
-	if publicKeyFile != "" {
-		logger.Info(fmt.Sprintf("Trying to load provider public keys from file %s", publicKeyFile))
-		jsonFile, err := os.Open(publicKeyFile)
-		if err != nil {
-			return nil, err
-		}
-		defer jsonFile.Close()
-		data, err := io.ReadAll(jsonFile)
-		if err != nil {
-			return nil, err
-		}
-		err = json.Unmarshal(data, &providerKeys)
-		if err != nil {
+		if err := t.load(ctx); err != nil {
 			return nil, err
 		}
+		c.reloader.Register("run-dsp TLS certificates", t.load, t.caFile, t.certFile, t.keyFile)
+		c.dspTLS = t
 	}
-	return providerKeys, nil
+	return credentials.NewTLS(c.dspTLS.config()), nil
 }
 
 func interceptorLogger(l *slog.Logger) grpclog.Logger {
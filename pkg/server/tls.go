@@ -0,0 +1,113 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync/atomic"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/logging"
+)
+
+// dspTLS holds the certificates of the connection to run-dsp. They are kept behind pointers that
+// are swapped on reload, so the connection doesn't have to be set up again.
+type dspTLS struct {
+	caFile   string
+	certFile string
+	keyFile  string
+	roots    atomic.Pointer[x509.CertPool]
+	cert     atomic.Pointer[tls.Certificate]
+}
+
+// load reads the certificates from their files, and swaps them in if they are all valid.
+func (t *dspTLS) load(ctx context.Context) error {
+	logger := logging.Extract(ctx)
+	var roots *x509.CertPool
+	if t.caFile != "" {
+		pemServerCA, err := os.ReadFile(t.caFile)
+		if err != nil {
+			return fmt.Errorf("couldn't read CA file: %w", err)
+		}
+		roots = x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(pemServerCA) {
+			return fmt.Errorf("failed to add server CA certificate")
+		}
+	}
+
+	var cert *tls.Certificate
+	if t.certFile != "" {
+		clientCert, err := tls.LoadX509KeyPair(t.certFile, t.keyFile)
+		if err != nil {
+			return err
+		}
+		cert = &clientCert
+	}
+
+	if roots != nil {
+		if old := t.roots.Swap(roots); old != nil && !old.Equal(roots) {
+			logger.Info("run-dsp CA certificate changed", "file", t.caFile)
+		}
+	}
+	if cert != nil {
+		old := t.cert.Swap(cert)
+		if old != nil && old.Leaf != nil && cert.Leaf != nil && !old.Leaf.Equal(cert.Leaf) {
+			logger.Info("run-dsp client certificate changed",
+				"old_serial", old.Leaf.SerialNumber, "old_not_after", old.Leaf.NotAfter,
+				"serial", cert.Leaf.SerialNumber, "not_after", cert.Leaf.NotAfter,
+			)
+		}
+	}
+	return nil
+}
+
+func (t *dspTLS) config() *tls.Config {
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+	if t.certFile != "" {
+		config.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return t.cert.Load(), nil
+		}
+	}
+	if t.caFile != "" {
+		// The roots of a config can't be swapped, so the default verification is replaced by one
+		// against the current roots.
+		config.InsecureSkipVerify = true //nolint:gosec
+		config.VerifyConnection = t.verifyConnection
+	}
+	return config
+}
+
+func (t *dspTLS) verifyConnection(cs tls.ConnectionState) error {
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("run-dsp didn't present a certificate")
+	}
+	intermediates := x509.NewCertPool()
+	for _, c := range cs.PeerCertificates[1:] {
+		intermediates.AddCert(c)
+	}
+	_, err := cs.PeerCertificates[0].Verify(x509.VerifyOptions{
+		Roots:         t.roots.Load(),
+		Intermediates: intermediates,
+		DNSName:       cs.ServerName,
+	})
+	return err
+}
@@ -0,0 +1,131 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transfer
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrUnsatisfiableRange is returned when a requested range lies outside of the file.
+var ErrUnsatisfiableRange = errors.New("unsatisfiable range")
+
+// ByteRange is a single, inclusive range of bytes in a file.
+type ByteRange struct {
+	Start int64
+	End   int64
+}
+
+// Length returns the amount of bytes in the range.
+func (br ByteRange) Length() int64 {
+	return br.End - br.Start + 1
+}
+
+// ContentRange returns the range formatted as the value of a Content-Range header.
+func (br ByteRange) ContentRange(size int64) string {
+	return fmt.Sprintf("bytes %d-%d/%d", br.Start, br.End, size)
+}
+
+// ParseRange parses the value of a HTTP Range header for a file of the given size. Only single
+// byte ranges are supported, for anything else ok is false and the full file should be returned,
+// as allowed by RFC 9110.
+func ParseRange(header string, size int64) (br ByteRange, ok bool, err error) {
+	spec, found := strings.CutPrefix(strings.TrimSpace(header), "bytes=")
+	if !found || strings.Contains(spec, ",") || size < 0 {
+		return ByteRange{}, false, nil
+	}
+	first, last, found := strings.Cut(strings.TrimSpace(spec), "-")
+	if !found {
+		return ByteRange{}, false, nil
+	}
+
+	if first == "" {
+		// Suffix range, the last n bytes of the file.
+		n, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || n < 0 {
+			return ByteRange{}, false, nil
+		}
+		if n == 0 || size == 0 {
+			return ByteRange{}, false, ErrUnsatisfiableRange
+		}
+		return ByteRange{Start: max(size-n, 0), End: size - 1}, true, nil
+	}
+
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 {
+		return ByteRange{}, false, nil
+	}
+	if start >= size {
+		return ByteRange{}, false, ErrUnsatisfiableRange
+	}
+	end := size - 1
+	if last != "" {
+		end, err = strconv.ParseInt(last, 10, 64)
+		if err != nil || end < start {
+			return ByteRange{}, false, nil
+		}
+		end = min(end, size-1)
+	}
+	return ByteRange{Start: start, End: end}, true, nil
+}
+
+// ParseContentRange parses the value of a HTTP Content-Range header, size is -1 if the complete
+// length is unknown.
+func ParseContentRange(header string) (br ByteRange, size int64, err error) {
+	spec, found := strings.CutPrefix(strings.TrimSpace(header), "bytes ")
+	if !found {
+		return ByteRange{}, 0, fmt.Errorf("unsupported content range %q", header)
+	}
+	rng, total, found := strings.Cut(spec, "/")
+	if !found {
+		return ByteRange{}, 0, fmt.Errorf("invalid content range %q", header)
+	}
+	first, last, found := strings.Cut(rng, "-")
+	if !found {
+		return ByteRange{}, 0, fmt.Errorf("invalid content range %q", header)
+	}
+	if br.Start, err = strconv.ParseInt(first, 10, 64); err != nil {
+		return ByteRange{}, 0, fmt.Errorf("invalid content range %q: %w", header, err)
+	}
+	if br.End, err = strconv.ParseInt(last, 10, 64); err != nil {
+		return ByteRange{}, 0, fmt.Errorf("invalid content range %q: %w", header, err)
+	}
+	size = -1
+	if total != "*" {
+		if size, err = strconv.ParseInt(total, 10, 64); err != nil {
+			return ByteRange{}, 0, fmt.Errorf("invalid content range %q: %w", header, err)
+		}
+	}
+	return br, size, nil
+}
+
+// IfRangeMatches checks the value of a HTTP If-Range header against the validators of the file.
+// Only strong entity tags and exact modification dates match.
+func IfRangeMatches(ifRange string, etag string, lastModified string) bool {
+	ifRange = strings.TrimSpace(ifRange)
+	if ifRange == "" {
+		return true
+	}
+	if strings.HasPrefix(ifRange, `"`) {
+		return etag != "" && !strings.HasPrefix(etag, "W/") && ifRange == etag
+	}
+	if strings.HasPrefix(ifRange, "W/") {
+		return false
+	}
+	return lastModified != "" && ifRange == lastModified
+}
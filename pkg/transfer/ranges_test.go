@@ -0,0 +1,91 @@
+// Copyright 2025 HEALTH-X dataLOFT
+//
+// Licensed under the European Union Public Licence, Version 1.2 (the
+// "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://eupl.eu/1.2/en/
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package transfer_test
+
+import (
+	"testing"
+
+	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/transfer"
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		size   int64
+		want   transfer.ByteRange
+		ok     bool
+		err    error
+	}{
+		{name: "Full", header: "bytes=0-", size: 10, want: transfer.ByteRange{Start: 0, End: 9}, ok: true},
+		{name: "Bounded", header: "bytes=2-5", size: 10, want: transfer.ByteRange{Start: 2, End: 5}, ok: true},
+		{name: "EndPastSize", header: "bytes=2-50", size: 10, want: transfer.ByteRange{Start: 2, End: 9}, ok: true},
+		{name: "Suffix", header: "bytes=-3", size: 10, want: transfer.ByteRange{Start: 7, End: 9}, ok: true},
+		{name: "SuffixPastSize", header: "bytes=-30", size: 10, want: transfer.ByteRange{Start: 0, End: 9}, ok: true},
+		{name: "StartPastSize", header: "bytes=10-", size: 10, err: transfer.ErrUnsatisfiableRange},
+		{name: "EmptySuffix", header: "bytes=-0", size: 10, err: transfer.ErrUnsatisfiableRange},
+		{name: "MultipleRanges", header: "bytes=0-1,4-5", size: 10},
+		{name: "OtherUnit", header: "items=0-1", size: 10},
+		{name: "Malformed", header: "bytes=a-b", size: 10},
+		{name: "Reversed", header: "bytes=5-2", size: 10},
+		{name: "UnknownSize", header: "bytes=0-1", size: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			br, ok, err := transfer.ParseRange(tt.header, tt.size)
+			assert.IsError(t, err, tt.err)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.want, br)
+		})
+	}
+}
+
+func TestParseContentRange(t *testing.T) {
+	br, size, err := transfer.ParseContentRange("bytes 2-5/10")
+	assert.NoError(t, err)
+	assert.Equal(t, transfer.ByteRange{Start: 2, End: 5}, br)
+	assert.Equal(t, 10, size)
+
+	_, size, err = transfer.ParseContentRange("bytes 2-5/*")
+	assert.NoError(t, err)
+	assert.Equal(t, -1, size)
+
+	_, _, err = transfer.ParseContentRange("bytes */10")
+	assert.Error(t, err)
+}
+
+func TestIfRangeMatches(t *testing.T) {
+	const lastModified = "Wed, 21 Oct 2015 07:28:00 GMT"
+	tests := []struct {
+		name    string
+		ifRange string
+		etag    string
+		want    bool
+	}{
+		{name: "Empty", ifRange: "", etag: `"abc"`, want: true},
+		{name: "StrongETag", ifRange: `"abc"`, etag: `"abc"`, want: true},
+		{name: "ChangedETag", ifRange: `"abc"`, etag: `"def"`, want: false},
+		{name: "WeakETag", ifRange: `W/"abc"`, etag: `W/"abc"`, want: false},
+		{name: "Date", ifRange: lastModified, want: true},
+		{name: "ChangedDate", ifRange: "Thu, 22 Oct 2015 07:28:00 GMT", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, transfer.IfRangeMatches(tt.ifRange, tt.etag, lastModified))
+		})
+	}
+}
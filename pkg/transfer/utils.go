@@ -42,6 +42,17 @@ import (
 	"github.com/HEALTH-X-dataLOFT/cma-backend/pkg/logging"
 )
 
+const errorBodyLimit = 4096
+
+// StatusError is returned when the remote side responds with a non-200 status code.
+type StatusError struct {
+	StatusCode int
+}
+
+func (e StatusError) Error() string {
+	return fmt.Sprintf("non-200 status code: %d", e.StatusCode)
+}
+
 func SendHTTPRequest(
 	ctx context.Context, method string, url *url.URL, reqBody []byte,
 ) ([]byte, error) {
@@ -77,7 +88,29 @@ func SendHTTPRequest(
 	return respBody, nil
 }
 
+// RetrieveDSPFile downloads the file published by the provider into memory, only use this for
+// small files.
 func RetrieveDSPFile(ctx context.Context, publishInfo *dspclient.PublishInfo) ([]byte, error) {
+	logger := logging.Extract(ctx).With("method", "GET", "target_url", publishInfo.Url)
+	resp, err := StreamDSPFile(ctx, publishInfo, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		logger.Error("Failed to read body", "err", err)
+		return nil, err
+	}
+	return respBody, nil
+}
+
+// StreamDSPFile starts the download of the file published by the provider, and returns the
+// response without reading the body. The caller has to close the body. The given headers are
+// added to the request, this can be used to request ranges of the file.
+func StreamDSPFile(
+	ctx context.Context, publishInfo *dspclient.PublishInfo, header http.Header,
+) (*http.Response, error) {
 	logger := logging.Extract(ctx).With("method", "GET", "target_url", publishInfo.Url)
 	logger.Debug("Doing HTTP request")
 	req, err := http.NewRequestWithContext(ctx, "GET", publishInfo.Url, nil)
@@ -86,6 +119,9 @@ func RetrieveDSPFile(ctx context.Context, publishInfo *dspclient.PublishInfo) ([
 		return nil, err
 	}
 	req.Header.Add("Content-Type", "application/json")
+	for k, v := range header {
+		req.Header[k] = v
+	}
 
 	switch auth := publishInfo.AuthenticationType; auth {
 	case dspclient.AuthenticationType_AUTHENTICATION_TYPE_BASIC:
@@ -103,18 +139,14 @@ func RetrieveDSPFile(ctx context.Context, publishInfo *dspclient.PublishInfo) ([
 		logger.Error("Failed to send request", "err", err)
 		return nil, err
 	}
-	defer resp.Body.Close()
-	// In the future we might want to return the reader to handle big bodies.
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		logger.Error("Failed to read body", "err", err)
-		return nil, err
-	}
 
 	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		// Only read the start of the body, it's only for logging purposes.
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, errorBodyLimit))
 		logger.Error("Received non-200 status code", "status_code", resp.StatusCode, "body", string(respBody))
-		return nil, fmt.Errorf("non-200 status code: %d", resp.StatusCode)
+		return nil, StatusError{StatusCode: resp.StatusCode}
 	}
 
-	return respBody, nil
+	return resp, nil
 }